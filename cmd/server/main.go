@@ -8,13 +8,24 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"user-service/internal/database"
+	"user-service/internal/loginguard"
+	"user-service/internal/oidc"
+	"user-service/internal/revocation"
 	"user-service/internal/s3helper"
 	"user-service/internal/user"
+	"user-service/internal/utils"
 )
 
 func main() {
 	r := gin.Default()
 
+	// Só os proxies listados em TRUSTED_PROXIES (IPs ou CIDRs separados por
+	// vírgula) podem informar o IP do cliente via X-Forwarded-For; sem a
+	// lista, vale o endereço da conexão, que não pode ser forjado
+	if err := r.SetTrustedProxies(utils.GetEnvList("TRUSTED_PROXIES", nil)); err != nil {
+		log.Fatal("Erro ao configurar TRUSTED_PROXIES:", err)
+	}
+
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
@@ -29,6 +40,22 @@ func main() {
 
 	database.ConnectDatabase()
 
+	if err := utils.InitKeys(); err != nil {
+		log.Fatal("Erro ao carregar chaves JWT:", err)
+	}
+
+	if err := revocation.Init(); err != nil {
+		log.Fatal("Erro ao carregar lista de revogação:", err)
+	}
+
+	if err := loginguard.Init(); err != nil {
+		log.Fatal("Erro ao iniciar proteção de login:", err)
+	}
+
+	if err := oidc.Init(); err != nil {
+		log.Fatal("Erro ao configurar provedores OIDC:", err)
+	}
+
 	user.RegisterRoutes(r)
 
 	r.Run(":8087")
@@ -0,0 +1,28 @@
+package audit
+
+import (
+	"user-service/internal/database"
+	"user-service/internal/user/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Ações registradas no log de auditoria
+const (
+	ActionImpersonationStart   = "impersonation.start"
+	ActionImpersonationRequest = "impersonation.request"
+)
+
+// Record grava uma entrada no log de auditoria com o ator real da requisição
+// (actor_id definido pelo AuthMiddleware), IP e user agent.
+func Record(c *gin.Context, action, targetUserID string, details map[string]interface{}) error {
+	entry := models.AuditLog{
+		ActorID:      c.GetString("actor_id"),
+		TargetUserID: targetUserID,
+		Action:       action,
+		IP:           c.ClientIP(),
+		UserAgent:    c.Request.UserAgent(),
+		Details:      details,
+	}
+	return database.DB.Create(&entry).Error
+}
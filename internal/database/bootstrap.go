@@ -0,0 +1,84 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+	"user-service/internal/user/models"
+	"user-service/internal/utils"
+
+	"gorm.io/gorm"
+)
+
+// bootstrapAdmin garante o primeiro administrador, já que o cadastro público
+// não aceita o papel admin. Com INITIAL_ADMIN_EMAIL definido e nenhum
+// administrador no banco, a conta com esse e-mail é promovida ou, se não
+// existir, criada com INITIAL_ADMIN_PASSWORD e INITIAL_ADMIN_NAME. Depois do
+// primeiro administrador, os demais são geridos pela API.
+func bootstrapAdmin() error {
+	email := strings.TrimSpace(os.Getenv("INITIAL_ADMIN_EMAIL"))
+	if email == "" {
+		return nil
+	}
+
+	var admins int64
+	if err := DB.Model(&models.User{}).Where("role = ?", models.RoleAdmin).Count(&admins).Error; err != nil {
+		return err
+	}
+	if admins > 0 {
+		return nil
+	}
+
+	var user models.User
+	result := DB.Where("LOWER(email) = LOWER(?)", email).Limit(1).Find(&user)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		if err := DB.Model(&user).Updates(map[string]interface{}{
+			"role":              models.RoleAdmin,
+			"authorized":        true,
+			"status":            models.StatusApproved,
+			"email_verified_at": gorm.Expr("COALESCE(email_verified_at, ?)", time.Now()),
+		}).Error; err != nil {
+			return err
+		}
+		log.Println("👤 Conta promovida a administrador inicial:", email)
+		return nil
+	}
+
+	password := os.Getenv("INITIAL_ADMIN_PASSWORD")
+	if password == "" {
+		return errors.New("INITIAL_ADMIN_PASSWORD é obrigatório para criar o administrador inicial")
+	}
+	if problems := utils.CheckPasswordPolicy(password); len(problems) > 0 {
+		return fmt.Errorf("INITIAL_ADMIN_PASSWORD não atende à política de senhas: %s", strings.Join(problems, "; "))
+	}
+	name := strings.TrimSpace(os.Getenv("INITIAL_ADMIN_NAME"))
+	if name == "" {
+		name = "Administrador"
+	}
+
+	// O e-mail é informado por quem opera o serviço, sem confirmação por link
+	now := time.Now()
+	user = models.User{
+		Name:            name,
+		Email:           email,
+		EmailVerifiedAt: &now,
+		Password:        password,
+		Role:            models.RoleAdmin,
+		Status:          models.StatusApproved,
+		Authorized:      true,
+	}
+	if err := user.HashPassword(); err != nil {
+		return err
+	}
+	if err := DB.Create(&user).Error; err != nil {
+		return err
+	}
+	log.Println("👤 Administrador inicial criado:", email)
+	return nil
+}
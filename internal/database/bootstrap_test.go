@@ -0,0 +1,173 @@
+package database_test
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"user-service/internal/database"
+	"user-service/internal/testutil"
+	"user-service/internal/user/models"
+)
+
+func TestMain(m *testing.M) {
+	cleanup, err := testutil.Setup()
+	if err != nil {
+		fmt.Println("❌ Erro ao preparar os testes:", err)
+		os.Exit(1)
+	}
+	code := m.Run()
+	cleanup()
+	os.Exit(code)
+}
+
+func removeAdmins(t *testing.T) {
+	t.Helper()
+	if err := database.DB.Where("role = ?", models.RoleAdmin).Delete(&models.User{}).Error; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func findUser(t *testing.T, email string) (models.User, bool) {
+	t.Helper()
+	var user models.User
+	result := database.DB.Where("email = ?", email).Limit(1).Find(&user)
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	return user, result.RowsAffected > 0
+}
+
+func TestMigrateCreatesInitialAdmin(t *testing.T) {
+	removeAdmins(t)
+	t.Setenv("INITIAL_ADMIN_EMAIL", "admin-inicial@example.com")
+	t.Setenv("INITIAL_ADMIN_PASSWORD", "Senha-do-admin-1")
+
+	if err := database.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	admin, ok := findUser(t, "admin-inicial@example.com")
+	if !ok {
+		t.Fatal("administrador inicial não foi criado")
+	}
+	if admin.Role != models.RoleAdmin || !admin.Authorized || admin.Status != models.StatusApproved ||
+		admin.EmailVerifiedAt == nil || !admin.CheckPassword("Senha-do-admin-1") {
+		t.Errorf("administrador inicial inesperado: %+v", admin)
+	}
+}
+
+func TestMigratePromotesExistingAccount(t *testing.T) {
+	removeAdmins(t)
+	user := models.User{Name: "Operadora", Email: "operadora@example.com", Password: "x", Role: models.RoleCliente}
+	if err := database.DB.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("INITIAL_ADMIN_EMAIL", "OPERADORA@example.com")
+
+	if err := database.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	if promoted, _ := findUser(t, "operadora@example.com"); promoted.Role != models.RoleAdmin || !promoted.Authorized || promoted.Status != models.StatusApproved {
+		t.Errorf("conta não promovida: %+v", promoted)
+	}
+}
+
+// Com um administrador já existente, a variável não cria outro.
+func TestMigrateKeepsExistingAdmins(t *testing.T) {
+	removeAdmins(t)
+	admin := models.User{Name: "Admin", Email: "admin-atual@example.com", Password: "x", Role: models.RoleAdmin, Authorized: true}
+	if err := database.DB.Create(&admin).Error; err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("INITIAL_ADMIN_EMAIL", "outro-admin@example.com")
+	t.Setenv("INITIAL_ADMIN_PASSWORD", "Senha-do-admin-1")
+
+	if err := database.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := findUser(t, "outro-admin@example.com"); ok {
+		t.Error("segundo administrador criado")
+	}
+}
+
+func TestMigrateRequiresInitialAdminPassword(t *testing.T) {
+	removeAdmins(t)
+	t.Setenv("INITIAL_ADMIN_EMAIL", "sem-senha@example.com")
+	t.Setenv("INITIAL_ADMIN_PASSWORD", "")
+
+	if err := database.Migrate(); err == nil {
+		t.Error("administrador criado sem senha")
+	}
+}
+
+func TestMigrateRejectsWeakInitialAdminPassword(t *testing.T) {
+	removeAdmins(t)
+	t.Setenv("INITIAL_ADMIN_EMAIL", "senha-fraca@example.com")
+	t.Setenv("INITIAL_ADMIN_PASSWORD", "123456")
+
+	if err := database.Migrate(); err == nil {
+		t.Error("administrador criado com senha fora da política")
+	}
+}
+
+// Usuários anteriores à coluna email_verified_at são tratados como verificados.
+func TestMigrateBackfillsEmailVerified(t *testing.T) {
+	user := models.User{Name: "Antiga", Email: "antiga@example.com", Password: "x", Role: models.RoleCliente}
+	if err := database.DB.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := database.DB.Migrator().DropColumn(&models.User{}, "EmailVerifiedAt"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := database.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.DB.First(&user, "id = ?", user.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if user.EmailVerifiedAt == nil {
+		t.Error("email_verified_at não preenchido")
+	}
+
+	// Depois da coluna criada, novos usuários seguem pendentes
+	pending := models.User{Name: "Nova", Email: "nova@example.com", Password: "x", Role: models.RoleCliente}
+	if err := database.DB.Create(&pending).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := database.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.DB.First(&pending, "id = ?", pending.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if pending.EmailVerifiedAt != nil {
+		t.Error("usuário novo marcado como verificado")
+	}
+}
+
+// Notas gravadas antes das avaliações são zeradas; as calculadas a partir de
+// avaliações são mantidas.
+func TestMigrateResetsRatingsWithoutReviews(t *testing.T) {
+	legacy := models.User{Name: "Legado", Email: "legado@example.com", Password: "x", Role: models.RoleInstalador, AverageRating: 4.8}
+	reviewed := models.User{Name: "Avaliado", Email: "avaliado@example.com", Password: "x", Role: models.RoleInstalador, AverageRating: 4.5, ReviewCount: 2}
+	for _, user := range []*models.User{&legacy, &reviewed} {
+		if err := database.DB.Create(user).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := database.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	for _, user := range []*models.User{&legacy, &reviewed} {
+		if err := database.DB.First(user, "id = ?", user.ID).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if legacy.AverageRating != 0 {
+		t.Errorf("nota sem avaliações = %v, esperado 0", legacy.AverageRating)
+	}
+	if reviewed.AverageRating != 4.5 {
+		t.Errorf("nota com avaliações = %v, esperado 4.5", reviewed.AverageRating)
+	}
+}
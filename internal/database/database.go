@@ -1,13 +1,16 @@
 package database
 
 import (
+	"fmt"
 	"log"
 	"os"
+	"time"
 	"user-service/internal/user/models"
 
 	"github.com/joho/godotenv"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 var DB *gorm.DB
@@ -38,17 +41,140 @@ func ConnectDatabase() {
 		log.Fatalf("❌ DATABASE_URL não encontrada para o ambiente '%s'", env)
 	}
 
-	// Conecta ao PostgreSQL
+	// Conecta ao PostgreSQL; TranslateError expõe violações de unicidade
+	// como gorm.ErrDuplicatedKey
 	var err error
-	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true})
 	if err != nil {
 		log.Fatal("❌ Falha ao conectar no banco de dados:", err)
 	}
 
-	// Migração
-	if err := DB.AutoMigrate(&models.User{}); err != nil {
-		log.Fatal("❌ Falha ao migrar modelo User:", err)
+	if err := Migrate(); err != nil {
+		log.Fatal("❌ ", err)
 	}
 
 	log.Println("✅ Banco de dados conectado com sucesso")
 }
+
+// Migrate cria ou atualiza as tabelas e preenche os dados padrão e derivados
+// em DB. Chamado por ConnectDatabase; os testes o usam com um banco próprio.
+func Migrate() error {
+	// Contas anteriores à verificação de e-mail já estavam em uso e não devem
+	// ser barradas quando REQUIRE_EMAIL_VERIFICATION for ativado
+	backfillEmailVerified := !DB.Migrator().HasColumn(&models.User{}, "EmailVerifiedAt")
+	if err := DB.AutoMigrate(&models.User{}); err != nil {
+		return fmt.Errorf("falha ao migrar modelo User: %w", err)
+	}
+	if backfillEmailVerified {
+		if err := DB.Model(&models.User{}).Where("email_verified_at IS NULL").
+			Update("email_verified_at", gorm.Expr("COALESCE(created_at, ?)", time.Now())).Error; err != nil {
+			return fmt.Errorf("falha ao preencher verificação de e-mail dos usuários: %w", err)
+		}
+	}
+	if err := DB.AutoMigrate(&models.Session{}, &models.RefreshToken{}); err != nil {
+		return fmt.Errorf("falha ao migrar modelos Session e RefreshToken: %w", err)
+	}
+	if err := DB.AutoMigrate(&models.RevokedToken{}, &models.UserTokenRevocation{}); err != nil {
+		return fmt.Errorf("falha ao migrar modelos de revogação: %w", err)
+	}
+	if err := DB.AutoMigrate(&models.UserToken{}); err != nil {
+		return fmt.Errorf("falha ao migrar modelo UserToken: %w", err)
+	}
+	if err := DB.AutoMigrate(&models.MFARecoveryCode{}); err != nil {
+		return fmt.Errorf("falha ao migrar modelo MFARecoveryCode: %w", err)
+	}
+	if err := DB.AutoMigrate(&models.APIKey{}); err != nil {
+		return fmt.Errorf("falha ao migrar modelo APIKey: %w", err)
+	}
+	if err := DB.AutoMigrate(&models.AuditLog{}); err != nil {
+		return fmt.Errorf("falha ao migrar modelo AuditLog: %w", err)
+	}
+	if err := DB.AutoMigrate(&models.UserIdentity{}, &models.OIDCNonce{}); err != nil {
+		return fmt.Errorf("falha ao migrar modelos UserIdentity e OIDCNonce: %w", err)
+	}
+	if err := DB.AutoMigrate(&models.UserStatusHistory{}); err != nil {
+		return fmt.Errorf("falha ao migrar modelo UserStatusHistory: %w", err)
+	}
+	if err := DB.AutoMigrate(&models.EmailDelivery{}); err != nil {
+		return fmt.Errorf("falha ao migrar modelo EmailDelivery: %w", err)
+	}
+	if err := DB.AutoMigrate(&models.InstallerDocument{}); err != nil {
+		return fmt.Errorf("falha ao migrar modelo InstallerDocument: %w", err)
+	}
+	if err := DB.AutoMigrate(&models.ServiceArea{}); err != nil {
+		return fmt.Errorf("falha ao migrar modelo ServiceArea: %w", err)
+	}
+
+	if err := backfillServiceAreas(); err != nil {
+		return fmt.Errorf("falha ao preencher índices das áreas de atendimento: %w", err)
+	}
+	if err := DB.AutoMigrate(&models.Specialty{}, &models.ChargerBrand{}, &models.InstallerSpecialty{}, &models.InstallerBrand{}); err != nil {
+		return fmt.Errorf("falha ao migrar modelos de especialidades e marcas: %w", err)
+	}
+	if err := seedCatalog(); err != nil {
+		return fmt.Errorf("falha ao criar catálogo de especialidades e marcas: %w", err)
+	}
+	if err := DB.AutoMigrate(&models.Review{}, &models.Booking{}); err != nil {
+		return fmt.Errorf("falha ao migrar modelos de avaliações: %w", err)
+	}
+	if err := DB.AutoMigrate(&models.WorkingHours{}, &models.BlockedDate{}); err != nil {
+		return fmt.Errorf("falha ao migrar modelos de disponibilidade: %w", err)
+	}
+	if err := DB.AutoMigrate(&models.InstallerStatsEvent{}); err != nil {
+		return fmt.Errorf("falha ao migrar modelo InstallerStatsEvent: %w", err)
+	}
+
+	// Usuários anteriores à coluna status: autorizados passam a aprovados
+	if err := DB.Model(&models.User{}).
+		Where("authorized = ? AND (status IS NULL OR status = '' OR status = ?)", true, models.StatusPending).
+		Update("status", models.StatusApproved).Error; err != nil {
+		return fmt.Errorf("falha ao preencher status dos usuários: %w", err)
+	}
+
+	// Notas definidas antes das avaliações não têm avaliações que as
+	// sustentem; a nota passa a ser calculada a partir delas
+	if err := DB.Model(&models.User{}).
+		Where("review_count = 0 AND average_rating <> 0").
+		Update("average_rating", 0).Error; err != nil {
+		return fmt.Errorf("falha ao zerar notas sem avaliações: %w", err)
+	}
+
+	if err := bootstrapAdmin(); err != nil {
+		return fmt.Errorf("falha ao criar administrador inicial: %w", err)
+	}
+	return nil
+}
+
+// backfillServiceAreas recalcula as colunas de filtro (caixa do polígono e
+// critérios por cidade/UF) de áreas salvas antes de elas existirem.
+func backfillServiceAreas() error {
+	var areas []models.ServiceArea
+	err := DB.Where("(polygon_min_lat IS NULL AND polygon NOT IN ('null', '[]')) OR " +
+		"(place_ufs = '' AND place_cities = '' AND (cities NOT IN ('null', '[]') OR states NOT IN ('null', '[]')))").
+		Find(&areas).Error
+	if err != nil {
+		return err
+	}
+	for i := range areas {
+		if err := DB.Save(&areas[i]).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// seedCatalog cria as especialidades e marcas padrão que ainda não existem,
+// sem alterar as editadas.
+func seedCatalog() error {
+	for _, specialty := range models.DefaultSpecialties {
+		if err := DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&specialty).Error; err != nil {
+			return err
+		}
+	}
+	for _, brand := range models.DefaultChargerBrands {
+		if err := DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&brand).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
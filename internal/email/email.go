@@ -2,11 +2,21 @@ package email
 
 import (
 	"bytes"
+	"embed"
 	"encoding/json"
 	"fmt"
+	"html/template"
 	"net/http"
+	"os"
+	"time"
+	"user-service/internal/utils"
 )
 
+//go:embed templates/*.html
+var templatesFS embed.FS
+
+var templates = template.Must(template.ParseFS(templatesFS, "templates/*.html"))
+
 type InstallerData struct {
 	Name         string `json:"name"`
 	Email        string `json:"email"`
@@ -25,14 +35,34 @@ type InstallerData struct {
 	Reference    string `json:"reference"`
 }
 
-func NotifyNewInstaller(data InstallerData) error {
-	url := "https://mail.api-castilho.com.br/send-email-new-installer"
+// Message é um e-mail genérico enviado pela API de e-mail.
+type Message struct {
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+	HTML    string `json:"html"`
+}
+
+// apiURL monta a URL da API de e-mail (EMAIL_API_URL, com fallback para o
+// endereço de produção).
+func apiURL(path string) string {
+	base := os.Getenv("EMAIL_API_URL")
+	if base == "" {
+		base = "https://mail.api-castilho.com.br"
+	}
+	return base + path
+}
+
+// client é usado em todas as chamadas à API de e-mail; o timeout
+// (EMAIL_API_TIMEOUT, padrão 10s) evita que uma API lenta prenda a requisição.
+var client = &http.Client{Timeout: utils.GetEnvDuration("EMAIL_API_TIMEOUT", 10*time.Second)}
+
+func postJSON(path string, data interface{}) error {
 	payload, err := json.Marshal(data)
 	if err != nil {
 		return fmt.Errorf("erro ao serializar dados para JSON: %v", err)
 	}
 
-	resp, err := http.Post(url, "application/json", bytes.NewBuffer(payload))
+	resp, err := client.Post(apiURL(path), "application/json", bytes.NewBuffer(payload))
 	if err != nil {
 		return fmt.Errorf("erro ao enviar Post: %v", err)
 	}
@@ -43,3 +73,97 @@ func NotifyNewInstaller(data InstallerData) error {
 	}
 	return nil
 }
+
+// render executa o template HTML informado com os dados.
+func render(name string, data interface{}) (string, error) {
+	var buf bytes.Buffer
+	if err := templates.ExecuteTemplate(&buf, name, data); err != nil {
+		return "", fmt.Errorf("erro ao renderizar template %s: %v", name, err)
+	}
+	return buf.String(), nil
+}
+
+// Send envia um e-mail genérico pela rota POST /send-email da API de e-mail,
+// que recebe um JSON {"to", "subject", "html"} e responde 200 ou 201 quando
+// aceita a mensagem; qualquer outro status é tratado como falha.
+func Send(msg Message) error {
+	return postJSON("/send-email", msg)
+}
+
+func NotifyNewInstaller(data InstallerData) error {
+	return postJSON("/send-email-new-installer", data)
+}
+
+// PasswordResetData alimenta o template de redefinição de senha. Link pode
+// ficar vazio quando PASSWORD_RESET_URL não estiver configurada; nesse caso o
+// e-mail traz apenas o código.
+type PasswordResetData struct {
+	Name         string
+	Link         string
+	Token        string
+	ValidMinutes int
+}
+
+// SendPasswordReset envia ao usuário as instruções para redefinir a senha.
+func SendPasswordReset(to string, data PasswordResetData) error {
+	html, err := render("password_reset.html", data)
+	if err != nil {
+		return err
+	}
+	return Send(Message{
+		To:      to,
+		Subject: "EletriHub - Redefinição de senha",
+		HTML:    html,
+	})
+}
+
+// EmailVerificationData alimenta o template de confirmação de e-mail. Assim
+// como na redefinição de senha, Link pode ficar vazio.
+type EmailVerificationData struct {
+	Name       string
+	Link       string
+	Token      string
+	ValidHours int
+}
+
+// SendEmailVerification envia ao usuário o link de confirmação do e-mail.
+func SendEmailVerification(to string, data EmailVerificationData) error {
+	html, err := render("email_verification.html", data)
+	if err != nil {
+		return err
+	}
+	return Send(Message{
+		To:      to,
+		Subject: "EletriHub - Confirme seu e-mail",
+		HTML:    html,
+	})
+}
+
+// StatusChangeData alimenta o template de mudança de status do cadastro.
+// Status é um dos valores de models.User.Status.
+type StatusChangeData struct {
+	Name   string
+	Status string
+	Reason string
+}
+
+var statusChangeSubjects = map[string]string{
+	"approved":   "EletriHub - Cadastro aprovado",
+	"rejected":   "EletriHub - Cadastro não aprovado",
+	"suspended":  "EletriHub - Conta suspensa",
+	"reinstated": "EletriHub - Conta reativada",
+}
+
+// StatusChangeMessage monta o e-mail que informa ao usuário a nova situação
+// do cadastro, sem enviá-lo, para que o envio possa ser repetido e registrado.
+func StatusChangeMessage(to string, data StatusChangeData) (Message, error) {
+	subject, ok := statusChangeSubjects[data.Status]
+	if !ok {
+		return Message{}, fmt.Errorf("status sem e-mail definido: %s", data.Status)
+	}
+	html, err := render("status_change.html", data)
+	if err != nil {
+		return Message{}, err
+	}
+	return Message{To: to, Subject: subject, HTML: html}, nil
+}
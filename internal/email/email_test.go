@@ -0,0 +1,86 @@
+package email
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSendPostsMessage(t *testing.T) {
+	var (
+		path string
+		got  Message
+	)
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer api.Close()
+	t.Setenv("EMAIL_API_URL", api.URL)
+
+	msg := Message{To: "ana@example.com", Subject: "Assunto", HTML: "<p>Olá</p>"}
+	if err := Send(msg); err != nil {
+		t.Fatal(err)
+	}
+	if path != "/send-email" {
+		t.Errorf("rota = %q, esperado /send-email", path)
+	}
+	if got != msg {
+		t.Errorf("mensagem = %+v, esperado %+v", got, msg)
+	}
+}
+
+func TestSendRejectedByAPI(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer api.Close()
+	t.Setenv("EMAIL_API_URL", api.URL)
+
+	if err := Send(Message{To: "ana@example.com"}); err == nil {
+		t.Error("falha da API não reportada")
+	}
+}
+
+func TestSendTimeout(t *testing.T) {
+	release := make(chan struct{})
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer api.Close()
+	defer close(release)
+	t.Setenv("EMAIL_API_URL", api.URL)
+
+	previous := client
+	client = &http.Client{Timeout: 50 * time.Millisecond}
+	defer func() { client = previous }()
+
+	start := time.Now()
+	if err := Send(Message{To: "ana@example.com"}); err == nil {
+		t.Error("envio sem resposta não expirou")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("envio levou %v", elapsed)
+	}
+}
+
+func TestStatusChangeMessage(t *testing.T) {
+	msg, err := StatusChangeMessage("ana@example.com", StatusChangeData{Name: "Ana", Status: "rejected", Reason: "CPF inválido"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.To != "ana@example.com" || msg.Subject != "EletriHub - Cadastro não aprovado" ||
+		!strings.Contains(msg.HTML, "rejeitado") || !strings.Contains(msg.HTML, "CPF inválido") {
+		t.Errorf("mensagem inesperada: %+v", msg)
+	}
+
+	if _, err := StatusChangeMessage("ana@example.com", StatusChangeData{Status: "pending"}); err == nil {
+		t.Error("status sem e-mail aceito")
+	}
+}
@@ -0,0 +1,198 @@
+package geo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// DistanceKm calcula a distância em km entre dois pontos (fórmula de haversine).
+func DistanceKm(lat1, lon1, lat2, lon2 float64) float64 {
+	const R = 6371
+	dLat := (lat2 - lat1) * math.Pi / 180
+	dLon := (lon2 - lon1) * math.Pi / 180
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*math.Pi/180)*math.Cos(lat2*math.Pi/180)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	c := 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+	return R * c
+}
+
+// BoundingBox retorna a caixa (latitudes e longitudes mínimas e máximas) que
+// contém o círculo de raio radiusKm em torno do ponto, para pré-filtrar
+// consultas antes do cálculo exato da distância.
+func BoundingBox(lat, lng, radiusKm float64) (minLat, maxLat, minLng, maxLng float64) {
+	latPerKm, lngPerKm := DegreesPerKm(lat)
+	dLat, dLng := radiusKm*latPerKm, radiusKm*lngPerKm
+	return lat - dLat, lat + dLat, lng - dLng, lng + dLng
+}
+
+// DegreesPerKm retorna quantos graus de latitude e de longitude correspondem a
+// 1 km na latitude informada (a longitude encolhe em direção aos polos).
+func DegreesPerKm(lat float64) (latPerKm, lngPerKm float64) {
+	const kmPerDegree = 111.32
+	return 1 / kmPerDegree, 1 / (kmPerDegree * math.Max(math.Cos(lat*math.Pi/180), 0.01))
+}
+
+// MultiPolygon segue as coordenadas GeoJSON: polígonos → anéis → pontos
+// [longitude, latitude]. O primeiro anel de cada polígono é o contorno e os
+// demais são buracos.
+type MultiPolygon [][][][2]float64
+
+// ParseGeoJSON aceita uma geometria GeoJSON Polygon ou MultiPolygon (também
+// dentro de um Feature) e a normaliza como MultiPolygon.
+func ParseGeoJSON(raw json.RawMessage) (MultiPolygon, error) {
+	var object struct {
+		Type        string          `json:"type"`
+		Coordinates json.RawMessage `json:"coordinates"`
+		Geometry    json.RawMessage `json:"geometry"`
+	}
+	if err := json.Unmarshal(raw, &object); err != nil {
+		return nil, errors.New("GeoJSON inválido")
+	}
+
+	var polygons MultiPolygon
+	switch object.Type {
+	case "Feature":
+		return ParseGeoJSON(object.Geometry)
+	case "Polygon":
+		var polygon [][][2]float64
+		if err := json.Unmarshal(object.Coordinates, &polygon); err != nil {
+			return nil, errors.New("coordenadas do Polygon inválidas")
+		}
+		polygons = MultiPolygon{polygon}
+	case "MultiPolygon":
+		if err := json.Unmarshal(object.Coordinates, &polygons); err != nil {
+			return nil, errors.New("coordenadas do MultiPolygon inválidas")
+		}
+	default:
+		return nil, fmt.Errorf("tipo GeoJSON não suportado: %q (use Polygon ou MultiPolygon)", object.Type)
+	}
+
+	if len(polygons) == 0 {
+		return nil, errors.New("GeoJSON sem polígonos")
+	}
+	for _, polygon := range polygons {
+		if len(polygon) == 0 {
+			return nil, errors.New("polígono sem contorno")
+		}
+		for _, ring := range polygon {
+			if len(ring) < 4 {
+				return nil, errors.New("cada anel precisa de pelo menos 4 pontos")
+			}
+			for _, p := range ring {
+				if p[0] < -180 || p[0] > 180 || p[1] < -90 || p[1] > 90 {
+					return nil, errors.New("coordenada fora dos limites")
+				}
+			}
+		}
+	}
+	return polygons, nil
+}
+
+// GeoJSON retorna a geometria no formato GeoJSON MultiPolygon.
+func (m MultiPolygon) GeoJSON() map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "MultiPolygon",
+		"coordinates": m,
+	}
+}
+
+// Vertices retorna o total de pontos de todos os anéis.
+func (m MultiPolygon) Vertices() int {
+	n := 0
+	for _, polygon := range m {
+		for _, ring := range polygon {
+			n += len(ring)
+		}
+	}
+	return n
+}
+
+// Bounds retorna a caixa que contém todos os polígonos; ok é false se não
+// houver pontos.
+func (m MultiPolygon) Bounds() (minLat, maxLat, minLng, maxLng float64, ok bool) {
+	for _, polygon := range m {
+		for _, ring := range polygon {
+			for _, point := range ring {
+				lng, lat := point[0], point[1]
+				if !ok {
+					minLat, maxLat, minLng, maxLng, ok = lat, lat, lng, lng, true
+					continue
+				}
+				minLat, maxLat = math.Min(minLat, lat), math.Max(maxLat, lat)
+				minLng, maxLng = math.Min(minLng, lng), math.Max(maxLng, lng)
+			}
+		}
+	}
+	return
+}
+
+// Contains informa se o ponto está dentro de algum polígono (e fora de seus
+// buracos).
+func (m MultiPolygon) Contains(lat, lng float64) bool {
+	for _, polygon := range m {
+		if len(polygon) == 0 || !ringContains(polygon[0], lat, lng) {
+			continue
+		}
+		inHole := false
+		for _, hole := range polygon[1:] {
+			if ringContains(hole, lat, lng) {
+				inHole = true
+				break
+			}
+		}
+		if !inHole {
+			return true
+		}
+	}
+	return false
+}
+
+// ringContains aplica o algoritmo de ray casting ao anel.
+func ringContains(ring [][2]float64, lat, lng float64) bool {
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		xi, yi := ring[i][0], ring[i][1]
+		xj, yj := ring[j][0], ring[j][1]
+		if (yi > lat) != (yj > lat) && lng < (xj-xi)*(lat-yi)/(yj-yi)+xi {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// NormalizeName padroniza nomes de cidades para comparação: sem acentos, em
+// minúsculas e sem espaços extras ("São  Paulo" → "sao paulo").
+func NormalizeName(name string) string {
+	// transform.Chain guarda estado e não pode ser compartilhado entre goroutines
+	normalizer := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	result, _, err := transform.String(normalizer, name)
+	if err != nil {
+		result = name
+	}
+	return strings.Join(strings.Fields(strings.ToLower(result)), " ")
+}
+
+// Siglas das unidades federativas
+var UFs = []string{
+	"AC", "AL", "AM", "AP", "BA", "CE", "DF", "ES", "GO", "MA", "MG", "MS", "MT", "PA",
+	"PB", "PE", "PI", "PR", "RJ", "RN", "RO", "RR", "RS", "SC", "SE", "SP", "TO",
+}
+
+// IsUF informa se a sigla é de uma unidade federativa.
+func IsUF(uf string) bool {
+	uf = strings.ToUpper(strings.TrimSpace(uf))
+	for _, valid := range UFs {
+		if valid == uf {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package geo
+
+import (
+	"math"
+	"sync"
+	"testing"
+)
+
+func TestNormalizeName(t *testing.T) {
+	cases := map[string]string{
+		"São  Paulo":            "sao paulo",
+		" Ribeirão Preto ":      "ribeirao preto",
+		"FLORIANÓPOLIS":         "florianopolis",
+		"Santa Bárbara d'Oeste": "santa barbara d'oeste",
+	}
+	for in, want := range cases {
+		if got := NormalizeName(in); got != want {
+			t.Errorf("NormalizeName(%q) = %q, esperado %q", in, got, want)
+		}
+	}
+}
+
+// Executar com -race: o normalizador não pode ser compartilhado entre goroutines.
+func TestNormalizeNameConcurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			for j := 0; j < 200; j++ {
+				if got := NormalizeName("São José dos Campos"); got != "sao jose dos campos" {
+					t.Errorf("NormalizeName = %q", got)
+					return
+				}
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+}
+
+func TestDistanceKm(t *testing.T) {
+	// São Paulo (Sé) → Rio de Janeiro (Centro): cerca de 360 km
+	d := DistanceKm(-23.5505, -46.6333, -22.9068, -43.1729)
+	if math.Abs(d-360) > 10 {
+		t.Errorf("DistanceKm = %.1f, esperado ~360", d)
+	}
+	if d := DistanceKm(-23.5, -46.6, -23.5, -46.6); d != 0 {
+		t.Errorf("DistanceKm do mesmo ponto = %v", d)
+	}
+}
+
+func TestBoundingBoxContainsCircle(t *testing.T) {
+	lat, lng, radius := -23.5505, -46.6333, 50.0
+	minLat, maxLat, minLng, maxLng := BoundingBox(lat, lng, radius)
+	for _, p := range [][2]float64{{minLat, lng}, {maxLat, lng}, {lat, minLng}, {lat, maxLng}} {
+		if d := DistanceKm(lat, lng, p[0], p[1]); d < radius*0.99 {
+			t.Errorf("borda da caixa em %v está a %.1f km, menos que o raio", p, d)
+		}
+	}
+}
+
+func TestMultiPolygonContains(t *testing.T) {
+	mp, err := ParseGeoJSON([]byte(`{"type":"Polygon","coordinates":[[[-47,-24],[-46,-24],[-46,-23],[-47,-23],[-47,-24]]]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !mp.Contains(-23.5, -46.5) {
+		t.Error("ponto interno não reconhecido")
+	}
+	if mp.Contains(-22.5, -46.5) {
+		t.Error("ponto externo reconhecido como interno")
+	}
+}
+
+func TestIsUF(t *testing.T) {
+	if !IsUF(" sp ") || IsUF("XX") {
+		t.Error("IsUF incorreto")
+	}
+}
+
+func TestMultiPolygonBounds(t *testing.T) {
+	mp := MultiPolygon{
+		{{{-47, -24}, {-46, -24}, {-46, -23}, {-47, -24}}},
+		{{{-45, -22}, {-44, -22}, {-44, -21}, {-45, -22}}},
+	}
+	minLat, maxLat, minLng, maxLng, ok := mp.Bounds()
+	if !ok || minLat != -24 || maxLat != -21 || minLng != -47 || maxLng != -44 {
+		t.Errorf("Bounds = %v %v %v %v %v", minLat, maxLat, minLng, maxLng, ok)
+	}
+	if _, _, _, _, ok := (MultiPolygon{}).Bounds(); ok {
+		t.Error("Bounds de polígono vazio deveria retornar ok = false")
+	}
+}
+
+func TestMultiPolygonVertices(t *testing.T) {
+	mp := MultiPolygon{
+		{{{-47, -24}, {-46, -24}, {-46, -23}, {-47, -24}}, {{-46.6, -23.6}, {-46.4, -23.6}, {-46.5, -23.4}, {-46.6, -23.6}}},
+		{{{-45, -22}, {-44, -22}, {-44, -21}, {-45, -22}}},
+	}
+	if n := mp.Vertices(); n != 12 {
+		t.Errorf("Vertices = %d, esperado 12", n)
+	}
+}
@@ -0,0 +1,72 @@
+package loginguard
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFailBackoff(t *testing.T) {
+	store = NewMemoryStore()
+	policy := Policy{MaxFailures: 3, BaseLock: time.Minute, MaxLock: 5 * time.Minute, Window: time.Hour}
+	key := AccountKey("pessoa@example.com")
+
+	// Bloqueio esperado após cada falha: nenhum até o limite, depois o dobro a
+	// cada falha, até MaxLock
+	want := []time.Duration{0, 0, time.Minute, 2 * time.Minute, 4 * time.Minute, 5 * time.Minute, 5 * time.Minute}
+	for i, lock := range want {
+		Fail(key, policy)
+		got := LockedFor(key)
+		if got > lock || got < lock-time.Second {
+			t.Errorf("falha %d: bloqueio = %v, esperado %v", i+1, got, lock)
+		}
+	}
+
+	// Muitas falhas não podem estourar o cálculo e liberar a conta
+	for i := 0; i < 100; i++ {
+		Fail(key, policy)
+	}
+	if got := LockedFor(key); got < policy.MaxLock-time.Second {
+		t.Errorf("após 100 falhas: bloqueio = %v, esperado %v", got, policy.MaxLock)
+	}
+
+	if err := Reset(key); err != nil {
+		t.Fatal(err)
+	}
+	if got := LockedFor(key); got != 0 {
+		t.Errorf("após Reset: bloqueio = %v, esperado 0", got)
+	}
+}
+
+// LockedFor considera a mais restritiva das chaves.
+func TestLockedForUsesLongestLock(t *testing.T) {
+	store = NewMemoryStore()
+	policy := Policy{MaxFailures: 1, BaseLock: time.Minute, MaxLock: time.Hour, Window: time.Hour}
+	account, ip := AccountKey("pessoa@example.com"), IPKey("192.0.2.1")
+
+	Fail(account, policy)
+	Fail(ip, policy)
+	Fail(ip, policy)
+
+	if got := LockedFor(account, ip); got < 2*time.Minute-time.Second || got > 2*time.Minute {
+		t.Errorf("bloqueio = %v, esperado 2m", got)
+	}
+	if got := LockedFor(AccountKey("outra@example.com")); got != 0 {
+		t.Errorf("chave sem falhas bloqueada por %v", got)
+	}
+}
+
+func TestMemoryStoreForgetsFailuresAfterWindow(t *testing.T) {
+	s := NewMemoryStore()
+	if _, err := s.RegisterFailure("k", time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	failures, err := s.RegisterFailure("k", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if failures != 1 {
+		t.Errorf("falhas = %d, esperado 1", failures)
+	}
+}
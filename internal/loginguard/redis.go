@@ -0,0 +1,99 @@
+package loginguard
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisStore implementa Store sobre um servidor compatível com o protocolo do
+// Redis (Redis, Valkey, KeyDB, Dragonfly...). Cada chave é um hash com os
+// campos "failures" e "locked_until" (unix em milissegundos).
+type RedisStore struct {
+	client *redis.Client
+	prefix string
+}
+
+// Soma uma falha e estende a expiração sem encurtar uma maior já existente,
+// numa única operação atômica.
+var registerFailureScript = redis.NewScript(`
+local failures = redis.call('HINCRBY', KEYS[1], 'failures', 1)
+if redis.call('PTTL', KEYS[1]) < tonumber(ARGV[1]) then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return failures
+`)
+
+// Grava o bloqueio e estende a expiração da mesma forma.
+var lockScript = redis.NewScript(`
+redis.call('HSET', KEYS[1], 'locked_until', ARGV[1])
+if redis.call('PTTL', KEYS[1]) < tonumber(ARGV[2]) then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 1
+`)
+
+// NewRedisStore cria o store a partir de uma URL no formato
+// redis[s]://[usuário:senha@]host:porta[/db]; rediss usa TLS.
+func NewRedisStore(rawURL string) (*RedisStore, error) {
+	opts, err := redis.ParseURL(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("URL do Redis inválida: %w", err)
+	}
+	// Um comando repetido após falha de rede pode já ter sido executado; uma
+	// falha contada duas vezes bloquearia a conta antes do previsto
+	opts.MaxRetries = -1
+
+	store := &RedisStore{client: redis.NewClient(opts), prefix: "loginguard:"}
+
+	// Valida a conexão já na inicialização
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := store.client.Ping(ctx).Err(); err != nil {
+		store.client.Close()
+		return nil, err
+	}
+	return store, nil
+}
+
+func (s *RedisStore) Get(key string) (Entry, error) {
+	values, err := s.client.HMGet(context.Background(), s.prefix+key, "failures", "locked_until").Result()
+	if err != nil {
+		return Entry{}, err
+	}
+
+	var entry Entry
+	if v, ok := values[0].(string); ok {
+		entry.Failures, _ = strconv.Atoi(v)
+	}
+	if v, ok := values[1].(string); ok {
+		if ms, err := strconv.ParseInt(v, 10, 64); err == nil {
+			entry.LockedUntil = time.UnixMilli(ms)
+		}
+	}
+	return entry, nil
+}
+
+func (s *RedisStore) RegisterFailure(key string, ttl time.Duration) (int, error) {
+	failures, err := registerFailureScript.Run(context.Background(), s.client,
+		[]string{s.prefix + key}, ttl.Milliseconds()).Int()
+	if err != nil {
+		return 0, err
+	}
+	return failures, nil
+}
+
+func (s *RedisStore) Lock(key string, until time.Time, ttl time.Duration) error {
+	if remaining := time.Until(until); remaining > ttl {
+		ttl = remaining
+	}
+	return lockScript.Run(context.Background(), s.client,
+		[]string{s.prefix + key}, until.UnixMilli(), ttl.Milliseconds()).Err()
+}
+
+func (s *RedisStore) Reset(key string) error {
+	return s.client.Del(context.Background(), s.prefix+key).Err()
+}
@@ -0,0 +1,116 @@
+package loginguard
+
+import (
+	"sync"
+	"time"
+)
+
+// Entry é o estado de tentativas malsucedidas de uma chave (conta ou IP).
+type Entry struct {
+	Failures    int
+	LockedUntil time.Time
+}
+
+// Store guarda as tentativas malsucedidas. A implementação em memória atende
+// uma única instância; com várias réplicas use um armazenamento compartilhado
+// compatível com Redis (NewRedisStore).
+type Store interface {
+	// Get retorna o estado atual da chave (zerado se inexistente).
+	Get(key string) (Entry, error)
+	// RegisterFailure soma uma falha de forma atômica e retorna o novo total.
+	// A chave expira após ttl sem novas falhas.
+	RegisterFailure(key string, ttl time.Duration) (int, error)
+	// Lock bloqueia a chave até until, mantendo-a por pelo menos ttl.
+	Lock(key string, until time.Time, ttl time.Duration) error
+	// Reset remove o estado da chave.
+	Reset(key string) error
+}
+
+type memoryEntry struct {
+	Entry
+	expiresAt time.Time
+}
+
+// MemoryStore é o Store padrão, mantido na memória do processo.
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]*memoryEntry
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: map[string]*memoryEntry{}}
+}
+
+// entry retorna a entrada válida da chave; deve ser chamado com o lock.
+func (s *MemoryStore) entry(key string) *memoryEntry {
+	e, ok := s.entries[key]
+	if ok && time.Now().After(e.expiresAt) {
+		delete(s.entries, key)
+		return nil
+	}
+	return e
+}
+
+func (s *MemoryStore) Get(key string) (Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e := s.entry(key); e != nil {
+		return e.Entry, nil
+	}
+	return Entry{}, nil
+}
+
+func (s *MemoryStore) RegisterFailure(key string, ttl time.Duration) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e := s.entry(key)
+	if e == nil {
+		e = &memoryEntry{}
+		s.entries[key] = e
+	}
+	e.Failures++
+	if exp := time.Now().Add(ttl); exp.After(e.expiresAt) {
+		e.expiresAt = exp
+	}
+
+	// Aproveita para descartar chaves expiradas e manter o mapa pequeno
+	if len(s.entries) > 10000 {
+		now := time.Now()
+		for k, v := range s.entries {
+			if now.After(v.expiresAt) {
+				delete(s.entries, k)
+			}
+		}
+	}
+
+	return e.Failures, nil
+}
+
+func (s *MemoryStore) Lock(key string, until time.Time, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e := s.entry(key)
+	if e == nil {
+		e = &memoryEntry{}
+		s.entries[key] = e
+	}
+	e.LockedUntil = until
+	if exp := time.Now().Add(ttl); exp.After(e.expiresAt) {
+		e.expiresAt = exp
+	}
+	if until.After(e.expiresAt) {
+		e.expiresAt = until
+	}
+	return nil
+}
+
+func (s *MemoryStore) Reset(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
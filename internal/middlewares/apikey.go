@@ -0,0 +1,75 @@
+package middlewares
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+	"user-service/internal/database"
+	"user-service/internal/user/models"
+	"user-service/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const apiKeyHeader = "X-API-Key"
+
+// APIKeyMiddleware protege rotas chamadas por outros serviços, autenticados
+// pelo cabeçalho X-API-Key em vez de um JWT de usuário. A chave precisa estar
+// ativa e conceder todos os escopos informados.
+func APIKeyMiddleware(scopes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		raw := c.GetHeader(apiKeyHeader)
+		if raw == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Chave de API ausente (cabeçalho X-API-Key)",
+			})
+			c.Abort()
+			return
+		}
+
+		var key models.APIKey
+		if err := database.DB.Where("key_hash = ?", utils.HashToken(raw)).First(&key).Error; err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Chave de API inválida",
+			})
+			c.Abort()
+			return
+		}
+
+		if key.RevokedAt != nil || (key.ExpiresAt != nil && time.Now().After(*key.ExpiresAt)) {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Chave de API revogada ou expirada",
+			})
+			c.Abort()
+			return
+		}
+
+		for _, scope := range scopes {
+			if !key.HasScope(scope) {
+				c.JSON(http.StatusForbidden, gin.H{
+					"error": "Chave de API sem o escopo necessário: " + scope,
+				})
+				c.Abort()
+				return
+			}
+		}
+
+		// Registra o uso sem atrasar a requisição
+		go func(id, ip string) {
+			if err := database.DB.Model(&models.APIKey{}).Where("id = ?", id).UpdateColumns(map[string]interface{}{
+				"last_used_at": time.Now(),
+				"last_used_ip": ip,
+				"usage_count":  gorm.Expr("usage_count + 1"),
+			}).Error; err != nil {
+				fmt.Println("⚠️ Erro ao registrar uso da chave de API:", err)
+			}
+		}(key.ID, c.ClientIP())
+
+		c.Set("api_key_id", key.ID)
+		c.Set("api_key_name", key.Name)
+		c.Set("scopes", key.Scopes)
+
+		c.Next()
+	}
+}
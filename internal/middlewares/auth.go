@@ -1,8 +1,15 @@
 package middlewares
 
 import (
+	"fmt"
 	"net/http"
+	"slices"
 	"strings"
+	"sync"
+	"time"
+	"user-service/internal/database"
+	"user-service/internal/revocation"
+	"user-service/internal/user/models"
 	"user-service/internal/utils"
 
 	"github.com/gin-gonic/gin"
@@ -13,6 +20,13 @@ const bearerPrefix = "Bearer "
 
 // AuthMiddleware protege rotas que exigem autenticação JWT.
 func AuthMiddleware() gin.HandlerFunc {
+	return AuthMiddlewareWithPurposes()
+}
+
+// AuthMiddlewareWithPurposes aceita, além dos tokens de acesso comuns, os
+// tokens com as finalidades informadas (ex.: utils.TokenPurposeOnboarding nas
+// rotas de documentos). A finalidade fica em "token_purpose" no contexto.
+func AuthMiddlewareWithPurposes(purposes ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 
@@ -28,15 +42,10 @@ func AuthMiddleware() gin.HandlerFunc {
 		// Extrai o token do header
 		tokenString := strings.TrimPrefix(authHeader, bearerPrefix)
 
-		// Prepara estrutura para os claims do token
-		claims := &utils.Claims{}
-
-		// Valida e parseia o token
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			return utils.SecretKey, nil
-		})
-
-		if err != nil || !token.Valid {
+		// Valida e parseia o token; tokens com finalidade específica (ex.: desafio
+		// MFA) só dão acesso às rotas que a aceitam explicitamente
+		claims, err := utils.ParseToken(tokenString)
+		if err != nil || (claims.Purpose != "" && !slices.Contains(purposes, claims.Purpose)) {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"error": "Token inválido ou expirado",
 			})
@@ -44,10 +53,91 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		// Token válido — injeta dados no contexto da requisição
+		// Verifica se o token foi revogado (logout, exclusão, troca de senha...)
+		revoked, err := revocation.IsRevoked(claims)
+		// Na personificação, revogar o administrador também derruba o token
+		if err == nil && !revoked && claims.Actor != nil {
+			revoked, err = revocation.IsRevoked(&utils.Claims{
+				UserID:           claims.Actor.Subject,
+				RegisteredClaims: jwt.RegisteredClaims{IssuedAt: claims.IssuedAt},
+			})
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Erro ao validar token",
+			})
+			c.Abort()
+			return
+		}
+		if revoked {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Token revogado",
+			})
+			c.Abort()
+			return
+		}
+
+		// Token válido — injeta dados no contexto da requisição. actor_id é
+		// sempre quem de fato age: o administrador, durante uma personificação
 		c.Set("user_id", claims.UserID)
 		c.Set("role", claims.Role)
+		c.Set("actor_id", claims.UserID)
+		if claims.Actor != nil {
+			c.Set("actor_id", claims.Actor.Subject)
+			c.Set("impersonating", true)
+		}
+		c.Set("token_purpose", claims.Purpose)
+		c.Set("jti", claims.ID)
+		c.Set("session_id", claims.SessionID)
+		if claims.ExpiresAt != nil {
+			c.Set("token_expires_at", claims.ExpiresAt.Time)
+		}
+
+		touchSession(claims.SessionID, c.ClientIP())
 
 		c.Next()
+
+		if claims.Actor != nil {
+			recordImpersonatedRequest(c, claims.UserID)
+		}
+	}
+}
+
+var (
+	sessionTouchMu sync.Mutex
+	sessionTouched = map[string]time.Time{}
+)
+
+// touchSession atualiza o last_seen_at da sessão no máximo uma vez por minuto
+// por instância, sem atrasar a requisição.
+func touchSession(sessionID, ip string) {
+	if sessionID == "" {
+		return
+	}
+
+	now := time.Now()
+	sessionTouchMu.Lock()
+	if last, ok := sessionTouched[sessionID]; ok && now.Sub(last) < time.Minute {
+		sessionTouchMu.Unlock()
+		return
 	}
+	sessionTouched[sessionID] = now
+	// Evita crescimento indefinido do mapa
+	if len(sessionTouched) > 10000 {
+		for id, last := range sessionTouched {
+			if now.Sub(last) >= time.Minute {
+				delete(sessionTouched, id)
+			}
+		}
+	}
+	sessionTouchMu.Unlock()
+
+	go func() {
+		if err := database.DB.Model(&models.Session{}).Where("id = ?", sessionID).Updates(map[string]interface{}{
+			"last_seen_at": now,
+			"ip":           ip,
+		}).Error; err != nil {
+			fmt.Println("⚠️ Erro ao atualizar última atividade da sessão:", err)
+		}
+	}()
 }
@@ -0,0 +1,38 @@
+package middlewares
+
+import (
+	"fmt"
+	"net/http"
+	"user-service/internal/audit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BlockImpersonation impede ações sensíveis (troca de senha, exclusão de conta,
+// MFA, sessões...) com um token de personificação. Deve ser usado após
+// AuthMiddleware.
+func BlockImpersonation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetBool("impersonating") {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "Ação não permitida durante a personificação de um usuário",
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// recordImpersonatedRequest registra na auditoria cada requisição feita com um
+// token de personificação, inclusive as recusadas, com o administrador como
+// ator e o usuário personificado como alvo.
+func recordImpersonatedRequest(c *gin.Context, targetUserID string) {
+	if err := audit.Record(c, audit.ActionImpersonationRequest, targetUserID, map[string]interface{}{
+		"method": c.Request.Method,
+		"path":   c.Request.URL.Path,
+		"status": c.Writer.Status(),
+	}); err != nil {
+		fmt.Println("⚠️ Erro ao registrar requisição personificada na auditoria:", err)
+	}
+}
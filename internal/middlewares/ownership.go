@@ -0,0 +1,34 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireOwnerOrRole garante que o usuário autenticado só altere o próprio
+// registro (parâmetro :id da rota), a menos que seu papel esteja entre os
+// informados. Rotas sem o parâmetro :id (ex.: /user/me) passam direto.
+// Deve ser usado após AuthMiddleware.
+func RequireOwnerOrRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		if id == "" || id == c.GetString("user_id") {
+			c.Next()
+			return
+		}
+
+		role := c.GetString("role")
+		for _, allowed := range roles {
+			if role == allowed {
+				c.Next()
+				return
+			}
+		}
+
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Acesso negado: você só pode alterar o próprio usuário",
+		})
+		c.Abort()
+	}
+}
@@ -0,0 +1,27 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireRole permite o acesso apenas a usuários cujo papel (definido pelo
+// AuthMiddleware) esteja entre os informados. Deve ser usado após AuthMiddleware.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.GetString("role")
+
+		for _, allowed := range roles {
+			if role == allowed {
+				c.Next()
+				return
+			}
+		}
+
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Acesso negado: permissão insuficiente",
+		})
+		c.Abort()
+	}
+}
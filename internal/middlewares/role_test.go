@@ -0,0 +1,82 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// withIdentity simula o AuthMiddleware, colocando usuário e papel no contexto.
+func withIdentity(userID, role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if userID != "" {
+			c.Set("user_id", userID)
+			c.Set("role", role)
+		}
+		c.Next()
+	}
+}
+
+func serve(r *gin.Engine, method, path string) int {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+	return w.Code
+}
+
+func TestRequireRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name   string
+		userID string
+		role   string
+		want   int
+	}{
+		{"papel permitido", "u1", "admin", http.StatusOK},
+		{"outro papel permitido", "u1", "instalador", http.StatusOK},
+		{"papel não permitido", "u1", "cliente", http.StatusForbidden},
+		{"sem identidade", "", "", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.GET("/x", withIdentity(tt.userID, tt.role), RequireRole("admin", "instalador"), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+			if got := serve(r, http.MethodGet, "/x"); got != tt.want {
+				t.Errorf("status = %d, esperado %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequireOwnerOrRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name   string
+		path   string
+		userID string
+		role   string
+		want   int
+	}{
+		{"próprio registro", "/user/u1", "u1", "cliente", http.StatusOK},
+		{"registro de outro", "/user/u2", "u1", "cliente", http.StatusForbidden},
+		{"registro de outro como admin", "/user/u2", "u1", "admin", http.StatusOK},
+		{"sem identidade", "/user/u1", "", "", http.StatusForbidden},
+		{"rota sem :id", "/user/me", "u1", "cliente", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			handler := func(c *gin.Context) { c.Status(http.StatusOK) }
+			r.PUT("/user/me", withIdentity(tt.userID, tt.role), RequireOwnerOrRole("admin"), handler)
+			r.PUT("/user/:id", withIdentity(tt.userID, tt.role), RequireOwnerOrRole("admin"), handler)
+			if got := serve(r, http.MethodPut, tt.path); got != tt.want {
+				t.Errorf("status = %d, esperado %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package oidc
+
+import (
+	"log"
+	"time"
+	"user-service/internal/database"
+	"user-service/internal/user/models"
+	"user-service/internal/utils"
+
+	"gorm.io/gorm/clause"
+)
+
+// ConsumeNonce registra o nonce da identidade até a expiração do ID token e
+// informa se ele ainda não havia sido usado. Identidades sem nonce (provedores
+// com OIDC_<NOME>_REQUIRE_NONCE=false) são sempre aceitas.
+func ConsumeNonce(identity *Identity) (bool, error) {
+	if identity.Nonce == "" {
+		return true, nil
+	}
+
+	result := database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.OIDCNonce{
+		Hash:      utils.HashToken(identity.Provider + ":" + identity.Nonce),
+		ExpiresAt: identity.ExpiresAt,
+	})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// janitor apaga periodicamente os nonces de tokens já expirados.
+func janitor(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := database.DB.Where("expires_at <= ?", time.Now()).Delete(&models.OIDCNonce{}).Error; err != nil {
+			log.Println("⚠️ Erro ao limpar nonces OIDC:", err)
+		}
+	}
+}
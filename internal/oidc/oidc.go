@@ -0,0 +1,94 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"user-service/internal/utils"
+)
+
+// Identity é o resultado de um ID token validado.
+type Identity struct {
+	Provider string
+	Subject  string
+	// Nonce e ExpiresAt permitem aceitar cada ID token uma única vez
+	Nonce         string
+	ExpiresAt     time.Time
+	Email         string
+	EmailVerified bool
+	Name          string
+	Picture       string
+}
+
+// Verifier valida um ID token de um provedor. A implementação padrão é
+// Provider (OIDC com discovery e JWKS); outras podem ser registradas com
+// Register, por exemplo um emissor local em testes.
+type Verifier interface {
+	Verify(ctx context.Context, rawIDToken, nonce string) (*Identity, error)
+}
+
+var ErrUnknownProvider = errors.New("provedor OIDC não configurado")
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Verifier{}
+)
+
+// Register associa um verificador ao nome do provedor (ex.: "google").
+func Register(name string, verifier Verifier) {
+	registryMu.Lock()
+	registry[strings.ToLower(name)] = verifier
+	registryMu.Unlock()
+}
+
+// Get retorna o verificador do provedor informado.
+func Get(name string) (Verifier, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	verifier, ok := registry[strings.ToLower(name)]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+	return verifier, nil
+}
+
+// Emissores conhecidos, usados quando OIDC_<NOME>_ISSUER não é informado
+var defaultIssuers = map[string][]string{
+	"google": {"https://accounts.google.com", "accounts.google.com"},
+	"apple":  {"https://appleid.apple.com"},
+}
+
+// Init registra os provedores listados em OIDC_PROVIDERS (ex.: "google,apple").
+// Para cada um são lidos OIDC_<NOME>_CLIENT_IDS (obrigatório: client IDs
+// aceitos como audiência), OIDC_<NOME>_ISSUER (emissores aceitos; o primeiro é
+// usado no discovery), OIDC_<NOME>_JWKS_URL (opcional, dispensa o discovery)
+// e OIDC_<NOME>_REQUIRE_NONCE (padrão true; desligar só para provedores que
+// não devolvem o nonce no ID token). Chamar após database.ConnectDatabase.
+func Init() error {
+	for _, name := range utils.GetEnvList("OIDC_PROVIDERS", nil) {
+		name = strings.ToLower(name)
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+
+		clientIDs := utils.GetEnvList(prefix+"CLIENT_IDS", nil)
+		if len(clientIDs) == 0 {
+			return fmt.Errorf("%sCLIENT_IDS não definido", prefix)
+		}
+		issuers := utils.GetEnvList(prefix+"ISSUER", defaultIssuers[name])
+		if len(issuers) == 0 {
+			return fmt.Errorf("%sISSUER não definido", prefix)
+		}
+
+		requireNonce := utils.GetEnvBool(prefix+"REQUIRE_NONCE", true)
+		Register(name, NewProvider(name, issuers, clientIDs, os.Getenv(prefix+"JWKS_URL"), requireNonce))
+		log.Printf("🔐 Login OIDC habilitado: %s (%s)", name, issuers[0])
+	}
+
+	go janitor(10 * time.Minute)
+	return nil
+}
@@ -0,0 +1,231 @@
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"user-service/internal/utils"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Intervalo mínimo entre recargas do JWKS disparadas por kid desconhecido
+const jwksMinRefresh = time.Minute
+
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// Provider valida ID tokens de um emissor OIDC: assinatura pelas chaves do
+// JWKS (obtido por discovery ou URL fixa), emissor, audiência, expiração e
+// nonce.
+type Provider struct {
+	name      string
+	issuers   []string
+	clientIDs []string
+	jwksURL   string
+	// requireNonce recusa tokens sem nonce, para provedores que o suportam
+	requireNonce bool
+
+	mu        sync.Mutex
+	keys      map[string]interface{}
+	fetchedAt time.Time
+}
+
+func NewProvider(name string, issuers, clientIDs []string, jwksURL string, requireNonce bool) *Provider {
+	return &Provider{
+		name:         name,
+		issuers:      issuers,
+		clientIDs:    clientIDs,
+		jwksURL:      jwksURL,
+		requireNonce: requireNonce,
+	}
+}
+
+type idTokenClaims struct {
+	Email         string      `json:"email"`
+	EmailVerified interface{} `json:"email_verified"`
+	Name          string      `json:"name"`
+	Picture       string      `json:"picture"`
+	Nonce         string      `json:"nonce"`
+	jwt.RegisteredClaims
+}
+
+func (p *Provider) Verify(ctx context.Context, rawIDToken, nonce string) (*Identity, error) {
+	claims := &idTokenClaims{}
+	_, err := jwt.ParseWithClaims(rawIDToken, claims,
+		func(token *jwt.Token) (interface{}, error) {
+			kid, _ := token.Header["kid"].(string)
+			return p.key(ctx, kid)
+		},
+		jwt.WithValidMethods([]string{"RS256", "ES256"}),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(utils.GetEnvDuration("JWT_CLOCK_SKEW", 30*time.Second)),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if !contains(p.issuers, claims.Issuer) {
+		return nil, fmt.Errorf("emissor inesperado: %q", claims.Issuer)
+	}
+	if !containsAny(p.clientIDs, claims.Audience) {
+		return nil, errors.New("audiência não corresponde a nenhum client ID configurado")
+	}
+	if claims.Subject == "" {
+		return nil, errors.New("token sem sub")
+	}
+	if p.requireNonce && nonce == "" {
+		return nil, errors.New("nonce obrigatório")
+	}
+	if nonce != "" && claims.Nonce != nonce {
+		return nil, errors.New("nonce não corresponde")
+	}
+
+	return &Identity{
+		Provider:      p.name,
+		Subject:       claims.Subject,
+		Nonce:         claims.Nonce,
+		ExpiresAt:     claims.ExpiresAt.Time,
+		Email:         strings.TrimSpace(claims.Email),
+		EmailVerified: isTrue(claims.EmailVerified),
+		Name:          claims.Name,
+		Picture:       claims.Picture,
+	}, nil
+}
+
+// isTrue interpreta email_verified, que a Apple envia como string "true".
+func isTrue(value interface{}) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case string:
+		return strings.EqualFold(v, "true")
+	}
+	return false
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAny(list []string, values []string) bool {
+	for _, value := range values {
+		if contains(list, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// key retorna a chave pública do kid, recarregando o JWKS quando o kid é
+// desconhecido (rotação de chaves do provedor), no máximo uma vez por minuto.
+func (p *Provider) key(ctx context.Context, kid string) (interface{}, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key, ok := p.keys[kid]; ok {
+		return key, nil
+	}
+	if time.Since(p.fetchedAt) < jwksMinRefresh {
+		return nil, fmt.Errorf("kid desconhecido: %q", kid)
+	}
+
+	keys, err := p.fetchKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	p.keys = keys
+	p.fetchedAt = time.Now()
+
+	if key, ok := p.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("kid desconhecido: %q", kid)
+}
+
+func (p *Provider) fetchKeys(ctx context.Context) (map[string]interface{}, error) {
+	if p.jwksURL == "" {
+		var discovery struct {
+			JWKSURI string `json:"jwks_uri"`
+		}
+		url := strings.TrimSuffix(p.issuers[0], "/") + "/.well-known/openid-configuration"
+		if err := getJSON(ctx, url, &discovery); err != nil {
+			return nil, fmt.Errorf("discovery de %s: %v", p.name, err)
+		}
+		if discovery.JWKSURI == "" {
+			return nil, fmt.Errorf("discovery de %s sem jwks_uri", p.name)
+		}
+		p.jwksURL = discovery.JWKSURI
+	}
+
+	var set struct {
+		Keys []struct {
+			Kid string `json:"kid"`
+			Kty string `json:"kty"`
+			Crv string `json:"crv"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		} `json:"keys"`
+	}
+	if err := getJSON(ctx, p.jwksURL, &set); err != nil {
+		return nil, fmt.Errorf("JWKS de %s: %v", p.name, err)
+	}
+
+	keys := map[string]interface{}{}
+	for _, k := range set.Keys {
+		switch {
+		case k.Kty == "RSA":
+			n, errN := base64.RawURLEncoding.DecodeString(k.N)
+			e, errE := base64.RawURLEncoding.DecodeString(k.E)
+			if errN != nil || errE != nil {
+				continue
+			}
+			keys[k.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		case k.Kty == "EC" && k.Crv == "P-256":
+			x, errX := base64.RawURLEncoding.DecodeString(k.X)
+			y, errY := base64.RawURLEncoding.DecodeString(k.Y)
+			if errX != nil || errY != nil {
+				continue
+			}
+			keys[k.Kid] = &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("JWKS de %s sem chaves suportadas", p.name)
+	}
+	return keys, nil
+}
+
+func getJSON(ctx context.Context, url string, target interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(target)
+}
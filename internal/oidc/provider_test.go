@@ -0,0 +1,208 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testKid      = "chave-1"
+	testClientID = "app-teste"
+)
+
+// stubIssuer é um emissor OIDC local: discovery e JWKS com uma chave RSA.
+type stubIssuer struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+}
+
+func newStubIssuer(t *testing.T) *stubIssuer {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuer := &stubIssuer{key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":   issuer.server.URL,
+			"jwks_uri": issuer.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kid": testKid,
+				"kty": "RSA",
+				"alg": "RS256",
+				"n":   base64.RawURLEncoding.EncodeToString(key.PublicKey.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.PublicKey.E)).Bytes()),
+			}},
+		})
+	})
+	issuer.server = httptest.NewServer(mux)
+	t.Cleanup(issuer.server.Close)
+	return issuer
+}
+
+// claims retorna claims válidas para o emissor, a alterar em cada caso.
+func (s *stubIssuer) claims() jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":            s.server.URL,
+		"aud":            testClientID,
+		"sub":            "123456",
+		"email":          "pessoa@example.com",
+		"email_verified": true,
+		"nonce":          "nonce-1",
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Hour).Unix(),
+	}
+}
+
+func (s *stubIssuer) sign(t *testing.T, claims jwt.MapClaims, kid string) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(s.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestProviderVerify(t *testing.T) {
+	issuer := newStubIssuer(t)
+	provider := NewProvider("teste", []string{issuer.server.URL}, []string{testClientID}, "", true)
+
+	identity, err := provider.Verify(context.Background(), issuer.sign(t, issuer.claims(), testKid), "nonce-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if identity.Provider != "teste" || identity.Subject != "123456" || identity.Email != "pessoa@example.com" ||
+		!identity.EmailVerified || identity.Nonce != "nonce-1" || identity.ExpiresAt.IsZero() {
+		t.Errorf("identidade inesperada: %+v", identity)
+	}
+}
+
+func TestProviderVerifyRejects(t *testing.T) {
+	issuer := newStubIssuer(t)
+	provider := NewProvider("teste", []string{issuer.server.URL}, []string{testClientID}, "", true)
+
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		token func() string
+		nonce string
+	}{
+		{"audiência de outro app", func() string {
+			claims := issuer.claims()
+			claims["aud"] = "outro-app"
+			return issuer.sign(t, claims, testKid)
+		}, "nonce-1"},
+		{"outro emissor", func() string {
+			claims := issuer.claims()
+			claims["iss"] = "https://emissor.invalido"
+			return issuer.sign(t, claims, testKid)
+		}, "nonce-1"},
+		{"expirado", func() string {
+			claims := issuer.claims()
+			claims["exp"] = time.Now().Add(-time.Hour).Unix()
+			return issuer.sign(t, claims, testKid)
+		}, "nonce-1"},
+		{"sem exp", func() string {
+			claims := issuer.claims()
+			delete(claims, "exp")
+			return issuer.sign(t, claims, testKid)
+		}, "nonce-1"},
+		{"sem sub", func() string {
+			claims := issuer.claims()
+			delete(claims, "sub")
+			return issuer.sign(t, claims, testKid)
+		}, "nonce-1"},
+		{"nonce ausente na requisição", func() string {
+			return issuer.sign(t, issuer.claims(), testKid)
+		}, ""},
+		{"nonce diferente", func() string {
+			return issuer.sign(t, issuer.claims(), testKid)
+		}, "nonce-2"},
+		{"kid desconhecido", func() string {
+			return issuer.sign(t, issuer.claims(), "chave-2")
+		}, "nonce-1"},
+		{"assinado por outra chave", func() string {
+			token := jwt.NewWithClaims(jwt.SigningMethodRS256, issuer.claims())
+			token.Header["kid"] = testKid
+			signed, _ := token.SignedString(other)
+			return signed
+		}, "nonce-1"},
+		{"algoritmo HS256", func() string {
+			token := jwt.NewWithClaims(jwt.SigningMethodHS256, issuer.claims())
+			token.Header["kid"] = testKid
+			signed, _ := token.SignedString([]byte("segredo"))
+			return signed
+		}, "nonce-1"},
+		{"algoritmo none", func() string {
+			token := jwt.NewWithClaims(jwt.SigningMethodNone, issuer.claims())
+			token.Header["kid"] = testKid
+			signed, _ := token.SignedString(jwt.UnsafeAllowNoneSignatureType)
+			return signed
+		}, "nonce-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := provider.Verify(context.Background(), tt.token(), tt.nonce); err == nil {
+				t.Error("token aceito, esperado erro")
+			}
+		})
+	}
+}
+
+// Provedores sem suporte a nonce (OIDC_<NOME>_REQUIRE_NONCE=false) aceitam
+// tokens sem ele, mas um nonce informado ainda precisa corresponder.
+func TestProviderVerifyOptionalNonce(t *testing.T) {
+	issuer := newStubIssuer(t)
+	provider := NewProvider("teste", []string{issuer.server.URL}, []string{testClientID}, "", false)
+
+	claims := issuer.claims()
+	delete(claims, "nonce")
+	if _, err := provider.Verify(context.Background(), issuer.sign(t, claims, testKid), ""); err != nil {
+		t.Errorf("token sem nonce recusado: %v", err)
+	}
+	if _, err := provider.Verify(context.Background(), issuer.sign(t, claims, testKid), "nonce-1"); err == nil {
+		t.Error("nonce divergente aceito")
+	}
+}
+
+// A Apple envia email_verified como string.
+func TestProviderVerifyEmailVerifiedAsString(t *testing.T) {
+	issuer := newStubIssuer(t)
+	provider := NewProvider("teste", []string{issuer.server.URL}, []string{testClientID}, "", true)
+
+	for value, want := range map[interface{}]bool{"true": true, "false": false, false: false} {
+		claims := issuer.claims()
+		claims["email_verified"] = value
+		identity, err := provider.Verify(context.Background(), issuer.sign(t, claims, testKid), "nonce-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if identity.EmailVerified != want {
+			t.Errorf("email_verified %#v: EmailVerified = %v, esperado %v", value, identity.EmailVerified, want)
+		}
+	}
+}
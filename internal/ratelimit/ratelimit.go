@@ -0,0 +1,65 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Limiter limita quantas vezes cada chave (e-mail, IP...) pode agir dentro de
+// uma janela fixa. Os contadores ficam em memória, por instância do serviço.
+type Limiter struct {
+	mu        sync.Mutex
+	limit     int
+	window    time.Duration
+	hits      map[string]*counter
+	lastSweep time.Time
+}
+
+type counter struct {
+	count   int
+	resetAt time.Time
+}
+
+// New cria um limitador que aceita até limit ações por chave a cada window.
+func New(limit int, window time.Duration) *Limiter {
+	return &Limiter{
+		limit:  limit,
+		window: window,
+		hits:   map[string]*counter{},
+	}
+}
+
+// Allow registra uma ação para a chave e informa se ela ainda está dentro do
+// limite da janela atual.
+func (l *Limiter) Allow(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.sweep(now)
+
+	entry, ok := l.hits[key]
+	if !ok || !now.Before(entry.resetAt) {
+		entry = &counter{resetAt: now.Add(l.window)}
+		l.hits[key] = entry
+	}
+	if entry.count >= l.limit {
+		return false
+	}
+	entry.count++
+	return true
+}
+
+// sweep descarta, no máximo uma vez por janela, as chaves cuja janela já
+// terminou.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.window {
+		return
+	}
+	for key, entry := range l.hits {
+		if !now.Before(entry.resetAt) {
+			delete(l.hits, key)
+		}
+	}
+	l.lastSweep = now
+}
@@ -0,0 +1,35 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLimiterAllow(t *testing.T) {
+	l := New(2, time.Hour)
+
+	if !l.Allow("a") || !l.Allow("a") {
+		t.Fatal("ações dentro do limite recusadas")
+	}
+	if l.Allow("a") {
+		t.Error("ação acima do limite aceita")
+	}
+	if !l.Allow("b") {
+		t.Error("limite de uma chave afetou outra")
+	}
+}
+
+func TestLimiterWindowReset(t *testing.T) {
+	l := New(1, 20*time.Millisecond)
+
+	if !l.Allow("a") {
+		t.Fatal("primeira ação recusada")
+	}
+	if l.Allow("a") {
+		t.Fatal("ação acima do limite aceita")
+	}
+	time.Sleep(30 * time.Millisecond)
+	if !l.Allow("a") {
+		t.Error("limite não foi renovado após a janela")
+	}
+}
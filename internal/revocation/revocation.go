@@ -0,0 +1,262 @@
+package revocation
+
+import (
+	"log"
+	"sync"
+	"time"
+	"user-service/internal/database"
+	"user-service/internal/user/models"
+	"user-service/internal/utils"
+
+	"gorm.io/gorm/clause"
+)
+
+// Lista de revogação de tokens de acesso. O banco é a fonte da verdade; a
+// memória guarda as revogações conhecidas e, por REVOCATION_CACHE_TTL (padrão
+// 30s), o resultado negativo de cada consulta. Revogações feitas por outra
+// instância do serviço são percebidas em no máximo esse intervalo.
+var (
+	mu sync.RWMutex
+	// jti -> expiração do token revogado
+	revokedTokens = map[string]time.Time{}
+	// user_id -> instante a partir do qual os tokens voltam a ser válidos
+	userCutoffs = map[string]time.Time{}
+	// sid -> instante até o qual tokens da sessão revogada ainda podem circular
+	revokedSessions = map[string]time.Time{}
+	// jti|sid|user_id -> instante da última consulta ao banco
+	checkedAt = map[string]time.Time{}
+)
+
+func cacheTTL() time.Duration {
+	return utils.GetEnvDuration("REVOCATION_CACHE_TTL", 30*time.Second)
+}
+
+// Init carrega as revogações ativas para a memória e inicia a limpeza
+// periódica dos registros expirados. Chamar após database.ConnectDatabase.
+func Init() error {
+	var tokens []models.RevokedToken
+	if err := database.DB.Where("expires_at > ?", time.Now()).Find(&tokens).Error; err != nil {
+		return err
+	}
+
+	var users []models.UserTokenRevocation
+	if err := database.DB.Find(&users).Error; err != nil {
+		return err
+	}
+
+	var sessions []models.Session
+	if err := database.DB.Where("revoked_at > ?", time.Now().Add(-utils.AccessTokenTTL())).
+		Find(&sessions).Error; err != nil {
+		return err
+	}
+
+	mu.Lock()
+	for _, t := range tokens {
+		revokedTokens[t.JTI] = t.ExpiresAt
+	}
+	for _, u := range users {
+		userCutoffs[u.UserID] = u.RevokedAt
+	}
+	for _, s := range sessions {
+		revokedSessions[s.ID] = s.RevokedAt.Add(utils.AccessTokenTTL())
+	}
+	mu.Unlock()
+
+	go janitor(10 * time.Minute)
+	return nil
+}
+
+// IsRevoked informa se o token foi revogado individualmente (jti), junto com
+// sua sessão (sid) ou por uma revogação geral do usuário.
+func IsRevoked(claims *utils.Claims) (bool, error) {
+	var issuedAt time.Time
+	if claims.IssuedAt != nil {
+		issuedAt = claims.IssuedAt.Time
+	}
+	jti, sid, userID := claims.ID, claims.SessionID, claims.UserID
+	key := jti + "|" + sid + "|" + userID
+
+	mu.RLock()
+	revoked := isRevokedLocked(jti, sid, userID, issuedAt)
+	last, checked := checkedAt[key]
+	mu.RUnlock()
+
+	if revoked || (checked && time.Since(last) < cacheTTL()) {
+		return revoked, nil
+	}
+
+	// Consulta o banco para enxergar revogações feitas por outras instâncias
+	if jti != "" {
+		var token models.RevokedToken
+		result := database.DB.Where("jti = ?", jti).Limit(1).Find(&token)
+		if result.Error != nil {
+			return false, result.Error
+		}
+		if result.RowsAffected > 0 {
+			mu.Lock()
+			revokedTokens[token.JTI] = token.ExpiresAt
+			mu.Unlock()
+		}
+	}
+
+	if sid != "" {
+		var session models.Session
+		result := database.DB.Where("id = ? AND revoked_at IS NOT NULL", sid).Limit(1).Find(&session)
+		if result.Error != nil {
+			return false, result.Error
+		}
+		if result.RowsAffected > 0 {
+			mu.Lock()
+			revokedSessions[session.ID] = session.RevokedAt.Add(utils.AccessTokenTTL())
+			mu.Unlock()
+		}
+	}
+
+	var cutoff models.UserTokenRevocation
+	result := database.DB.Where("user_id = ?", userID).Limit(1).Find(&cutoff)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if result.RowsAffected > 0 {
+		userCutoffs[cutoff.UserID] = cutoff.RevokedAt
+	}
+	checkedAt[key] = time.Now()
+	return isRevokedLocked(jti, sid, userID, issuedAt), nil
+}
+
+func isRevokedLocked(jti, sid, userID string, issuedAt time.Time) bool {
+	if jti != "" {
+		if _, ok := revokedTokens[jti]; ok {
+			return true
+		}
+	}
+	if sid != "" {
+		if _, ok := revokedSessions[sid]; ok {
+			return true
+		}
+	}
+	// O iat do JWT tem precisão de segundos, por isso o corte é truncado
+	if cutoff, ok := userCutoffs[userID]; ok && issuedAt.Before(cutoff) {
+		return true
+	}
+	return false
+}
+
+// RevokeToken invalida um único token de acesso até sua expiração.
+func RevokeToken(jti, userID string, expiresAt time.Time, reason string) error {
+	if jti == "" {
+		return nil
+	}
+
+	token := models.RevokedToken{
+		JTI:       jti,
+		UserID:    userID,
+		Reason:    reason,
+		ExpiresAt: expiresAt,
+	}
+	if err := database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&token).Error; err != nil {
+		return err
+	}
+
+	mu.Lock()
+	revokedTokens[jti] = expiresAt
+	mu.Unlock()
+	return nil
+}
+
+// RevokeUser invalida todos os tokens de acesso já emitidos para o usuário e
+// revoga seus refresh tokens ativos.
+func RevokeUser(userID, reason string) error {
+	cutoff := time.Now().Truncate(time.Second)
+
+	revocation := models.UserTokenRevocation{
+		UserID:    userID,
+		Reason:    reason,
+		RevokedAt: cutoff,
+	}
+	if err := database.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"reason", "revoked_at"}),
+	}).Create(&revocation).Error; err != nil {
+		return err
+	}
+
+	if err := database.DB.Model(&models.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error; err != nil {
+		return err
+	}
+
+	if err := database.DB.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error; err != nil {
+		return err
+	}
+
+	mu.Lock()
+	userCutoffs[userID] = cutoff
+	mu.Unlock()
+	return nil
+}
+
+// RevokeSession encerra uma sessão: seus refresh tokens deixam de ser aceitos
+// e os tokens de acesso com o mesmo sid passam a ser rejeitados.
+func RevokeSession(sessionID string) error {
+	if sessionID == "" {
+		return nil
+	}
+
+	now := time.Now()
+	if err := database.DB.Model(&models.Session{}).
+		Where("id = ? AND revoked_at IS NULL", sessionID).
+		Update("revoked_at", now).Error; err != nil {
+		return err
+	}
+
+	if err := database.DB.Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", sessionID).
+		Update("revoked_at", now).Error; err != nil {
+		return err
+	}
+
+	mu.Lock()
+	revokedSessions[sessionID] = now.Add(utils.AccessTokenTTL())
+	mu.Unlock()
+	return nil
+}
+
+// janitor remove periodicamente os tokens revogados já expirados, que não
+// precisam mais constar na lista.
+func janitor(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		now := time.Now()
+
+		if err := database.DB.Where("expires_at <= ?", now).Delete(&models.RevokedToken{}).Error; err != nil {
+			log.Println("⚠️ Erro ao limpar tokens revogados:", err)
+		}
+
+		mu.Lock()
+		for jti, exp := range revokedTokens {
+			if exp.Before(now) {
+				delete(revokedTokens, jti)
+			}
+		}
+		for sid, exp := range revokedSessions {
+			if exp.Before(now) {
+				delete(revokedSessions, sid)
+			}
+		}
+		for key, last := range checkedAt {
+			if now.Sub(last) > cacheTTL() {
+				delete(checkedAt, key)
+			}
+		}
+		mu.Unlock()
+	}
+}
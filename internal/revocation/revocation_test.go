@@ -0,0 +1,119 @@
+package revocation
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+	"user-service/internal/database"
+	"user-service/internal/testutil"
+	"user-service/internal/user/models"
+	"user-service/internal/utils"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+func TestMain(m *testing.M) {
+	cleanup, err := testutil.Setup()
+	if err != nil {
+		fmt.Println("❌ Erro ao preparar os testes:", err)
+		os.Exit(1)
+	}
+	code := m.Run()
+	cleanup()
+	os.Exit(code)
+}
+
+func testClaims(jti, userID, sessionID string, issuedAt time.Time) *utils.Claims {
+	return &utils.Claims{
+		UserID:           userID,
+		SessionID:        sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{ID: jti, IssuedAt: jwt.NewNumericDate(issuedAt)},
+	}
+}
+
+func TestRevokeToken(t *testing.T) {
+	jti := uuid.NewString()
+	if err := RevokeToken(jti, "u1", time.Now().Add(time.Hour), "logout"); err != nil {
+		t.Fatal(err)
+	}
+
+	if revoked, err := IsRevoked(testClaims(jti, "u1", "", time.Now())); err != nil || !revoked {
+		t.Errorf("token revogado aceito (err = %v)", err)
+	}
+	if revoked, err := IsRevoked(testClaims(uuid.NewString(), "u1", "", time.Now())); err != nil || revoked {
+		t.Errorf("outro token recusado (err = %v)", err)
+	}
+}
+
+// A revogação geral vale para os tokens emitidos antes dela, não para os
+// emitidos depois, e também revoga os refresh tokens ativos.
+func TestRevokeUser(t *testing.T) {
+	userID := uuid.NewString()
+	refresh := models.RefreshToken{UserID: userID, FamilyID: uuid.NewString(), TokenHash: uuid.NewString(), ExpiresAt: time.Now().Add(time.Hour)}
+	if err := database.DB.Create(&refresh).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := RevokeUser(userID, "password_changed"); err != nil {
+		t.Fatal(err)
+	}
+
+	if revoked, err := IsRevoked(testClaims(uuid.NewString(), userID, "", time.Now().Add(-time.Minute))); err != nil || !revoked {
+		t.Errorf("token anterior à revogação aceito (err = %v)", err)
+	}
+	if revoked, err := IsRevoked(testClaims(uuid.NewString(), userID, "", time.Now().Add(time.Second))); err != nil || revoked {
+		t.Errorf("token posterior à revogação recusado (err = %v)", err)
+	}
+
+	if err := database.DB.First(&refresh, "id = ?", refresh.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if refresh.RevokedAt == nil {
+		t.Error("refresh token continua ativo")
+	}
+}
+
+// Uma revogação gravada por outra instância é lida do banco.
+func TestIsRevokedReadsDatabase(t *testing.T) {
+	jti := uuid.NewString()
+	if err := database.DB.Create(&models.RevokedToken{JTI: jti, UserID: "u2", ExpiresAt: time.Now().Add(time.Hour)}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if revoked, err := IsRevoked(testClaims(jti, "u2", "", time.Now())); err != nil || !revoked {
+		t.Errorf("revogação do banco ignorada (err = %v)", err)
+	}
+}
+
+// Revogar a sessão recusa os tokens de acesso vinculados a ela e revoga seus
+// refresh tokens, sem afetar outras sessões do mesmo usuário.
+func TestRevokeSession(t *testing.T) {
+	userID := uuid.NewString()
+	session := models.Session{ID: uuid.NewString(), UserID: userID, ExpiresAt: time.Now().Add(time.Hour)}
+	if err := database.DB.Create(&session).Error; err != nil {
+		t.Fatal(err)
+	}
+	refresh := models.RefreshToken{UserID: userID, FamilyID: session.ID, TokenHash: uuid.NewString(), ExpiresAt: time.Now().Add(time.Hour)}
+	if err := database.DB.Create(&refresh).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := RevokeSession(session.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if revoked, err := IsRevoked(testClaims(uuid.NewString(), userID, session.ID, time.Now())); err != nil || !revoked {
+		t.Errorf("token da sessão revogada aceito (err = %v)", err)
+	}
+	if revoked, err := IsRevoked(testClaims(uuid.NewString(), userID, uuid.NewString(), time.Now())); err != nil || revoked {
+		t.Errorf("token de outra sessão recusado (err = %v)", err)
+	}
+
+	if err := database.DB.First(&refresh, "id = ?", refresh.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if refresh.RevokedAt == nil {
+		t.Error("refresh token da sessão continua ativo")
+	}
+}
@@ -0,0 +1,80 @@
+package s3helper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// ErrPrivateBucketNotConfigured indica que AWS_PRIVATE_BUCKET_NAME não foi
+// definido. Não há fallback para o bucket principal, cujos arquivos são
+// servidos por URL pública.
+var ErrPrivateBucketNotConfigured = errors.New("bucket privado não configurado (AWS_PRIVATE_BUCKET_NAME)")
+
+// privateBucket retorna o bucket de arquivos privados (AWS_PRIVATE_BUCKET_NAME).
+func privateBucket() (string, error) {
+	bucket := os.Getenv("AWS_PRIVATE_BUCKET_NAME")
+	if bucket == "" {
+		return "", ErrPrivateBucketNotConfigured
+	}
+	if bucket == bucketName {
+		return "", fmt.Errorf("AWS_PRIVATE_BUCKET_NAME não pode ser o bucket público %q", bucket)
+	}
+	return bucket, nil
+}
+
+// UploadPrivateFile envia um arquivo privado (sem URL pública), criptografado
+// no S3. O acesso é feito apenas por URLs pré-assinadas.
+func UploadPrivateFile(body io.Reader, key, contentType string) error {
+	bucket, err := privateBucket()
+	if err != nil {
+		return err
+	}
+	_, err = s3Client.PutObject(context.TODO(), &s3.PutObjectInput{
+		Bucket:               aws.String(bucket),
+		Key:                  aws.String(key),
+		Body:                 body,
+		ContentType:          aws.String(contentType),
+		ServerSideEncryption: types.ServerSideEncryptionAes256,
+	})
+	if err != nil {
+		return fmt.Errorf("❌ Falha ao fazer upload privado para o S3: %v", err)
+	}
+	return nil
+}
+
+// PresignGetURL gera uma URL temporária de download de um arquivo privado.
+func PresignGetURL(key string, ttl time.Duration) (string, error) {
+	bucket, err := privateBucket()
+	if err != nil {
+		return "", err
+	}
+	presigner := s3.NewPresignClient(s3Client)
+	req, err := presigner.PresignGetObject(context.TODO(), &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}, s3.WithPresignExpires(ttl))
+	if err != nil {
+		return "", err
+	}
+	return req.URL, nil
+}
+
+func DeletePrivateFile(key string) error {
+	bucket, err := privateBucket()
+	if err != nil {
+		return err
+	}
+	_, err = s3Client.DeleteObject(context.TODO(), &s3.DeleteObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	return err
+}
@@ -56,6 +56,10 @@ func InitS3Helper() error {
 	s3Client = s3.NewFromConfig(cfg)
 	log.Println("✅ Cliente S3 configurado com sucesso")
 
+	if _, err := privateBucket(); err != nil {
+		log.Println("⚠️ Envio de documentos desativado:", err)
+	}
+
 	return nil
 }
 
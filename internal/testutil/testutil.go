@@ -0,0 +1,131 @@
+// Package testutil prepara o ambiente compartilhado pelos testes: banco
+// SQLite temporário em database.DB, com as mesmas migrações do serviço, chave
+// JWT de teste, uma API de e-mail local que aceita todos os envios e um S3
+// local em memória.
+package testutil
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"user-service/internal/database"
+	"user-service/internal/s3helper"
+	"user-service/internal/utils"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Variáveis de ambiente usadas pelos testes; valores já definidos no
+// ambiente não são sobrescritos.
+var testEnv = map[string]string{
+	"JWT_SECRET": "segredo-de-teste-com-pelo-menos-32-bytes",
+	// Envios em segundo plano não ficam repetindo após o fim do teste
+	"EMAIL_MAX_ATTEMPTS": "1",
+	// Credenciais fictícias: os arquivos vão para o S3 local
+	"AWS_BUCKET_NAME":         "bucket-publico-de-teste",
+	"AWS_PRIVATE_BUCKET_NAME": "bucket-privado-de-teste",
+	"AWS_REGION":              "us-east-1",
+	"AWS_ACCESS_KEY_ID":       "chave-de-teste",
+	"AWS_SECRET_ACCESS_KEY":   "segredo-de-teste",
+}
+
+var (
+	s3Mu      sync.Mutex
+	s3Objects = map[string][]byte{}
+)
+
+// HasS3Object informa se o S3 local guarda o objeto bucket/key.
+func HasS3Object(bucket, key string) bool {
+	s3Mu.Lock()
+	defer s3Mu.Unlock()
+	_, ok := s3Objects["/"+bucket+"/"+key]
+	return ok
+}
+
+// s3Handler atende PutObject, GetObject e DeleteObject em endereçamento por
+// caminho (/bucket/key), o usado pelo SDK com um endpoint em IP.
+func s3Handler(w http.ResponseWriter, r *http.Request) {
+	s3Mu.Lock()
+	defer s3Mu.Unlock()
+
+	switch r.Method {
+	case http.MethodPut:
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s3Objects[r.URL.Path] = body
+	case http.MethodGet:
+		body, ok := s3Objects[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(body)
+	case http.MethodDelete:
+		delete(s3Objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// Setup configura o ambiente e retorna a função que o desfaz. Chamar em
+// TestMain, antes de m.Run.
+func Setup() (func(), error) {
+	for key, value := range testEnv {
+		if _, ok := os.LookupEnv(key); !ok {
+			os.Setenv(key, value)
+		}
+	}
+
+	// Nenhum e-mail sai do processo durante os testes
+	emailAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	os.Setenv("EMAIL_API_URL", emailAPI.URL)
+
+	s3API := httptest.NewServer(http.HandlerFunc(s3Handler))
+	os.Setenv("AWS_ENDPOINT_URL_S3", s3API.URL)
+
+	dir, err := os.MkdirTemp("", "user-service-test")
+	if err != nil {
+		emailAPI.Close()
+		s3API.Close()
+		return nil, err
+	}
+	cleanup := func() {
+		emailAPI.Close()
+		s3API.Close()
+		os.RemoveAll(dir)
+	}
+
+	// Transações imediatas esperam pelo lock de escrita (busy_timeout) em vez
+	// de falhar quando outra conexão grava entre a leitura e a escrita
+	dsn := filepath.Join(dir, "test.db") + "?_txlock=immediate&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+	database.DB, err = gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent), TranslateError: true})
+	if err != nil {
+		cleanup()
+		return nil, err
+	}
+	if err := database.Migrate(); err != nil {
+		cleanup()
+		return nil, err
+	}
+
+	if err := utils.InitKeys(); err != nil {
+		cleanup()
+		return nil, err
+	}
+	if err := s3helper.InitS3Helper(); err != nil {
+		cleanup()
+		return nil, err
+	}
+	return cleanup, nil
+}
@@ -0,0 +1,82 @@
+package totp
+
+import (
+	"testing"
+	"time"
+)
+
+// Segredo dos vetores SHA1 do RFC 6238 (apêndice B): "12345678901234567890"
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// Vetores do RFC 6238 com 8 dígitos, reduzidos aos 6 últimos
+var rfcVectors = []struct {
+	unix int64
+	code string
+}{
+	{59, "287082"},
+	{1111111109, "081804"},
+	{1111111111, "050471"},
+	{1234567890, "005924"},
+	{2000000000, "279037"},
+	{20000000000, "353130"},
+}
+
+func TestCodeRFC6238(t *testing.T) {
+	for _, v := range rfcVectors {
+		code, err := Code(rfcSecret, Step(time.Unix(v.unix, 0)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if code != v.code {
+			t.Errorf("T=%d: código = %s, esperado %s", v.unix, code, v.code)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := Step(now)
+
+	tests := []struct {
+		name   string
+		at     time.Time
+		code   string
+		skew   int
+		want   bool
+		wantAt int64
+	}{
+		{"código atual", now, "050471", 1, true, current},
+		{"com espaços", now, " 050 471 ", 1, true, current},
+		{"passo anterior dentro da tolerância", now.Add(Period * time.Second), "050471", 1, true, current},
+		{"passo anterior sem tolerância", now.Add(Period * time.Second), "050471", 0, false, 0},
+		{"fora da tolerância", now.Add(2 * Period * time.Second), "050471", 1, false, 0},
+		{"código errado", now, "123456", 1, false, 0},
+		{"tamanho errado", now, "50471", 1, false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := Validate(rfcSecret, tt.code, tt.at, tt.skew)
+			if ok != tt.want || step != tt.wantAt {
+				t.Errorf("Validate = (%d, %v), esperado (%d, %v)", step, ok, tt.wantAt, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateGeneratedSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	code, err := Code(secret, Step(now))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := Validate(secret, code, now, 1); !ok {
+		t.Error("código do próprio segredo recusado")
+	}
+	if _, ok := Validate("segredo inválido!", code, now, 1); ok {
+		t.Error("segredo inválido aceito")
+	}
+}
@@ -0,0 +1,100 @@
+package user
+
+import (
+	"net/http"
+	"testing"
+	"user-service/internal/database"
+	"user-service/internal/user/models"
+)
+
+// adminRoute descreve uma rota administrativa; setup prepara os registros
+// necessários para a chamada bem-sucedida e retorna o caminho e o corpo.
+type adminRoute struct {
+	method string
+	path   string
+	setup  func(t *testing.T) (string, string)
+	want   int
+}
+
+// fixedRoute é o setup de rotas que não dependem de registros prévios.
+func fixedRoute(path, body string) func(t *testing.T) (string, string) {
+	return func(t *testing.T) (string, string) { return path, body }
+}
+
+// testAdminOnly confere que cada rota exige token (401), recusa clientes e
+// instaladores (403) e atende administradores.
+func testAdminOnly(t *testing.T, routes map[string]adminRoute) {
+	t.Helper()
+	r := newTestRouter()
+
+	t.Run("sem token", func(t *testing.T) {
+		for name, route := range routes {
+			if w := doRequest(r, route.method, route.path, "", ""); w.Code != http.StatusUnauthorized {
+				t.Errorf("%s: status = %d, esperado %d: %s", name, w.Code, http.StatusUnauthorized, w.Body)
+			}
+		}
+	})
+
+	for _, role := range []string{models.RoleCliente, models.RoleInstalador} {
+		token := bearerToken(t, createTestUser(t, role, models.StatusApproved))
+		t.Run(role, func(t *testing.T) {
+			for name, route := range routes {
+				if w := doRequest(r, route.method, route.path, token, ""); w.Code != http.StatusForbidden {
+					t.Errorf("%s: status = %d, esperado %d: %s", name, w.Code, http.StatusForbidden, w.Body)
+				}
+			}
+		})
+	}
+
+	token := bearerToken(t, createTestUser(t, models.RoleAdmin, models.StatusApproved))
+	t.Run(models.RoleAdmin, func(t *testing.T) {
+		for name, route := range routes {
+			path, body := route.setup(t)
+			if w := doRequest(r, route.method, path, token, body); w.Code != route.want {
+				t.Errorf("%s: status = %d, esperado %d: %s", name, w.Code, route.want, w.Body)
+			}
+		}
+	})
+}
+
+func TestAdminRoutes(t *testing.T) {
+	testAdminOnly(t, map[string]adminRoute{
+		"listar usuários":               {http.MethodGet, "/user/list", fixedRoute("/user/list", ""), http.StatusOK},
+		"listar instaladores pendentes": {http.MethodGet, "/user/installers/pending", fixedRoute("/user/installers/pending", ""), http.StatusOK},
+		"aprovar": {http.MethodPatch, "/user/x/authorize", func(t *testing.T) (string, string) {
+			installer := createTestUser(t, models.RoleInstalador, models.StatusPending)
+			approveRequiredDocuments(t, installer.ID)
+			return "/user/" + installer.ID + "/authorize", ""
+		}, http.StatusOK},
+		"personificar": {http.MethodPost, "/user/x/impersonate", func(t *testing.T) (string, string) {
+			return "/user/" + createTestUser(t, models.RoleCliente, models.StatusApproved).ID + "/impersonate", `{"reason":"chamado de suporte"}`
+		}, http.StatusOK},
+		"auditoria": {http.MethodGet, "/user/audit-logs", fixedRoute("/user/audit-logs", ""), http.StatusOK},
+	})
+}
+
+func TestAuthorizeUserApprovesInstaller(t *testing.T) {
+	r := newTestRouter()
+	admin := createTestUser(t, models.RoleAdmin, models.StatusApproved)
+	installer := createTestUser(t, models.RoleInstalador, models.StatusPending)
+	approveRequiredDocuments(t, installer.ID)
+
+	if w := doRequest(r, http.MethodPatch, "/user/"+installer.ID+"/authorize", bearerToken(t, admin), ""); w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body)
+	}
+	if err := database.DB.First(&installer, "id = ?", installer.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if installer.Status != models.StatusApproved || !installer.Authorized {
+		t.Errorf("status = %q, authorized = %v; esperado aprovado", installer.Status, installer.Authorized)
+	}
+}
+
+// O cadastro público não aceita o papel de administrador.
+func TestRegisterRejectsAdminRole(t *testing.T) {
+	r := newTestRouter()
+	body := `{"name":"Intruso","email":"intruso@example.com","password":"` + testPassword + `","role":"admin"}`
+	if w := doRequest(r, http.MethodPost, "/user/register", "", body); w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, esperado %d: %s", w.Code, http.StatusBadRequest, w.Body)
+	}
+}
@@ -0,0 +1,117 @@
+package user
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"time"
+	"user-service/internal/database"
+	"user-service/internal/user/models"
+	"user-service/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+func isValidScope(scope string) bool {
+	for _, s := range models.APIScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// newAPIKey gera uma chave no formato ehk_<prefixo>_<segredo>.
+func newAPIKey() (key string, prefix string, err error) {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	prefix = "ehk_" + hex.EncodeToString(b)
+
+	secret, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return "", "", err
+	}
+	return prefix + "_" + secret, prefix, nil
+}
+
+// CreateAPIKey cria uma chave de API para um serviço interno. O valor da
+// chave só é exibido nesta resposta.
+func CreateAPIKey(c *gin.Context) {
+	var body struct {
+		Name      string     `json:"name"`
+		Scopes    []string   `json:"scopes"`
+		ExpiresAt *time.Time `json:"expires_at"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON"})
+		return
+	}
+
+	if body.Name == "" || len(body.Scopes) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name e scopes são obrigatórios"})
+		return
+	}
+	for _, scope := range body.Scopes {
+		if !isValidScope(scope) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Escopo inválido: " + scope, "valid_scopes": models.APIScopes})
+			return
+		}
+	}
+	if body.ExpiresAt != nil && body.ExpiresAt.Before(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "expires_at deve estar no futuro"})
+		return
+	}
+
+	raw, prefix, err := newAPIKey()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao gerar chave de API"})
+		return
+	}
+
+	key := models.APIKey{
+		Name:      body.Name,
+		Prefix:    prefix,
+		KeyHash:   utils.HashToken(raw),
+		Scopes:    body.Scopes,
+		ExpiresAt: body.ExpiresAt,
+		CreatedBy: c.GetString("user_id"),
+	}
+	if err := database.DB.Create(&key).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao salvar chave de API"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"api_key": key,
+		"key":     raw,
+		"message": "Guarde a chave agora; ela não será exibida novamente",
+	})
+}
+
+func ListAPIKeys(c *gin.Context) {
+	var keys []models.APIKey
+	if err := database.DB.Order("created_at DESC").Find(&keys).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao listar chaves de API"})
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+func RevokeAPIKey(c *gin.Context) {
+	result := database.DB.Model(&models.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", c.Param("key_id")).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao revogar chave de API"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Chave de API não encontrada ou já revogada"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Chave de API revogada com sucesso"})
+}
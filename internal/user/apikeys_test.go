@@ -0,0 +1,135 @@
+package user
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"user-service/internal/database"
+	"user-service/internal/user/models"
+	"user-service/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// createTestAPIKey grava uma chave com os escopos informados; modify ajusta
+// o registro antes de salvá-lo. Retorna o valor da chave.
+func createTestAPIKey(t *testing.T, scopes []string, modify func(key *models.APIKey)) string {
+	t.Helper()
+	raw, prefix, err := newAPIKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := models.APIKey{Name: "teste", Prefix: prefix, KeyHash: utils.HashToken(raw), Scopes: scopes}
+	if modify != nil {
+		modify(&key)
+	}
+	if err := database.DB.Create(&key).Error; err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+func doAPIKeyRequest(r *gin.Engine, method, path, apiKey, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		req.Header.Set("X-API-Key", apiKey)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestAPIKeyAdminRoutes(t *testing.T) {
+	testAdminOnly(t, map[string]adminRoute{
+		"criar": {http.MethodPost, "/user/api-keys",
+			fixedRoute("/user/api-keys", `{"name":"pedidos","scopes":["`+models.ScopeInstallerStatsWrite+`"]}`), http.StatusCreated},
+		"listar": {http.MethodGet, "/user/api-keys", fixedRoute("/user/api-keys", ""), http.StatusOK},
+		"revogar": {http.MethodDelete, "/user/api-keys/x", func(t *testing.T) (string, string) {
+			raw := createTestAPIKey(t, []string{models.ScopeInstallerStatsWrite}, nil)
+			var key models.APIKey
+			if err := database.DB.First(&key, "key_hash = ?", utils.HashToken(raw)).Error; err != nil {
+				t.Fatal(err)
+			}
+			return "/user/api-keys/" + key.ID, ""
+		}, http.StatusOK},
+	})
+}
+
+func TestCreateAPIKeyRejectsUnknownScope(t *testing.T) {
+	r := newTestRouter()
+	admin := createTestUser(t, models.RoleAdmin, models.StatusApproved)
+	body := `{"name":"pedidos","scopes":["users:delete"]}`
+	if w := doRequest(r, http.MethodPost, "/user/api-keys", bearerToken(t, admin), body); w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, esperado %d: %s", w.Code, http.StatusBadRequest, w.Body)
+	}
+}
+
+// A chave criada pela API autentica a rota do escopo concedido.
+func TestCreatedAPIKeyAuthenticates(t *testing.T) {
+	r := newTestRouter()
+	admin := createTestUser(t, models.RoleAdmin, models.StatusApproved)
+	installer := createTestUser(t, models.RoleInstalador, models.StatusApproved)
+
+	w := doRequest(r, http.MethodPost, "/user/api-keys", bearerToken(t, admin), `{"name":"pedidos","scopes":["`+models.ScopeInstallerStatsWrite+`"]}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("criação: status = %d: %s", w.Code, w.Body)
+	}
+	var created struct {
+		Key string `json:"key"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil || !strings.HasPrefix(created.Key, "ehk_") {
+		t.Fatalf("resposta sem chave: %s", w.Body)
+	}
+
+	if w := doAPIKeyRequest(r, http.MethodPut, "/user/"+installer.ID+"/stats", created.Key, `{"total_services_accepted":3}`); w.Code != http.StatusOK {
+		t.Fatalf("stats: status = %d: %s", w.Code, w.Body)
+	}
+	if err := database.DB.First(&installer, "id = ?", installer.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if installer.TotalServicesAccepted != 3 {
+		t.Errorf("total_services_accepted = %d, esperado 3", installer.TotalServicesAccepted)
+	}
+}
+
+func TestAPIKeyMiddlewareScopes(t *testing.T) {
+	r := newTestRouter()
+	installer := createTestUser(t, models.RoleInstalador, models.StatusApproved)
+	path := "/user/" + installer.ID + "/stats"
+	body := `{"services_not_executed":1}`
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name string
+		key  string
+		want int
+	}{
+		{"sem chave", "", http.StatusUnauthorized},
+		{"chave desconhecida", "ehk_00000000_desconhecida", http.StatusUnauthorized},
+		{"sem o escopo", createTestAPIKey(t, []string{"outro:escopo"}, nil), http.StatusForbidden},
+		{"revogada", createTestAPIKey(t, []string{models.ScopeInstallerStatsWrite}, func(k *models.APIKey) { k.RevokedAt = &past }), http.StatusUnauthorized},
+		{"expirada", createTestAPIKey(t, []string{models.ScopeInstallerStatsWrite}, func(k *models.APIKey) { k.ExpiresAt = &past }), http.StatusUnauthorized},
+		{"com o escopo", createTestAPIKey(t, []string{models.ScopeInstallerStatsWrite}, nil), http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := doAPIKeyRequest(r, http.MethodPut, path, tt.key, body); w.Code != tt.want {
+				t.Errorf("status = %d, esperado %d: %s", w.Code, tt.want, w.Body)
+			}
+		})
+	}
+}
+
+// Um token de usuário, mesmo de administrador, não substitui a chave de API.
+func TestStatsRouteRejectsUserToken(t *testing.T) {
+	r := newTestRouter()
+	installer := createTestUser(t, models.RoleInstalador, models.StatusApproved)
+	admin := createTestUser(t, models.RoleAdmin, models.StatusApproved)
+	if w := doRequest(r, http.MethodPut, "/user/"+installer.ID+"/stats", bearerToken(t, admin), `{"services_not_executed":1}`); w.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, esperado %d", w.Code, http.StatusUnauthorized)
+	}
+}
@@ -0,0 +1,321 @@
+package user
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+	_ "time/tzdata"
+	"user-service/internal/database"
+	"user-service/internal/user/models"
+	"user-service/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const dateLayout = "2006-01-02"
+
+// availabilityLocation é o fuso usado para interpretar datas de agenda
+// (AVAILABILITY_TIMEZONE, padrão America/Sao_Paulo).
+func availabilityLocation() *time.Location {
+	name := os.Getenv("AVAILABILITY_TIMEZONE")
+	if name == "" {
+		name = "America/Sao_Paulo"
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		fmt.Println("⚠️ Fuso de agenda inválido, usando America/Sao_Paulo:", err)
+		loc, _ = time.LoadLocation("America/Sao_Paulo")
+	}
+	return loc
+}
+
+// parseAgendaDate valida uma data AAAA-MM-DD que não esteja no passado.
+func parseAgendaDate(value string) (time.Time, error) {
+	loc := availabilityLocation()
+	date, err := time.ParseInLocation(dateLayout, strings.TrimSpace(value), loc)
+	if err != nil {
+		return date, fmt.Errorf("data inválida: %q (use AAAA-MM-DD)", value)
+	}
+	today := time.Now().In(loc).Format(dateLayout)
+	if date.Format(dateLayout) < today {
+		return date, fmt.Errorf("data no passado: %s", value)
+	}
+	return date, nil
+}
+
+// DayAvailability resume a agenda de um instalador em um dia.
+type DayAvailability struct {
+	Date      string `json:"date"`
+	Available bool   `json:"available"`
+	Start     string `json:"start,omitempty"`
+	End       string `json:"end,omitempty"`
+	Capacity  int    `json:"capacity"`
+	Booked    int    `json:"booked"`
+	Blocked   bool   `json:"blocked"`
+}
+
+// availabilityOn calcula a disponibilidade dos instaladores na data.
+func availabilityOn(tx *gorm.DB, userIDs []string, date time.Time) (map[string]DayAvailability, error) {
+	days, err := availabilityBetween(tx, userIDs, date, date)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]DayAvailability, len(days))
+	for id, d := range days {
+		result[id] = d[0]
+	}
+	return result, nil
+}
+
+// availabilityBetween calcula a disponibilidade dos instaladores em cada dia
+// de from a to, com uma consulta por tabela para todo o intervalo. Quem está
+// em data bloqueada fica indisponível; os demais precisam trabalhar no dia da
+// semana e ter vaga na capacidade diária. Instaladores que nunca definiram
+// expediente atendem todos os dias, com a capacidade padrão
+// (AVAILABILITY_DEFAULT_DAILY_CAPACITY, padrão 3).
+func availabilityBetween(tx *gorm.DB, userIDs []string, from, to time.Time) (map[string][]DayAvailability, error) {
+	result := make(map[string][]DayAvailability, len(userIDs))
+	if len(userIDs) == 0 {
+		return result, nil
+	}
+	first, last := from.Format(dateLayout), to.Format(dateLayout)
+
+	var hours []models.WorkingHours
+	if err := tx.Where("user_id IN ?", userIDs).Find(&hours).Error; err != nil {
+		return nil, err
+	}
+	hoursByUser := map[string]map[time.Weekday]models.WorkingHours{}
+	for _, h := range hours {
+		if hoursByUser[h.UserID] == nil {
+			hoursByUser[h.UserID] = map[time.Weekday]models.WorkingHours{}
+		}
+		hoursByUser[h.UserID][time.Weekday(h.Weekday)] = h
+	}
+
+	var blocked []models.BlockedDate
+	if err := tx.Where("user_id IN ? AND date BETWEEN ? AND ?", userIDs, first, last).Find(&blocked).Error; err != nil {
+		return nil, err
+	}
+	isBlocked := map[string]bool{}
+	for _, b := range blocked {
+		isBlocked[b.UserID+"|"+b.Date] = true
+	}
+
+	var counts []struct {
+		UserID string
+		Date   string
+		Total  int
+	}
+	if err := tx.Model(&models.Booking{}).Select("user_id, date, COUNT(*) AS total").
+		Where("user_id IN ? AND date BETWEEN ? AND ? AND canceled_at IS NULL", userIDs, first, last).
+		Group("user_id, date").Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+	booked := map[string]int{}
+	for _, c := range counts {
+		booked[c.UserID+"|"+c.Date] = c.Total
+	}
+
+	defaultCapacity := utils.GetEnvInt("AVAILABILITY_DEFAULT_DAILY_CAPACITY", 3)
+	for _, id := range userIDs {
+		for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+			day := date.Format(dateLayout)
+			a := DayAvailability{
+				Date:    day,
+				Booked:  booked[id+"|"+day],
+				Blocked: isBlocked[id+"|"+day],
+			}
+			works := true
+			if weekly, configured := hoursByUser[id]; configured {
+				var h models.WorkingHours
+				h, works = weekly[date.Weekday()]
+				a.Start, a.End, a.Capacity = h.Start, h.End, h.Capacity
+			} else {
+				a.Capacity = defaultCapacity
+			}
+			a.Available = !a.Blocked && works && a.Booked < a.Capacity
+			result[id] = append(result[id], a)
+		}
+	}
+	return result, nil
+}
+
+// GetMyAvailability retorna o expediente semanal e as próximas datas
+// bloqueadas do instalador autenticado.
+func GetMyAvailability(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	var hours []models.WorkingHours
+	if err := database.DB.Where("user_id = ?", userID).Order("weekday").Find(&hours).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar agenda"})
+		return
+	}
+
+	var blocked []models.BlockedDate
+	today := time.Now().In(availabilityLocation()).Format(dateLayout)
+	if err := database.DB.Where("user_id = ? AND date >= ?", userID, today).Order("date").Find(&blocked).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar agenda"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"working_hours": hours,
+		"blocked_dates": blocked,
+	})
+}
+
+// UpdateWorkingHours substitui o expediente semanal do instalador autenticado.
+// Cada item tem weekday (0 = domingo), start e end (HH:MM) e capacity
+// (serviços por dia); dias omitidos são folga.
+func UpdateWorkingHours(c *gin.Context) {
+	var body struct {
+		Hours []models.WorkingHours `json:"hours"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON"})
+		return
+	}
+
+	maxCapacity := utils.GetEnvInt("AVAILABILITY_MAX_DAILY_CAPACITY", 20)
+	seen := map[int]bool{}
+	for _, h := range body.Hours {
+		start, errStart := time.Parse("15:04", h.Start)
+		end, errEnd := time.Parse("15:04", h.End)
+		switch {
+		case h.Weekday < 0 || h.Weekday > 6:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "weekday deve estar entre 0 (domingo) e 6 (sábado)"})
+			return
+		case seen[h.Weekday]:
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("weekday %d repetido", h.Weekday)})
+			return
+		case errStart != nil || errEnd != nil || !end.After(start):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "start e end devem estar no formato HH:MM, com end após start"})
+			return
+		case h.Capacity < 1 || h.Capacity > maxCapacity:
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("capacity deve estar entre 1 e %d", maxCapacity)})
+			return
+		}
+		seen[h.Weekday] = true
+	}
+
+	userID := c.GetString("user_id")
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.WorkingHours{}).Error; err != nil {
+			return err
+		}
+		for _, h := range body.Hours {
+			if err := tx.Create(&models.WorkingHours{
+				UserID:   userID,
+				Weekday:  h.Weekday,
+				Start:    h.Start,
+				End:      h.End,
+				Capacity: h.Capacity,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao salvar expediente"})
+		return
+	}
+
+	GetMyAvailability(c)
+}
+
+// BlockDate bloqueia uma data na agenda do instalador autenticado.
+func BlockDate(c *gin.Context) {
+	var body struct {
+		Date   string `json:"date"`
+		Reason string `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON"})
+		return
+	}
+
+	date, err := parseAgendaDate(body.Date)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	blocked := models.BlockedDate{
+		UserID: c.GetString("user_id"),
+		Date:   date.Format(dateLayout),
+		Reason: strings.TrimSpace(body.Reason),
+	}
+	if err := database.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"reason"}),
+	}).Create(&blocked).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao bloquear data"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, blocked)
+}
+
+// UnblockDate libera uma data bloqueada do instalador autenticado.
+func UnblockDate(c *gin.Context) {
+	result := database.DB.Where("user_id = ? AND date = ?", c.GetString("user_id"), c.Param("date")).
+		Delete(&models.BlockedDate{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao liberar data"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Data não bloqueada"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Data liberada com sucesso"})
+}
+
+// GetInstallerAvailability mostra a disponibilidade de um instalador dia a dia
+// entre from e to (padrão: os próximos 7 dias, no máximo 31).
+func GetInstallerAvailability(c *gin.Context) {
+	var installer models.User
+	if err := database.DB.Where("id = ? AND role = ? AND authorized = ?", c.Param("id"), models.RoleInstalador, true).
+		First(&installer).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Instalador não encontrado"})
+		return
+	}
+
+	loc := availabilityLocation()
+	now := time.Now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+
+	from, to := today, today.AddDate(0, 0, 6)
+	var err error
+	if value := c.Query("from"); value != "" {
+		if from, err = parseAgendaDate(value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		to = from.AddDate(0, 0, 6)
+	}
+	if value := c.Query("to"); value != "" {
+		if to, err = parseAgendaDate(value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if to.Before(from) || to.After(from.AddDate(0, 0, 30)) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Intervalo inválido (máximo de 31 dias)"})
+		return
+	}
+
+	days, err := availabilityBetween(database.DB, []string{installer.ID}, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao calcular disponibilidade"})
+		return
+	}
+
+	c.JSON(http.StatusOK, days[installer.ID])
+}
@@ -0,0 +1,210 @@
+package user
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+	"user-service/internal/database"
+	"user-service/internal/user/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// nextWeekday retorna a próxima data (a partir de amanhã) no dia da semana.
+func nextWeekday(weekday time.Weekday) time.Time {
+	date := time.Now().In(availabilityLocation()).AddDate(0, 0, 1)
+	for date.Weekday() != weekday {
+		date = date.AddDate(0, 0, 1)
+	}
+	return date
+}
+
+// bookService agenda um serviço do cliente com o instalador na data.
+func bookService(r *gin.Engine, key string, installer, client models.User, date time.Time) int {
+	body := `{"service_id":"pedido-` + uuid.NewString() + `","client_id":"` + client.ID + `","date":"` + date.Format(dateLayout) + `"}`
+	return doAPIKeyRequest(r, http.MethodPost, "/user/"+installer.ID+"/bookings", key, body).Code
+}
+
+func installerAvailability(t *testing.T, r *gin.Engine, installer models.User, query string) []DayAvailability {
+	t.Helper()
+	w := doRequest(r, http.MethodGet, "/user/"+installer.ID+"/availability?"+query, "", "")
+	var days []DayAvailability
+	if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &days) != nil {
+		t.Fatalf("disponibilidade: status = %d: %s", w.Code, w.Body)
+	}
+	return days
+}
+
+func TestUpdateWorkingHoursValidation(t *testing.T) {
+	r := newTestRouter()
+	token := bearerToken(t, createTestUser(t, models.RoleInstalador, models.StatusApproved))
+
+	cases := map[string]string{
+		"dia inválido":        `{"hours":[{"weekday":7,"start":"08:00","end":"17:00","capacity":2}]}`,
+		"dia repetido":        `{"hours":[{"weekday":1,"start":"08:00","end":"12:00","capacity":1},{"weekday":1,"start":"13:00","end":"17:00","capacity":1}]}`,
+		"fim antes do início": `{"hours":[{"weekday":1,"start":"17:00","end":"08:00","capacity":2}]}`,
+		"horário inválido":    `{"hours":[{"weekday":1,"start":"8h","end":"17:00","capacity":2}]}`,
+		"sem capacidade":      `{"hours":[{"weekday":1,"start":"08:00","end":"17:00","capacity":0}]}`,
+	}
+	for name, body := range cases {
+		if w := doRequest(r, http.MethodPut, "/user/me/availability/hours", token, body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, esperado %d", name, w.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+// Sem expediente definido, o instalador atende todo dia até a capacidade
+// padrão.
+func TestAvailabilityDefaultCapacity(t *testing.T) {
+	t.Setenv("AVAILABILITY_DEFAULT_DAILY_CAPACITY", "2")
+	r := newTestRouter()
+	installer := createTestUser(t, models.RoleInstalador, models.StatusApproved)
+	client := createTestUser(t, models.RoleCliente, models.StatusApproved)
+	key := createTestAPIKey(t, []string{models.ScopeInstallerBookingsWrite}, nil)
+	date := nextWeekday(time.Sunday)
+
+	for i := 0; i < 2; i++ {
+		if code := bookService(r, key, installer, client, date); code != http.StatusCreated {
+			t.Fatalf("agendamento %d: status = %d", i+1, code)
+		}
+	}
+	if code := bookService(r, key, installer, client, date); code != http.StatusConflict {
+		t.Errorf("acima da capacidade padrão: status = %d, esperado %d", code, http.StatusConflict)
+	}
+
+	day := installerAvailability(t, r, installer, "from="+date.Format(dateLayout)+"&to="+date.Format(dateLayout))[0]
+	if day.Available || day.Capacity != 2 || day.Booked != 2 {
+		t.Errorf("dia lotado: %+v", day)
+	}
+}
+
+// O intervalo considera o expediente, as datas bloqueadas e os agendamentos
+// de cada dia.
+func TestInstallerAvailabilityRange(t *testing.T) {
+	r := newTestRouter()
+	installer := createTestUser(t, models.RoleInstalador, models.StatusApproved)
+	token := bearerToken(t, installer)
+	client := createTestUser(t, models.RoleCliente, models.StatusApproved)
+	key := createTestAPIKey(t, []string{models.ScopeInstallerBookingsWrite}, nil)
+
+	if w := doRequest(r, http.MethodPut, "/user/me/availability/hours", token, `{"hours":[{"weekday":1,"start":"08:00","end":"17:00","capacity":1}]}`); w.Code != http.StatusOK {
+		t.Fatalf("expediente: status = %d: %s", w.Code, w.Body)
+	}
+	monday := nextWeekday(time.Monday)
+	booked, blocked := monday, monday.AddDate(0, 0, 7)
+	if w := doRequest(r, http.MethodPost, "/user/me/availability/blocked-dates", token, `{"date":"`+blocked.Format(dateLayout)+`","reason":"Férias"}`); w.Code != http.StatusCreated {
+		t.Fatalf("bloquear data: status = %d: %s", w.Code, w.Body)
+	}
+	if code := bookService(r, key, installer, client, booked); code != http.StatusCreated {
+		t.Fatalf("agendamento: status = %d", code)
+	}
+	if code := bookService(r, key, installer, client, monday.AddDate(0, 0, 1)); code != http.StatusConflict {
+		t.Errorf("agendamento na folga: status = %d, esperado %d", code, http.StatusConflict)
+	}
+
+	free := monday.AddDate(0, 0, 14)
+	days := installerAvailability(t, r, installer, "from="+monday.Format(dateLayout)+"&to="+free.Format(dateLayout))
+	if len(days) != 15 {
+		t.Fatalf("dias = %d, esperado 15", len(days))
+	}
+	for _, day := range days {
+		date, _ := time.ParseInLocation(dateLayout, day.Date, availabilityLocation())
+		want := date.Weekday() == time.Monday && day.Date == free.Format(dateLayout)
+		if day.Available != want {
+			t.Errorf("%s: disponível = %v, esperado %v (%+v)", day.Date, day.Available, want, day)
+		}
+	}
+	if days[0].Booked != 1 || !days[7].Blocked {
+		t.Errorf("agendado = %+v, bloqueado = %+v", days[0], days[7])
+	}
+
+	if w := doRequest(r, http.MethodGet, "/user/"+installer.ID+"/availability?from="+monday.Format(dateLayout)+"&to="+monday.AddDate(0, 0, 31).Format(dateLayout), "", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("intervalo acima de 31 dias: status = %d, esperado %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestCreateBookingRules(t *testing.T) {
+	r := newTestRouter()
+	installer := createTestUser(t, models.RoleInstalador, models.StatusApproved)
+	client := createTestUser(t, models.RoleCliente, models.StatusApproved)
+	key := createTestAPIKey(t, []string{models.ScopeInstallerBookingsWrite}, nil)
+	date := nextWeekday(time.Wednesday).Format(dateLayout)
+	path := "/user/" + installer.ID + "/bookings"
+
+	for _, status := range []string{models.StatusPending, models.StatusSuspended} {
+		other := createTestUser(t, models.RoleInstalador, status)
+		if code := bookService(r, key, other, client, nextWeekday(time.Wednesday)); code != http.StatusConflict {
+			t.Errorf("instalador %s: status = %d, esperado %d", status, code, http.StatusConflict)
+		}
+	}
+	for name, body := range map[string]string{
+		"sem client_id":        `{"service_id":"s1","date":"` + date + `"}`,
+		"cliente desconhecido": `{"service_id":"s1","client_id":"` + uuid.NewString() + `","date":"` + date + `"}`,
+		"data no passado":      `{"service_id":"s1","client_id":"` + client.ID + `","date":"2020-01-01"}`,
+	} {
+		if w := doAPIKeyRequest(r, http.MethodPost, path, key, body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, esperado %d", name, w.Code, http.StatusBadRequest)
+		}
+	}
+
+	serviceID := "pedido-" + uuid.NewString()
+	body := `{"service_id":"` + serviceID + `","client_id":"` + client.ID + `","date":"` + date + `"}`
+	if w := doAPIKeyRequest(r, http.MethodPost, path, key, body); w.Code != http.StatusCreated {
+		t.Fatalf("agendar: status = %d: %s", w.Code, w.Body)
+	}
+	if w := doAPIKeyRequest(r, http.MethodPost, path, key, body); w.Code != http.StatusConflict {
+		t.Errorf("serviço repetido: status = %d, esperado %d", w.Code, http.StatusConflict)
+	}
+
+	// Cancelado, o serviço pode ser reagendado; concluído, não é mais cancelado
+	if w := doAPIKeyRequest(r, http.MethodDelete, path+"/"+serviceID, key, ""); w.Code != http.StatusOK {
+		t.Fatalf("cancelar: status = %d: %s", w.Code, w.Body)
+	}
+	if w := doAPIKeyRequest(r, http.MethodPost, path, key, body); w.Code != http.StatusCreated {
+		t.Fatalf("reagendar: status = %d: %s", w.Code, w.Body)
+	}
+	if w := doAPIKeyRequest(r, http.MethodPost, path+"/"+serviceID+"/complete", key, `{"client_id":"`+client.ID+`"}`); w.Code != http.StatusOK {
+		t.Fatalf("concluir: status = %d: %s", w.Code, w.Body)
+	}
+	if w := doAPIKeyRequest(r, http.MethodDelete, path+"/"+serviceID, key, ""); w.Code != http.StatusNotFound {
+		t.Errorf("cancelar serviço concluído: status = %d, esperado %d", w.Code, http.StatusNotFound)
+	}
+}
+
+// available_on deixa de fora quem está de folga, bloqueado ou lotado no dia.
+func TestNearbyAvailableOn(t *testing.T) {
+	r := newTestRouter()
+	client := createTestUser(t, models.RoleCliente, models.StatusApproved)
+	key := createTestAPIKey(t, []string{models.ScopeInstallerBookingsWrite}, nil)
+	date := nextWeekday(time.Thursday)
+
+	free := createTestInstaller(t, -30.03, -51.23, nil)
+	blocked := createTestInstaller(t, -30.03, -51.23, nil)
+	full := createTestInstaller(t, -30.03, -51.23, nil)
+	off := createTestInstaller(t, -30.03, -51.23, nil)
+
+	if err := database.DB.Create(&models.BlockedDate{UserID: blocked.ID, Date: date.Format(dateLayout)}).Error; err != nil {
+		t.Fatal(err)
+	}
+	for _, hours := range []models.WorkingHours{
+		{UserID: full.ID, Weekday: int(time.Thursday), Start: "08:00", End: "17:00", Capacity: 1},
+		{UserID: off.ID, Weekday: int(time.Friday), Start: "08:00", End: "17:00", Capacity: 1},
+	} {
+		if err := database.DB.Create(&hours).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if code := bookService(r, key, full, client, date); code != http.StatusCreated {
+		t.Fatalf("agendamento: status = %d", code)
+	}
+
+	ids := nearbyIDs(t, r, "lat=-30.03&lng=-51.23&available_on="+date.Format(dateLayout))
+	if !ids[free.ID] || ids[blocked.ID] || ids[full.ID] || ids[off.ID] {
+		t.Errorf("livre = %v, bloqueado = %v, lotado = %v, folga = %v", ids[free.ID], ids[blocked.ID], ids[full.ID], ids[off.ID])
+	}
+	if w := doRequest(r, http.MethodGet, "/user/public/installers/nearby?lat=-30.03&lng=-51.23&available_on=amanhã", "", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("data inválida: status = %d, esperado %d", w.Code, http.StatusBadRequest)
+	}
+}
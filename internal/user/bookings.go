@@ -0,0 +1,203 @@
+package user
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+	"user-service/internal/database"
+	"user-service/internal/user/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var (
+	errClientNotFound       = errors.New("cliente não encontrado")
+	errBookingNotFound      = errors.New("agendamento não encontrado")
+	errBookingExists        = errors.New("serviço já agendado")
+	errInstallerUnavailable = errors.New("instalador indisponível na data")
+)
+
+// checkClient confere que o cliente do serviço existe.
+func checkClient(tx *gorm.DB, clientID string) error {
+	var clients int64
+	if err := tx.Model(&models.User{}).Where("id = ? AND role = ?", clientID, models.RoleCliente).
+		Count(&clients).Error; err != nil {
+		return err
+	}
+	if clients == 0 {
+		return errClientNotFound
+	}
+	return nil
+}
+
+// CreateBooking ocupa uma vaga da agenda do instalador para um serviço. Chamado
+// pelo backend de pedidos (chave de API com escopo installers:bookings:write);
+// responde 409 se o instalador não estiver aprovado ou disponível na data.
+func CreateBooking(c *gin.Context) {
+	var body struct {
+		ServiceID string `json:"service_id"`
+		ClientID  string `json:"client_id"`
+		Date      string `json:"date"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil || strings.TrimSpace(body.ServiceID) == "" || strings.TrimSpace(body.ClientID) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "service_id, client_id e date são obrigatórios"})
+		return
+	}
+
+	date, err := parseAgendaDate(body.Date)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	booking := models.Booking{
+		UserID:    c.Param("id"),
+		ClientID:  strings.TrimSpace(body.ClientID),
+		ServiceID: strings.TrimSpace(body.ServiceID),
+		Date:      date.Format(dateLayout),
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		// Trava o instalador para que reservas simultâneas não excedam a capacidade
+		if _, err := lockApprovedInstaller(tx, booking.UserID); err != nil {
+			return err
+		}
+		if err := checkClient(tx, booking.ClientID); err != nil {
+			return err
+		}
+
+		// Um serviço cancelado pode ser reagendado; o registro antigo é descartado
+		var existing models.Booking
+		result := tx.Where("service_id = ?", booking.ServiceID).Limit(1).Find(&existing)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			if existing.CanceledAt == nil {
+				return errBookingExists
+			}
+			if err := tx.Delete(&existing).Error; err != nil {
+				return err
+			}
+		}
+
+		availability, err := availabilityOn(tx, []string{booking.UserID}, date)
+		if err != nil {
+			return err
+		}
+		if !availability[booking.UserID].Available {
+			return errInstallerUnavailable
+		}
+		return tx.Create(&booking).Error
+	})
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Instalador não encontrado"})
+		return
+	case errors.Is(err, errInstallerNotApproved):
+		c.JSON(http.StatusConflict, gin.H{"error": "Instalador não está aprovado"})
+		return
+	case errors.Is(err, errClientNotFound):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cliente não encontrado"})
+		return
+	case errors.Is(err, errInstallerUnavailable):
+		c.JSON(http.StatusConflict, gin.H{"error": "Instalador indisponível na data"})
+		return
+	case errors.Is(err, errBookingExists):
+		c.JSON(http.StatusConflict, gin.H{"error": "Serviço já agendado"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao agendar serviço"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, booking)
+}
+
+// CancelBooking libera a vaga ocupada por um serviço ainda não concluído.
+func CancelBooking(c *gin.Context) {
+	result := database.DB.Model(&models.Booking{}).
+		Where("user_id = ? AND service_id = ? AND canceled_at IS NULL AND completed_at IS NULL", c.Param("id"), c.Param("service_id")).
+		Update("canceled_at", time.Now())
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao cancelar agendamento"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Agendamento não encontrado"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Agendamento cancelado com sucesso"})
+}
+
+// CompleteBooking registra que o serviço foi executado, o que libera a
+// avaliação pelo cliente. Se o serviço ainda não estava registrado, ele é
+// criado já concluído para o client_id informado. Repetir a chamada não
+// altera a data de conclusão.
+func CompleteBooking(c *gin.Context) {
+	var body struct {
+		ClientID string `json:"client_id"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil || strings.TrimSpace(body.ClientID) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "client_id é obrigatório"})
+		return
+	}
+
+	booking := models.Booking{
+		UserID:    c.Param("id"),
+		ClientID:  strings.TrimSpace(body.ClientID),
+		ServiceID: c.Param("service_id"),
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if _, err := lockInstaller(tx, booking.UserID); err != nil {
+			return err
+		}
+
+		var existing models.Booking
+		result := tx.Where("service_id = ?", booking.ServiceID).Limit(1).Find(&existing)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			if existing.UserID != booking.UserID || existing.ClientID != booking.ClientID || existing.CanceledAt != nil {
+				return errBookingNotFound
+			}
+			if existing.CompletedAt == nil {
+				now := time.Now()
+				existing.CompletedAt = &now
+				if err := tx.Model(&existing).Update("completed_at", now).Error; err != nil {
+					return err
+				}
+			}
+			booking = existing
+			return nil
+		}
+
+		if err := checkClient(tx, booking.ClientID); err != nil {
+			return err
+		}
+		now := time.Now()
+		booking.CompletedAt = &now
+		return tx.Create(&booking).Error
+	})
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Instalador não encontrado"})
+		return
+	case errors.Is(err, errBookingNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Agendamento não encontrado"})
+		return
+	case errors.Is(err, errClientNotFound):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cliente não encontrado"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao concluir serviço"})
+		return
+	}
+
+	c.JSON(http.StatusOK, booking)
+}
@@ -0,0 +1,41 @@
+package user
+
+import (
+	"net/http"
+	"testing"
+	"time"
+	"user-service/internal/database"
+	"user-service/internal/user/models"
+)
+
+// Excluir a conta apaga na mesma transação os registros que pertencem a ela.
+func TestDeleteUserRemovesOwnedRecords(t *testing.T) {
+	r := newTestRouter()
+	user := createTestUser(t, models.RoleCliente, models.StatusApproved)
+	tokens := loginTestUser(t, r, user)
+
+	owned := []interface{}{
+		&models.UserToken{UserID: user.ID, Purpose: models.TokenPurposePasswordReset, TokenHash: user.ID, ExpiresAt: time.Now().Add(time.Hour)},
+		&models.MFARecoveryCode{UserID: user.ID, CodeHash: user.ID},
+		&models.UserIdentity{UserID: user.ID, Provider: "stub", Subject: user.ID},
+	}
+	for _, record := range owned {
+		if err := database.DB.Create(record).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if w := doRequest(r, http.MethodDelete, "/user/me", tokens.Token, ""); w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body)
+	}
+
+	for _, model := range userOwnedModels {
+		var count int64
+		if err := database.DB.Model(model).Where("user_id = ?", user.ID).Count(&count).Error; err != nil {
+			t.Fatal(err)
+		}
+		if count != 0 {
+			t.Errorf("%T: %d registros restantes", model, count)
+		}
+	}
+}
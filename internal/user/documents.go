@@ -0,0 +1,331 @@
+package user
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+	"user-service/internal/database"
+	"user-service/internal/s3helper"
+	"user-service/internal/user/models"
+	"user-service/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Formatos aceitos para documentos, pelo conteúdo do arquivo
+var documentContentTypes = map[string]string{
+	"application/pdf": ".pdf",
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+}
+
+type DocumentResponse struct {
+	models.InstallerDocument
+	URL string `json:"url,omitempty"`
+}
+
+// requiredDocuments lê INSTALLER_REQUIRED_DOCUMENTS: tipos separados por
+// vírgula, com alternativas separadas por "|" (ex.: "identidade|cnh,nr10").
+func requiredDocuments() [][]string {
+	var required [][]string
+	for _, item := range utils.GetEnvList("INSTALLER_REQUIRED_DOCUMENTS", []string{
+		models.DocumentIdentidade + "|" + models.DocumentCNH,
+		models.DocumentNR10,
+		models.DocumentComprovanteEndereco,
+	}) {
+		required = append(required, strings.Split(item, "|"))
+	}
+	return required
+}
+
+// errDocumentApproved impede que um novo envio substitua um documento já
+// aprovado.
+var errDocumentApproved = errors.New("documento já aprovado")
+
+// missingDocumentsError impede a aprovação de um instalador sem todos os
+// documentos obrigatórios aprovados.
+type missingDocumentsError struct {
+	missing []string
+}
+
+func (e *missingDocumentsError) Error() string {
+	return "documentos obrigatórios pendentes: " + strings.Join(e.missing, ", ")
+}
+
+// canOnboard informa se o usuário, ainda sem acesso, pode entrar apenas para
+// enviar documentos: instaladores pendentes ou recusados (que podem reenviar).
+func canOnboard(user models.User) bool {
+	return user.Role == models.RoleInstalador &&
+		(user.Status == models.StatusPending || user.Status == models.StatusRejected)
+}
+
+// missingRequiredDocuments retorna os documentos obrigatórios ainda não
+// aprovados para o usuário.
+func missingRequiredDocuments(tx *gorm.DB, userID string) ([]string, error) {
+	var approved []string
+	if err := tx.Model(&models.InstallerDocument{}).
+		Where("user_id = ? AND status = ?", userID, models.DocumentApproved).
+		Pluck("type", &approved).Error; err != nil {
+		return nil, err
+	}
+
+	has := map[string]bool{}
+	for _, t := range approved {
+		has[t] = true
+	}
+
+	missing := []string{}
+	for _, alternatives := range requiredDocuments() {
+		ok := false
+		for _, t := range alternatives {
+			if has[t] {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			missing = append(missing, strings.Join(alternatives, "|"))
+		}
+	}
+	return missing, nil
+}
+
+// documentApproved informa se o usuário já tem o documento do tipo aprovado.
+func documentApproved(tx *gorm.DB, userID, docType string) (bool, error) {
+	var approved int64
+	err := tx.Model(&models.InstallerDocument{}).
+		Where("user_id = ? AND type = ? AND status = ?", userID, docType, models.DocumentApproved).
+		Count(&approved).Error
+	return approved > 0, err
+}
+
+func documentURLTTL() time.Duration {
+	return utils.GetEnvDuration("DOCUMENT_URL_TTL", 10*time.Minute)
+}
+
+func newDocumentResponse(doc models.InstallerDocument, withURL bool) DocumentResponse {
+	response := DocumentResponse{InstallerDocument: doc}
+	if withURL {
+		url, err := s3helper.PresignGetURL(doc.FileKey, documentURLTTL())
+		if err != nil {
+			fmt.Println("⚠️ Erro ao gerar URL do documento:", err)
+		}
+		response.URL = url
+	}
+	return response
+}
+
+// UploadDocument recebe um documento do instalador autenticado (multipart:
+// type e file). Um novo envio do mesmo tipo substitui o anterior e volta para
+// análise, exceto se o anterior já foi aprovado.
+func UploadDocument(c *gin.Context) {
+	userID := c.GetString("user_id")
+	docType := c.PostForm("type")
+	if !models.IsDocumentType(docType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Tipo de documento inválido", "types": models.DocumentTypes})
+		return
+	}
+
+	if approved, err := documentApproved(database.DB, userID, docType); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao salvar documento"})
+		return
+	} else if approved {
+		c.JSON(http.StatusConflict, gin.H{"error": "Documento já aprovado"})
+		return
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Arquivo não enviado"})
+		return
+	}
+
+	maxSize := int64(utils.GetEnvInt("DOCUMENT_MAX_SIZE_MB", 10)) << 20
+	if header.Size > maxSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("Arquivo maior que %d MB", maxSize>>20)})
+		return
+	}
+
+	src, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao abrir arquivo"})
+		return
+	}
+	defer src.Close()
+
+	content, err := io.ReadAll(io.LimitReader(src, maxSize+1))
+	if err != nil || int64(len(content)) > maxSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Erro ao ler arquivo"})
+		return
+	}
+
+	contentType := http.DetectContentType(content)
+	ext, ok := documentContentTypes[contentType]
+	if !ok {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Formato não suportado (use PDF, JPG ou PNG)"})
+		return
+	}
+
+	key := fmt.Sprintf("documents/%s/%s_%s%s", userID, docType, uuid.New().String(), ext)
+	if err := s3helper.UploadPrivateFile(bytes.NewReader(content), key, contentType); err != nil {
+		if errors.Is(err, s3helper.ErrPrivateBucketNotConfigured) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Envio de documentos indisponível"})
+			return
+		}
+		fmt.Println("⚠️ Erro ao enviar documento para o S3:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao fazer upload para S3"})
+		return
+	}
+
+	doc := models.InstallerDocument{
+		UserID:      userID,
+		Type:        docType,
+		FileKey:     key,
+		FileName:    filepath.Base(header.Filename),
+		ContentType: contentType,
+		Size:        int64(len(content)),
+		Status:      models.DocumentPending,
+	}
+
+	var previous []models.InstallerDocument
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		// Confere de novo: o documento pode ter sido aprovado durante o envio
+		if approved, err := documentApproved(tx, userID, docType); err != nil {
+			return err
+		} else if approved {
+			return errDocumentApproved
+		}
+		if err := tx.Where("user_id = ? AND type = ?", userID, docType).Find(&previous).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ? AND type = ?", userID, docType).Delete(&models.InstallerDocument{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&doc).Error
+	})
+	if err != nil {
+		if delErr := s3helper.DeletePrivateFile(key); delErr != nil {
+			fmt.Println("⚠️ Erro ao remover arquivo órfão:", delErr)
+		}
+		if errors.Is(err, errDocumentApproved) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Documento já aprovado"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao salvar documento"})
+		return
+	}
+
+	for _, old := range previous {
+		if err := s3helper.DeletePrivateFile(old.FileKey); err != nil {
+			fmt.Println("⚠️ Erro ao deletar documento anterior:", err)
+		}
+	}
+
+	c.JSON(http.StatusCreated, newDocumentResponse(doc, false))
+}
+
+func listDocuments(c *gin.Context, userID string) {
+	var docs []models.InstallerDocument
+	if err := database.DB.Where("user_id = ?", userID).Order("type").Find(&docs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao listar documentos"})
+		return
+	}
+
+	missing, err := missingRequiredDocuments(database.DB, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao listar documentos"})
+		return
+	}
+
+	responses := make([]DocumentResponse, 0, len(docs))
+	for _, doc := range docs {
+		responses = append(responses, newDocumentResponse(doc, true))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"documents":         responses,
+		"missing_documents": missing,
+	})
+}
+
+// ListMyDocuments lista os documentos do instalador autenticado e os
+// obrigatórios ainda não aprovados.
+func ListMyDocuments(c *gin.Context) {
+	listDocuments(c, c.GetString("user_id"))
+}
+
+// ListUserDocuments lista os documentos de um instalador, com URLs de download
+// temporárias, para análise do administrador.
+func ListUserDocuments(c *gin.Context) {
+	listDocuments(c, c.Param("id"))
+}
+
+// DownloadDocument retorna uma URL temporária para baixar o documento.
+func DownloadDocument(c *gin.Context) {
+	var doc models.InstallerDocument
+	if err := database.DB.Where("id = ? AND user_id = ?", c.Param("document_id"), c.Param("id")).
+		First(&doc).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Documento não encontrado"})
+		return
+	}
+
+	url, err := s3helper.PresignGetURL(doc.FileKey, documentURLTTL())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao gerar URL do documento"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"url":        url,
+		"expires_in": int(documentURLTTL().Seconds()),
+	})
+}
+
+// ReviewDocument aprova ou rejeita um documento. A rejeição exige motivo.
+func ReviewDocument(c *gin.Context) {
+	var body struct {
+		Status string `json:"status"`
+		Reason string `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil ||
+		(body.Status != models.DocumentApproved && body.Status != models.DocumentRejected) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status deve ser approved ou rejected"})
+		return
+	}
+	reason := strings.TrimSpace(body.Reason)
+	if body.Status == models.DocumentRejected && reason == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "reason é obrigatório ao rejeitar"})
+		return
+	}
+
+	var doc models.InstallerDocument
+	if err := database.DB.Where("id = ? AND user_id = ?", c.Param("document_id"), c.Param("id")).
+		First(&doc).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Documento não encontrado"})
+		return
+	}
+
+	now := time.Now()
+	doc.Status = body.Status
+	doc.ReviewReason = reason
+	doc.ReviewedBy = c.GetString("actor_id")
+	doc.ReviewedAt = &now
+	if err := database.DB.Model(&doc).Updates(map[string]interface{}{
+		"status":        doc.Status,
+		"review_reason": doc.ReviewReason,
+		"reviewed_by":   doc.ReviewedBy,
+		"reviewed_at":   doc.ReviewedAt,
+	}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao salvar análise do documento"})
+		return
+	}
+
+	c.JSON(http.StatusOK, newDocumentResponse(doc, false))
+}
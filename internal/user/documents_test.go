@@ -0,0 +1,213 @@
+package user
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"user-service/internal/database"
+	"user-service/internal/testutil"
+	"user-service/internal/user/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+var testPDF = []byte("%PDF-1.4\n%documento de teste\n")
+
+// approveRequiredDocuments registra como aprovados os documentos exigidos por
+// padrão para aprovar um instalador.
+func approveRequiredDocuments(t *testing.T, userID string) {
+	t.Helper()
+	for _, docType := range []string{models.DocumentIdentidade, models.DocumentNR10, models.DocumentComprovanteEndereco} {
+		doc := models.InstallerDocument{
+			UserID:  userID,
+			Type:    docType,
+			FileKey: "documents/" + userID + "/" + docType,
+			Status:  models.DocumentApproved,
+		}
+		if err := database.DB.Create(&doc).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func uploadDocument(r *gin.Engine, token, docType string, content []byte) *httptest.ResponseRecorder {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	form.WriteField("type", docType)
+	file, _ := form.CreateFormFile("file", docType+".pdf")
+	file.Write(content)
+	form.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/user/me/documents", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func decodeDocument(t *testing.T, w *httptest.ResponseRecorder) models.InstallerDocument {
+	t.Helper()
+	if w.Code != http.StatusCreated {
+		t.Fatalf("envio: status = %d: %s", w.Code, w.Body)
+	}
+	var doc models.InstallerDocument
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.DB.First(&doc, "id = ?", doc.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func privateObject(key string) bool {
+	return testutil.HasS3Object(os.Getenv("AWS_PRIVATE_BUCKET_NAME"), key)
+}
+
+// O instalador pendente entra com um token de cadastro que só vale para os
+// documentos.
+func TestOnboardingTokenUploadsDocuments(t *testing.T) {
+	r := newTestRouter()
+	installer := createTestUser(t, models.RoleInstalador, models.StatusPending)
+
+	w := doRequest(r, http.MethodPost, "/user/login", "", `{"email":"`+installer.Email+`","password":"`+testPassword+`"}`)
+	var login struct {
+		OnboardingRequired bool   `json:"onboarding_required"`
+		OnboardingToken    string `json:"onboarding_token"`
+		Token              string `json:"token"`
+	}
+	if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &login) != nil ||
+		!login.OnboardingRequired || login.OnboardingToken == "" || login.Token != "" {
+		t.Fatalf("login pendente: status = %d: %s", w.Code, w.Body)
+	}
+
+	doc := decodeDocument(t, uploadDocument(r, login.OnboardingToken, models.DocumentNR10, testPDF))
+	if doc.Status != models.DocumentPending || doc.ContentType != "application/pdf" || !privateObject(doc.FileKey) {
+		t.Errorf("documento inesperado: %+v", doc)
+	}
+
+	if w := doRequest(r, http.MethodGet, "/user/me/documents", login.OnboardingToken, ""); w.Code != http.StatusOK {
+		t.Errorf("listar documentos: status = %d: %s", w.Code, w.Body)
+	}
+	if w := doRequest(r, http.MethodGet, "/user/me", login.OnboardingToken, ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("token de cadastro em outra rota: status = %d, esperado %d", w.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestUploadDocumentValidation(t *testing.T) {
+	r := newTestRouter()
+	token := bearerToken(t, createTestUser(t, models.RoleInstalador, models.StatusApproved))
+
+	if w := uploadDocument(r, token, "passaporte", testPDF); w.Code != http.StatusBadRequest {
+		t.Errorf("tipo inválido: status = %d, esperado %d", w.Code, http.StatusBadRequest)
+	}
+	if w := uploadDocument(r, token, models.DocumentNR10, []byte("texto simples")); w.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("formato inválido: status = %d, esperado %d", w.Code, http.StatusUnsupportedMediaType)
+	}
+	client := bearerToken(t, createTestUser(t, models.RoleCliente, models.StatusApproved))
+	if w := uploadDocument(r, client, models.DocumentNR10, testPDF); w.Code != http.StatusForbidden {
+		t.Errorf("cliente: status = %d, esperado %d", w.Code, http.StatusForbidden)
+	}
+}
+
+// Um novo envio substitui o documento em análise, mas não um já aprovado.
+func TestUploadDocumentReplacesOnlyUnapproved(t *testing.T) {
+	r := newTestRouter()
+	installer := createTestUser(t, models.RoleInstalador, models.StatusApproved)
+	token := bearerToken(t, installer)
+	adminToken := bearerToken(t, createTestUser(t, models.RoleAdmin, models.StatusApproved))
+
+	first := decodeDocument(t, uploadDocument(r, token, models.DocumentCNH, testPDF))
+	second := decodeDocument(t, uploadDocument(r, token, models.DocumentCNH, testPDF))
+	if privateObject(first.FileKey) || !privateObject(second.FileKey) {
+		t.Error("arquivo anterior não foi substituído")
+	}
+
+	review := "/user/" + installer.ID + "/documents/" + second.ID + "/review"
+	if w := doRequest(r, http.MethodPatch, review, adminToken, `{"status":"approved"}`); w.Code != http.StatusOK {
+		t.Fatalf("aprovar documento: status = %d: %s", w.Code, w.Body)
+	}
+
+	if w := uploadDocument(r, token, models.DocumentCNH, testPDF); w.Code != http.StatusConflict {
+		t.Errorf("reenvio de documento aprovado: status = %d, esperado %d", w.Code, http.StatusConflict)
+	}
+	if err := database.DB.First(&second, "id = ?", second.ID).Error; err != nil || second.Status != models.DocumentApproved {
+		t.Errorf("documento aprovado alterado: %+v (err = %v)", second, err)
+	}
+	if !privateObject(second.FileKey) {
+		t.Error("arquivo do documento aprovado removido")
+	}
+}
+
+// O instalador só é aprovado com todos os documentos obrigatórios aprovados.
+func TestApprovalRequiresDocuments(t *testing.T) {
+	r := newTestRouter()
+	installer := createTestUser(t, models.RoleInstalador, models.StatusPending)
+	adminToken := bearerToken(t, createTestUser(t, models.RoleAdmin, models.StatusApproved))
+	authorize := "/user/" + installer.ID + "/authorize"
+
+	w := doRequest(r, http.MethodPatch, authorize, adminToken, "")
+	var missing struct {
+		MissingDocuments []string `json:"missing_documents"`
+	}
+	if w.Code != http.StatusConflict || json.Unmarshal(w.Body.Bytes(), &missing) != nil || len(missing.MissingDocuments) != 3 {
+		t.Fatalf("sem documentos: status = %d: %s", w.Code, w.Body)
+	}
+
+	doc := models.InstallerDocument{UserID: installer.ID, Type: models.DocumentNR10, FileKey: "x", Status: models.DocumentPending}
+	if err := database.DB.Create(&doc).Error; err != nil {
+		t.Fatal(err)
+	}
+	review := "/user/" + installer.ID + "/documents/" + doc.ID + "/review"
+	if w := doRequest(r, http.MethodPatch, review, adminToken, `{"status":"rejected"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("rejeição sem motivo: status = %d, esperado %d", w.Code, http.StatusBadRequest)
+	}
+
+	if err := database.DB.Delete(&doc).Error; err != nil {
+		t.Fatal(err)
+	}
+	approveRequiredDocuments(t, installer.ID)
+	if w := doRequest(r, http.MethodPatch, authorize, adminToken, ""); w.Code != http.StatusOK {
+		t.Errorf("com documentos: status = %d: %s", w.Code, w.Body)
+	}
+}
+
+func TestDocumentRoutesAdminOnly(t *testing.T) {
+	withDocument := func(suffix, body string) func(t *testing.T) (string, string) {
+		return func(t *testing.T) (string, string) {
+			installer := createTestUser(t, models.RoleInstalador, models.StatusPending)
+			doc := models.InstallerDocument{UserID: installer.ID, Type: models.DocumentNR10, FileKey: "x", Status: models.DocumentPending}
+			if err := database.DB.Create(&doc).Error; err != nil {
+				t.Fatal(err)
+			}
+			return "/user/" + installer.ID + "/documents/" + doc.ID + suffix, body
+		}
+	}
+	testAdminOnly(t, map[string]adminRoute{
+		"listar documentos": {http.MethodGet, "/user/x/documents", func(t *testing.T) (string, string) {
+			return "/user/" + createTestUser(t, models.RoleInstalador, models.StatusPending).ID + "/documents", ""
+		}, http.StatusOK},
+		"baixar documento":   {http.MethodGet, "/user/x/documents/y/download", withDocument("/download", ""), http.StatusOK},
+		"analisar documento": {http.MethodPatch, "/user/x/documents/y/review", withDocument("/review", `{"status":"approved"}`), http.StatusOK},
+	})
+}
+
+// Excluir a conta do instalador apaga os arquivos dos documentos.
+func TestDeleteUserRemovesDocumentFiles(t *testing.T) {
+	r := newTestRouter()
+	installer := createTestUser(t, models.RoleInstalador, models.StatusApproved)
+	token := bearerToken(t, installer)
+	doc := decodeDocument(t, uploadDocument(r, token, models.DocumentNR10, testPDF))
+
+	if w := doRequest(r, http.MethodDelete, "/user/me", token, ""); w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body)
+	}
+	if privateObject(doc.FileKey) {
+		t.Error("arquivo do documento continua no S3")
+	}
+}
@@ -10,38 +10,45 @@ import (
 	"user-service/internal/user/models"
 
 	"user-service/internal/email"
+	"user-service/internal/loginguard"
+	"user-service/internal/revocation"
 	"user-service/internal/s3helper"
 	"user-service/internal/utils"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 type UserResponse struct {
-	ID                    string  `json:"id"`
-	Name                  string  `json:"username"`
-	Email                 string  `json:"email"`
-	Role                  string  `json:"role"`
-	Phone                 string  `json:"phone"`
-	CPF                   string  `json:"cpf"`
-	CNPJ                  string  `json:"cnpj"`
-	CompanyName           string  `json:"company_name"`
-	Street                string  `json:"street"`
-	Number                string  `json:"number"`
-	Neighborhood          string  `json:"neighborhood"`
-	City                  string  `json:"city"`
-	State                 string  `json:"state"`
-	Complement            string  `json:"complement"`
-	CEP                   string  `json:"cep"`
-	Latitude              float64 `json:"latitude"`
-	Longitude             float64 `json:"longitude"`
-	BirthDate             string  `json:"birth_date"`
-	Reference             string  `json:"reference"`
-	AceptTerms            bool    `json:"accept_terms"`
-	AverageRating         float64 `json:"average_rating"`
-	TotalServicesAccepted int     `json:"total_services_accepted"`
-	ServicesNotExecuted   int     `json:"services_not_executed"`
-
-	Photo string `json:"photo"`
+	ID                    string     `json:"id"`
+	Name                  string     `json:"username"`
+	Email                 string     `json:"email"`
+	EmailVerifiedAt       *time.Time `json:"email_verified_at"`
+	Role                  string     `json:"role"`
+	Status                string     `json:"status"`
+	Phone                 string     `json:"phone"`
+	CPF                   string     `json:"cpf"`
+	CNPJ                  string     `json:"cnpj"`
+	CompanyName           string     `json:"company_name"`
+	Street                string     `json:"street"`
+	Number                string     `json:"number"`
+	Neighborhood          string     `json:"neighborhood"`
+	City                  string     `json:"city"`
+	State                 string     `json:"state"`
+	Complement            string     `json:"complement"`
+	CEP                   string     `json:"cep"`
+	Latitude              float64    `json:"latitude"`
+	Longitude             float64    `json:"longitude"`
+	BirthDate             string     `json:"birth_date"`
+	Reference             string     `json:"reference"`
+	AceptTerms            bool       `json:"accept_terms"`
+	AverageRating         float64    `json:"average_rating"`
+	ReviewCount           int        `json:"review_count"`
+	TotalServicesAccepted int        `json:"total_services_accepted"`
+	ServicesNotExecuted   int        `json:"services_not_executed"`
+
+	Photo      string `json:"photo"`
+	MFAEnabled bool   `json:"mfa_enabled"`
 }
 
 type UserInstalerResponse struct {
@@ -50,12 +57,64 @@ type UserInstalerResponse struct {
 	CompanyName           string  `json:"company_name"`
 	Role                  string  `json:"role"`
 	AverageRating         float64 `json:"average_rating"`
+	ReviewCount           int     `json:"review_count"`
 	TotalServicesAccepted int     `json:"total_services_accepted"`
 	ServicesNotExecuted   int     `json:"services_not_executed"`
 	Phone                 string  `json:"phone"`
 	State                 string  `json:"state"`
 
-	Photo string `json:"photo"`
+	Photo       string          `json:"photo"`
+	Specialties []Qualification `json:"specialties"`
+	Brands      []Qualification `json:"brands"`
+}
+
+func newInstallerResponse(user models.User) UserInstalerResponse {
+	return UserInstalerResponse{
+		ID:                    user.ID,
+		Name:                  user.Name,
+		CompanyName:           user.CompanyName,
+		AverageRating:         user.AverageRating,
+		ReviewCount:           user.ReviewCount,
+		TotalServicesAccepted: user.TotalServicesAccepted,
+		ServicesNotExecuted:   user.ServicesNotExecuted,
+		Role:                  user.Role,
+		Photo:                 user.Photo,
+		Phone:                 user.Phone,
+		State:                 user.State,
+	}
+}
+
+func newUserResponse(user models.User) UserResponse {
+	return UserResponse{
+		ID:                    user.ID,
+		Name:                  user.Name,
+		Email:                 user.Email,
+		EmailVerifiedAt:       user.EmailVerifiedAt,
+		Phone:                 user.Phone,
+		CPF:                   user.CPF,
+		CNPJ:                  user.CNPJ,
+		CompanyName:           user.CompanyName,
+		Street:                user.Street,
+		Number:                user.Number,
+		Neighborhood:          user.Neighborhood,
+		City:                  user.City,
+		State:                 user.State,
+		Complement:            user.Complement,
+		CEP:                   user.CEP,
+		Latitude:              user.Latitude,
+		Longitude:             user.Longitude,
+		BirthDate:             user.BirthDate,
+		Reference:             user.Reference,
+		AceptTerms:            user.AceptTerms,
+		AverageRating:         user.AverageRating,
+		ReviewCount:           user.ReviewCount,
+		TotalServicesAccepted: user.TotalServicesAccepted,
+		ServicesNotExecuted:   user.ServicesNotExecuted,
+		Role:                  user.Role,
+		Status:                user.Status,
+		Photo:                 user.Photo,
+		MFAEnabled:            user.MFAEnabled,
+	}
 }
 
 func RegisterUser(c *gin.Context) {
@@ -65,11 +124,28 @@ func RegisterUser(c *gin.Context) {
 		return
 	}
 
-	if newUser.Role == "cliente" {
-		newUser.Authorized = true
+	// Administradores não podem se cadastrar pela rota pública
+	if newUser.Role != models.RoleCliente && newUser.Role != models.RoleInstalador {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Role inválida (esperado: cliente ou instalador)"})
+		return
+	}
+
+	// Clientes são aprovados no cadastro; instaladores aguardam análise
+	if newUser.Role == models.RoleCliente {
+		newUser.Status = models.StatusApproved
 	} else {
-		newUser.Authorized = false
+		newUser.Status = models.StatusPending
 	}
+	newUser.Authorized = models.IsActiveStatus(newUser.Status)
+
+	// O e-mail só é considerado verificado após a confirmação pelo link
+	newUser.EmailVerifiedAt = nil
+
+	// Métricas são calculadas pelo serviço, nunca informadas no cadastro
+	newUser.AverageRating = 0
+	newUser.ReviewCount = 0
+	newUser.TotalServicesAccepted = 0
+	newUser.ServicesNotExecuted = 0
 
 	// Fallback: se latitude ou longitude não foram enviados
 	if (newUser.Latitude == 0 || newUser.Longitude == 0) &&
@@ -98,6 +174,11 @@ func RegisterUser(c *gin.Context) {
 		}
 	}
 
+	if problems := utils.CheckPasswordPolicy(newUser.Password); len(problems) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Senha não atende à política de segurança", "details": problems})
+		return
+	}
+
 	if err := newUser.HashPassword(); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
 		return
@@ -109,7 +190,9 @@ func RegisterUser(c *gin.Context) {
 		return
 	}
 
-	if newUser.Role == "instalador" {
+	go sendEmailVerification(newUser)
+
+	if newUser.Role == models.RoleInstalador {
 		go func() {
 			err := email.NotifyNewInstaller(email.InstallerData{
 				Name:         newUser.Name,
@@ -146,43 +229,89 @@ func RegisterUser(c *gin.Context) {
 
 func LoginUser(c *gin.Context) {
 	var input struct {
-		Email    string `json:"email"`
-		Password string `json:"password"`
+		Email      string `json:"email"`
+		Password   string `json:"password"`
+		DeviceName string `json:"device_name"`
 	}
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON"})
 		return
 	}
 
+	accountKey := loginAccountKey(input.Email)
+	if respondIfLocked(c, accountKey, loginguard.IPKey(c.ClientIP())) {
+		return
+	}
+
 	var user models.User
 	if err := database.DB.Where("email = ?", input.Email).First(&user).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		dummyUser.CheckPassword(input.Password)
+		registerLoginFailure(c, accountKey)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": invalidCredentialsMessage})
 		return
 	}
 
 	if !user.CheckPassword(input.Password) {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid password"})
+		registerLoginFailure(c, accountKey)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": invalidCredentialsMessage})
+		return
+	}
+
+	if err := loginguard.Reset(accountKey); err != nil {
+		fmt.Println("⚠️ Erro ao limpar tentativas de login:", err)
+	}
+
+	completeLogin(c, user, input.DeviceName)
+}
+
+// completeLogin aplica as verificações comuns a todo login já autenticado
+// (autorização, e-mail verificado, MFA) e responde com os tokens ou com o
+// desafio MFA.
+func completeLogin(c *gin.Context, user models.User, deviceName string) {
+	if !user.Authorized && !canOnboard(user) {
+		c.JSON(http.StatusForbidden, gin.H{"error": statusDeniedMessage(user.Status), "status": user.Status})
 		return
 	}
 
+	if emailVerificationRequired() && user.EmailVerifiedAt == nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "E-mail ainda não verificado"})
+		return
+	}
+
+	// Instalador ainda não aprovado recebe apenas o token de cadastro, para
+	// enviar os documentos exigidos na aprovação
 	if !user.Authorized {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Usuário ainda não autorizado"})
+		token, err := utils.GenerateOnboardingToken(user.ID, user.Role)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"onboarding_required": true,
+			"status":              user.Status,
+			"message":             statusDeniedMessage(user.Status),
+			"onboarding_token":    token,
+			"expires_in":          int(utils.OnboardingTokenTTL().Seconds()),
+		})
 		return
 	}
 
-	token, err := utils.GenerateJWT(user.ID, user.Role)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+	// Com MFA, a senha correta rende apenas um desafio a ser trocado em /login/mfa
+	if user.MFAEnabled || mfaRequiredForRole(user.Role) {
+		challenge, err := utils.GenerateMFAChallenge(user.ID, user.Role)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"mfa_required":            true,
+			"mfa_enrollment_required": !user.MFAEnabled,
+			"mfa_token":               challenge,
+		})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"ID":     user.ID,
-		"name":   user.Name,
-		"person": user.Role,
-		"token":  token,
-		"photo":  user.Photo,
-	})
+	respondLogin(c, user, deviceName, nil)
 }
 
 func ListUsers(c *gin.Context) {
@@ -205,59 +334,20 @@ func ListUsers(c *gin.Context) {
 
 	var userResponses []UserResponse
 	for _, user := range users {
-		userResponses = append(userResponses, UserResponse{
-			ID:                    user.ID,
-			Name:                  user.Name,
-			Email:                 user.Email,
-			Phone:                 user.Phone,
-			CPF:                   user.CPF,
-			CNPJ:                  user.CNPJ,
-			CompanyName:           user.CompanyName,
-			Street:                user.Street,
-			Number:                user.Number,
-			Neighborhood:          user.Neighborhood,
-			City:                  user.City,
-			State:                 user.State,
-			Complement:            user.Complement,
-			CEP:                   user.CEP,
-			Latitude:              user.Latitude,
-			Longitude:             user.Longitude,
-			BirthDate:             user.BirthDate,
-			Reference:             user.Reference,
-			AceptTerms:            user.AceptTerms,
-			AverageRating:         user.AverageRating,
-			TotalServicesAccepted: user.TotalServicesAccepted,
-			ServicesNotExecuted:   user.ServicesNotExecuted,
-			Role:                  user.Role,
-			Photo:                 user.Photo,
-		})
+		userResponses = append(userResponses, newUserResponse(user))
 	}
 
 	c.JSON(http.StatusOK, userResponses)
 }
 
-func ListPendingInstallers(c *gin.Context) {
-	var users []models.User
-	database.DB.Where("role = ? AND authorized = ?", "instalador", false).Find(&users)
-	c.JSON(http.StatusOK, users)
-}
-
-func AuthorizeUser(c *gin.Context) {
-	id := c.Param("id")
-
-	if err := database.DB.Model(&models.User{}).Where("id = ?", id).Update("authorized", true).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao autorizar usuário"})
-		return
-	}
-
-	c.JSON(http.StatusOK, gin.H{"message": "Usuário autorizado com sucesso"})
-}
-
 func UpdatePassword(c *gin.Context) {
-	id := c.Param("id")
+	id := targetUserID(c)
+	self := id == c.GetString("user_id")
 
 	var body struct {
-		NewPassword string `json:"new_password"`
+		CurrentPassword string `json:"current_password"`
+		NewPassword     string `json:"new_password"`
+		DeviceName      string `json:"device_name"`
 	}
 	if err := c.ShouldBindJSON(&body); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON"})
@@ -270,6 +360,23 @@ func UpdatePassword(c *gin.Context) {
 		return
 	}
 
+	// O próprio usuário precisa confirmar a senha atual; administradores
+	// alterando a senha de outro usuário não a conhecem
+	if self && !user.CheckPassword(body.CurrentPassword) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Senha atual incorreta"})
+		return
+	}
+
+	if problems := utils.CheckPasswordPolicy(body.NewPassword); len(problems) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Senha não atende à política de segurança", "details": problems})
+		return
+	}
+
+	if user.CheckPassword(body.NewPassword) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A nova senha deve ser diferente da atual"})
+		return
+	}
+
 	user.Password = body.NewPassword
 	if err := user.HashPassword(); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash new password"})
@@ -281,25 +388,67 @@ func UpdatePassword(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Password updated successfully"})
+	// Encerra todas as sessões; quem trocou a própria senha recebe novos tokens
+	if err := revocation.RevokeUser(user.ID, "password_changed"); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke sessions"})
+		return
+	}
+
+	if !self {
+		c.JSON(http.StatusOK, gin.H{"message": "Password updated successfully"})
+		return
+	}
+
+	tokens, err := issueTokenPair(database.DB, c, user, "", body.DeviceName)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Password updated successfully",
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
+	})
 }
 
+// UpdateUser altera o perfil do usuário. Apenas os campos permitidos para o
+// papel do usuário editado são aceitos (ver profileFieldsByRole) e cada um é
+// validado; métricas e dados de acesso têm rotas próprias.
 func UpdateUser(c *gin.Context) {
-	id := c.Param("id")
+	id := targetUserID(c)
 
-	var updateData map[string]interface{}
-	if err := c.ShouldBindJSON(&updateData); err != nil {
+	var user models.User
+	if err := database.DB.First(&user, "id = ?", id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	body, err := c.GetRawData()
+	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON"})
 		return
 	}
 
-	delete(updateData, "id")
-	delete(updateData, "email")
-	delete(updateData, "password")
-	delete(updateData, "role")
-	delete(updateData, "authorized")
+	req, err := decodeUpdateUserRequest(body, user.Role)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if problems := req.Validate(); len(problems) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Dados inválidos", "details": problems})
+		return
+	}
 
-	if err := database.DB.Model(&models.User{}).Where("id = ?", id).Updates(updateData).Error; err != nil {
+	updates := req.Updates()
+	if len(updates) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Nenhum campo informado"})
+		return
+	}
+
+	if err := database.DB.Model(&user).Updates(updates).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
 		return
 	}
@@ -308,7 +457,7 @@ func UpdateUser(c *gin.Context) {
 }
 
 func DeleteUser(c *gin.Context) {
-	id := c.Param("id")
+	id := targetUserID(c)
 
 	var user models.User
 	if err := database.DB.First(&user, "id = ?", id).Error; err != nil {
@@ -316,16 +465,65 @@ func DeleteUser(c *gin.Context) {
 		return
 	}
 
-	if err := database.DB.Delete(&user).Error; err != nil {
+	// Os arquivos dos documentos só são apagados depois da exclusão confirmada
+	var documents []models.InstallerDocument
+	if err := database.DB.Where("user_id = ?", user.ID).Find(&documents).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user"})
 		return
 	}
 
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		return deleteUserRecords(tx, user)
+	}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user"})
+		return
+	}
+
+	for _, doc := range documents {
+		if err := s3helper.DeletePrivateFile(doc.FileKey); err != nil {
+			fmt.Println("⚠️ Erro ao deletar documento do usuário excluído:", err)
+		}
+	}
+
+	if err := revocation.RevokeUser(user.ID, "user_deleted"); err != nil {
+		fmt.Println("⚠️ Erro ao revogar tokens do usuário excluído:", err)
+	}
+
 	c.JSON(http.StatusOK, gin.H{"message": "User deleted successfully"})
 }
 
+// userOwnedModels são os registros do usuário apagados junto com a conta. O
+// log de auditoria e as revogações de token são mantidos.
+var userOwnedModels = []interface{}{
+	&models.Session{},
+	&models.RefreshToken{},
+	&models.UserToken{},
+	&models.MFARecoveryCode{},
+	&models.UserIdentity{},
+	&models.UserStatusHistory{},
+	&models.EmailDelivery{},
+	&models.InstallerDocument{},
+	&models.ServiceArea{},
+	&models.InstallerSpecialty{},
+	&models.InstallerBrand{},
+	&models.Booking{},
+	&models.WorkingHours{},
+	&models.BlockedDate{},
+	&models.InstallerStatsEvent{},
+}
+
+// deleteUserRecords apaga o usuário e os registros que pertencem a ele.
+func deleteUserRecords(tx *gorm.DB, user models.User) error {
+	for _, model := range userOwnedModels {
+		if err := tx.Where("user_id = ?", user.ID).Delete(model).Error; err != nil {
+			return err
+		}
+	}
+	return tx.Delete(&user).Error
+}
+
 func UpdateUserPhoto(c *gin.Context) {
-	id := c.Param("id")
+	id := targetUserID(c)
 
 	// Lê o arquivo da requisição
 	file, err := c.FormFile("photo")
@@ -387,25 +585,16 @@ func ListPublicInstallers(c *gin.Context) {
 	var users []models.User
 
 	// Filtra usuários com role = "instalador" e authorized = true
-	if err := database.DB.Where("role = ? AND authorized = ?", "instalador", true).Find(&users).Error; err != nil {
+	query := database.DB.Where("role = ? AND authorized = ?", "instalador", true)
+	if err := applyQualificationFilters(query, c).Find(&users).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao listar instaladores"})
 		return
 	}
 
-	var userResponses []UserInstalerResponse
-	for _, user := range users {
-		userResponses = append(userResponses, UserInstalerResponse{
-			ID:                    user.ID,
-			Name:                  user.Name,
-			CompanyName:           user.CompanyName,
-			AverageRating:         user.AverageRating,
-			TotalServicesAccepted: user.TotalServicesAccepted,
-			ServicesNotExecuted:   user.ServicesNotExecuted,
-			Role:                  user.Role,
-			Photo:                 user.Photo,
-			Phone:                 user.Phone,
-			State:                 user.State,
-		})
+	userResponses, err := installerResponses(users)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao listar instaladores"})
+		return
 	}
 
 	c.JSON(http.StatusOK, userResponses)
@@ -0,0 +1,100 @@
+package user
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"user-service/internal/audit"
+	"user-service/internal/database"
+	"user-service/internal/user/models"
+	"user-service/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ImpersonateUser emite para um administrador um token de curta duração em
+// nome do usuário informado, para o suporte ver o app como ele. O token não
+// tem refresh, carrega o administrador no claim "act" e fica registrado no log
+// de auditoria, assim como cada requisição feita com ele. Administradores e
+// contas ainda não autorizadas não podem ser personificados.
+func ImpersonateUser(c *gin.Context) {
+	var body struct {
+		Reason string `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil || strings.TrimSpace(body.Reason) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "reason é obrigatório"})
+		return
+	}
+
+	actorID := c.GetString("actor_id")
+	if c.GetBool("impersonating") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Ação não permitida durante a personificação de um usuário"})
+		return
+	}
+
+	var target models.User
+	if err := database.DB.First(&target, "id = ?", c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if target.ID == actorID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Não é possível personificar a si mesmo"})
+		return
+	}
+	if target.Role == models.RoleAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Não é permitido personificar administradores"})
+		return
+	}
+	if !target.Authorized {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Não é permitido personificar usuários não autorizados"})
+		return
+	}
+
+	token, err := utils.GenerateImpersonationToken(target.ID, target.Role, actorID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao gerar token"})
+		return
+	}
+	ttl := utils.ImpersonationTokenTTL()
+
+	// Sem registro na auditoria, o token não é entregue
+	if err := audit.Record(c, audit.ActionImpersonationStart, target.ID, map[string]interface{}{
+		"reason":     strings.TrimSpace(body.Reason),
+		"expires_in": int(ttl.Seconds()),
+	}); err != nil {
+		fmt.Println("⚠️ Erro ao registrar personificação na auditoria:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao registrar auditoria"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"token":      token,
+		"expires_in": int(ttl.Seconds()),
+		"user":       newUserResponse(target),
+	})
+}
+
+// ListAuditLogs lista as entradas mais recentes do log de auditoria, com
+// filtros opcionais por actor_id, target_user_id e action.
+func ListAuditLogs(c *gin.Context) {
+	query := database.DB.Model(&models.AuditLog{})
+	for _, field := range []string{"actor_id", "target_user_id", "action"} {
+		if value := c.Query(field); value != "" {
+			query = query.Where(field+" = ?", value)
+		}
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit <= 0 || limit > 500 {
+		limit = 100
+	}
+
+	var logs []models.AuditLog
+	if err := query.Order("created_at DESC").Limit(limit).Find(&logs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao listar auditoria"})
+		return
+	}
+
+	c.JSON(http.StatusOK, logs)
+}
@@ -0,0 +1,106 @@
+package user
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"user-service/internal/audit"
+	"user-service/internal/database"
+	"user-service/internal/user/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func impersonate(t *testing.T, r *gin.Engine, adminToken, targetID string) string {
+	t.Helper()
+	w := doRequest(r, http.MethodPost, "/user/"+targetID+"/impersonate", adminToken, `{"reason":"chamado de suporte"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("personificar: status = %d: %s", w.Code, w.Body)
+	}
+	var body struct {
+		Token string `json:"token"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body.Token == "" {
+		t.Fatalf("resposta sem token: %s", w.Body)
+	}
+	return body.Token
+}
+
+func auditLogsFor(t *testing.T, actorID, targetID string) []models.AuditLog {
+	t.Helper()
+	var logs []models.AuditLog
+	if err := database.DB.Where("actor_id = ? AND target_user_id = ?", actorID, targetID).
+		Order("created_at").Find(&logs).Error; err != nil {
+		t.Fatal(err)
+	}
+	return logs
+}
+
+// O token de personificação age como o usuário, mas cada requisição, inclusive
+// as recusadas, fica na auditoria em nome do administrador.
+func TestImpersonationRequestsAreAudited(t *testing.T) {
+	r := newTestRouter()
+	admin := createTestUser(t, models.RoleAdmin, models.StatusApproved)
+	target := createTestUser(t, models.RoleCliente, models.StatusApproved)
+	token := impersonate(t, r, bearerToken(t, admin), target.ID)
+
+	w := doRequest(r, http.MethodGet, "/user/me", token, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("/user/me: status = %d: %s", w.Code, w.Body)
+	}
+	var me UserResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &me); err != nil || me.ID != target.ID {
+		t.Fatalf("/user/me retornou outro usuário: %s", w.Body)
+	}
+
+	body := `{"current_password":"` + testPassword + `","new_password":"Outra-senha-forte-2"}`
+	if w := doRequest(r, http.MethodPut, "/user/me/password", token, body); w.Code != http.StatusForbidden {
+		t.Errorf("troca de senha personificada: status = %d, esperado %d", w.Code, http.StatusForbidden)
+	}
+
+	logs := auditLogsFor(t, admin.ID, target.ID)
+	want := []struct {
+		action string
+		path   string
+		status int
+	}{
+		{audit.ActionImpersonationStart, "", 0},
+		{audit.ActionImpersonationRequest, "/user/me", http.StatusOK},
+		{audit.ActionImpersonationRequest, "/user/me/password", http.StatusForbidden},
+	}
+	if len(logs) != len(want) {
+		t.Fatalf("entradas de auditoria = %d, esperado %d", len(logs), len(want))
+	}
+	for i, w := range want {
+		if logs[i].Action != w.action {
+			t.Errorf("entrada %d: action = %q, esperado %q", i, logs[i].Action, w.action)
+		}
+		if w.path == "" {
+			continue
+		}
+		if logs[i].Details["path"] != w.path || logs[i].Details["status"] != float64(w.status) {
+			t.Errorf("entrada %d: details = %v, esperado path %s e status %d", i, logs[i].Details, w.path, w.status)
+		}
+	}
+}
+
+// Administradores e contas não autorizadas não podem ser personificados.
+func TestImpersonationRefusedTargets(t *testing.T) {
+	r := newTestRouter()
+	admin := createTestUser(t, models.RoleAdmin, models.StatusApproved)
+	token := bearerToken(t, admin)
+
+	for name, target := range map[string]models.User{
+		"administrador":         createTestUser(t, models.RoleAdmin, models.StatusApproved),
+		"instalador pendente":   createTestUser(t, models.RoleInstalador, models.StatusPending),
+		"cliente desautorizado": createTestUser(t, models.RoleCliente, models.StatusPending),
+	} {
+		w := doRequest(r, http.MethodPost, "/user/"+target.ID+"/impersonate", token, `{"reason":"chamado de suporte"}`)
+		if w.Code != http.StatusForbidden {
+			t.Errorf("%s: status = %d, esperado %d: %s", name, w.Code, http.StatusForbidden, w.Body)
+		}
+		if logs := auditLogsFor(t, admin.ID, target.ID); len(logs) != 0 {
+			t.Errorf("%s: personificação recusada registrada como iniciada", name)
+		}
+	}
+}
@@ -0,0 +1,14 @@
+package user
+
+import (
+	"net/http"
+	"user-service/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetJWKS publica as chaves públicas de assinatura dos tokens.
+func GetJWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, utils.JWKS())
+}
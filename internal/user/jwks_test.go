@@ -0,0 +1,23 @@
+package user
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestGetJWKS(t *testing.T) {
+	w := doRequest(newTestRouter(), http.MethodGet, "/.well-known/jwks.json", "", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body)
+	}
+	var body struct {
+		Keys []map[string]interface{} `json:"keys"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body.Keys == nil {
+		t.Errorf("JWKS inválido: %s", w.Body)
+	}
+	if w.Header().Get("Cache-Control") == "" {
+		t.Error("JWKS sem Cache-Control")
+	}
+}
@@ -0,0 +1,71 @@
+package user
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"user-service/internal/database"
+	"user-service/internal/loginguard"
+	"user-service/internal/user/models"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Resposta única para e-mail inexistente e senha errada, evitando enumeração
+const invalidCredentialsMessage = "E-mail ou senha inválidos"
+
+// dummyUser tem um hash bcrypt válido usado quando o e-mail não existe, para
+// que o tempo de resposta seja o mesmo de uma senha incorreta.
+var dummyUser = func() models.User {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
+	return models.User{Password: string(hash)}
+}()
+
+func loginAccountKey(email string) string {
+	return loginguard.AccountKey(strings.ToLower(strings.TrimSpace(email)))
+}
+
+func mfaAccountKey(userID string) string {
+	return loginguard.AccountKey("mfa:" + userID)
+}
+
+// respondIfLocked responde 429 se alguma das chaves estiver bloqueada.
+func respondIfLocked(c *gin.Context, keys ...string) bool {
+	wait := loginguard.LockedFor(keys...)
+	if wait <= 0 {
+		return false
+	}
+
+	c.Header("Retry-After", fmt.Sprint(int(math.Ceil(wait.Seconds()))))
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"error":       "Muitas tentativas malsucedidas. Tente novamente mais tarde",
+		"retry_after": int(math.Ceil(wait.Seconds())),
+	})
+	return true
+}
+
+// registerLoginFailure contabiliza a falha para a conta e para o IP.
+func registerLoginFailure(c *gin.Context, accountKey string) {
+	loginguard.Fail(accountKey, loginguard.AccountPolicy())
+	loginguard.Fail(loginguard.IPKey(c.ClientIP()), loginguard.IPPolicy())
+}
+
+// UnlockUser remove o bloqueio por tentativas de login (senha e MFA) de uma conta.
+func UnlockUser(c *gin.Context) {
+	var user models.User
+	if err := database.DB.First(&user, "id = ?", c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	for _, key := range []string{loginAccountKey(user.Email), mfaAccountKey(user.ID)} {
+		if err := loginguard.Reset(key); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao desbloquear usuário"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Usuário desbloqueado com sucesso"})
+}
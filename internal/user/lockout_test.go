@@ -0,0 +1,144 @@
+package user
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"user-service/internal/loginguard"
+	"user-service/internal/user/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Endereço de origem das requisições do httptest
+const testClientIP = "192.0.2.1"
+
+// resetClientIPFailures zera as falhas do IP dos testes antes e depois do
+// teste, para que os bloqueios não vazem entre testes.
+func resetClientIPFailures(t *testing.T) {
+	t.Helper()
+	reset := func() {
+		if err := loginguard.Reset(loginguard.IPKey(testClientIP)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	reset()
+	t.Cleanup(reset)
+}
+
+func loginFrom(r *gin.Engine, forwardedFor, address, password string) *httptest.ResponseRecorder {
+	body := `{"email":"` + address + `","password":"` + password + `"}`
+	req := httptest.NewRequest(http.MethodPost, "/user/login", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	if forwardedFor != "" {
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestLoginLocksAccountAfterFailures(t *testing.T) {
+	resetClientIPFailures(t)
+	t.Setenv("LOGIN_MAX_ACCOUNT_FAILURES", "3")
+	r := newTestRouter()
+	user := createTestUser(t, models.RoleCliente, models.StatusApproved)
+
+	for i := 0; i < 3; i++ {
+		if w := loginFrom(r, "", user.Email, "errada"); w.Code != http.StatusUnauthorized {
+			t.Fatalf("falha %d: status = %d: %s", i+1, w.Code, w.Body)
+		}
+	}
+	w := loginFrom(r, "", user.Email, testPassword)
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") == "" {
+		t.Fatalf("conta bloqueada: status = %d, Retry-After = %q", w.Code, w.Header().Get("Retry-After"))
+	}
+
+	admin := createTestUser(t, models.RoleAdmin, models.StatusApproved)
+	if w := doRequest(r, http.MethodPost, "/user/"+user.ID+"/unlock", bearerToken(t, admin), ""); w.Code != http.StatusOK {
+		t.Fatalf("desbloqueio: status = %d: %s", w.Code, w.Body)
+	}
+	if w := loginFrom(r, "", user.Email, testPassword); w.Code != http.StatusOK {
+		t.Errorf("após desbloqueio: status = %d: %s", w.Code, w.Body)
+	}
+}
+
+// Sem TRUSTED_PROXIES, um X-Forwarded-For forjado não troca o IP contado:
+// as falhas continuam somando para o endereço da conexão.
+func TestSpoofedForwardedForKeepsIPCounter(t *testing.T) {
+	resetClientIPFailures(t)
+	t.Setenv("LOGIN_MAX_IP_FAILURES", "3")
+	r := newTestRouter()
+
+	for i, spoofed := range []string{"203.0.113.1", "203.0.113.2", "203.0.113.3"} {
+		if w := loginFrom(r, spoofed, uuid.NewString()+"@example.com", "errada"); w.Code != http.StatusUnauthorized {
+			t.Fatalf("falha %d: status = %d: %s", i+1, w.Code, w.Body)
+		}
+	}
+	if w := loginFrom(r, "203.0.113.4", uuid.NewString()+"@example.com", "errada"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("IP com novo X-Forwarded-For: status = %d, esperado %d", w.Code, http.StatusTooManyRequests)
+	}
+}
+
+// Atrás de um proxy confiável, o IP vem do X-Forwarded-For.
+func TestTrustedProxyForwardedFor(t *testing.T) {
+	resetClientIPFailures(t)
+	t.Setenv("TRUSTED_PROXIES", testClientIP)
+	t.Setenv("LOGIN_MAX_IP_FAILURES", "1")
+	r := newTestRouter()
+	t.Cleanup(func() { loginguard.Reset(loginguard.IPKey("203.0.113.10")) })
+
+	if w := loginFrom(r, "203.0.113.10", uuid.NewString()+"@example.com", "errada"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d: %s", w.Code, w.Body)
+	}
+	if w := loginFrom(r, "203.0.113.10", uuid.NewString()+"@example.com", "errada"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("mesmo cliente: status = %d, esperado %d", w.Code, http.StatusTooManyRequests)
+	}
+	if w := loginFrom(r, "203.0.113.11", uuid.NewString()+"@example.com", "errada"); w.Code != http.StatusUnauthorized {
+		t.Errorf("outro cliente: status = %d, esperado %d", w.Code, http.StatusUnauthorized)
+	}
+}
+
+// A confirmação do MFA e a troca dos códigos de recuperação seguem o mesmo
+// limite de tentativas do login em duas etapas.
+func TestMFAConfirmationLimited(t *testing.T) {
+	resetClientIPFailures(t)
+	t.Setenv("LOGIN_MAX_ACCOUNT_FAILURES", "3")
+	r := newTestRouter()
+	user := createTestUser(t, models.RoleCliente, models.StatusApproved)
+	token := bearerToken(t, user)
+
+	if w := doRequest(r, http.MethodPost, "/user/me/mfa/setup", token, ""); w.Code != http.StatusOK {
+		t.Fatalf("setup: status = %d: %s", w.Code, w.Body)
+	}
+	for i := 0; i < 3; i++ {
+		if w := doRequest(r, http.MethodPost, "/user/me/mfa/confirm", token, `{"code":"000000"}`); w.Code != http.StatusUnauthorized {
+			t.Fatalf("falha %d: status = %d: %s", i+1, w.Code, w.Body)
+		}
+	}
+	if w := doRequest(r, http.MethodPost, "/user/me/mfa/confirm", token, `{"code":"000000"}`); w.Code != http.StatusTooManyRequests {
+		t.Errorf("confirmação bloqueada: status = %d, esperado %d", w.Code, http.StatusTooManyRequests)
+	}
+
+	enrolled := createTestUser(t, models.RoleCliente, models.StatusApproved)
+	enrolledToken := bearerToken(t, enrolled)
+	enrollMFA(t, r, enrolledToken)
+	for i := 0; i < 3; i++ {
+		if w := doRequest(r, http.MethodPost, "/user/me/mfa/recovery-codes", enrolledToken, `{"code":"000000"}`); w.Code != http.StatusUnauthorized {
+			t.Fatalf("recovery-codes, falha %d: status = %d: %s", i+1, w.Code, w.Body)
+		}
+	}
+	if w := doRequest(r, http.MethodPost, "/user/me/mfa/recovery-codes", enrolledToken, `{"code":"000000"}`); w.Code != http.StatusTooManyRequests {
+		t.Errorf("recovery-codes bloqueado: status = %d, esperado %d", w.Code, http.StatusTooManyRequests)
+	}
+}
+
+func TestUnlockUserAdminOnly(t *testing.T) {
+	testAdminOnly(t, map[string]adminRoute{
+		"desbloquear": {http.MethodPost, "/user/x/unlock", func(t *testing.T) (string, string) {
+			return "/user/" + createTestUser(t, models.RoleCliente, models.StatusApproved).ID + "/unlock", ""
+		}, http.StatusOK},
+	})
+}
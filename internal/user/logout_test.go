@@ -0,0 +1,59 @@
+package user
+
+import (
+	"net/http"
+	"testing"
+	"user-service/internal/database"
+	"user-service/internal/user/models"
+)
+
+func TestLogoutRevokesTokens(t *testing.T) {
+	r := newTestRouter()
+	tokens := loginTestUser(t, r, createTestUser(t, models.RoleCliente, models.StatusApproved))
+
+	if w := doRequest(r, http.MethodPost, "/user/logout", tokens.Token, refreshBody(tokens.RefreshToken)); w.Code != http.StatusOK {
+		t.Fatalf("logout: status = %d: %s", w.Code, w.Body)
+	}
+	if w := doRequest(r, http.MethodGet, "/user/me", tokens.Token, ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("token de acesso após logout: status = %d, esperado %d", w.Code, http.StatusUnauthorized)
+	}
+	if w := doRequest(r, http.MethodPost, "/user/token/refresh", "", refreshBody(tokens.RefreshToken)); w.Code != http.StatusUnauthorized {
+		t.Errorf("refresh token após logout: status = %d, esperado %d", w.Code, http.StatusUnauthorized)
+	}
+}
+
+// O logout de um login não afeta os demais logins do mesmo usuário.
+func TestLogoutKeepsOtherLogins(t *testing.T) {
+	r := newTestRouter()
+	user := createTestUser(t, models.RoleCliente, models.StatusApproved)
+	first := loginTestUser(t, r, user)
+	second := loginTestUser(t, r, user)
+
+	if w := doRequest(r, http.MethodPost, "/user/logout", first.Token, refreshBody(first.RefreshToken)); w.Code != http.StatusOK {
+		t.Fatalf("logout: status = %d: %s", w.Code, w.Body)
+	}
+	if w := doRequest(r, http.MethodGet, "/user/me", second.Token, ""); w.Code != http.StatusOK {
+		t.Errorf("token de outro login: status = %d, esperado %d", w.Code, http.StatusOK)
+	}
+	if w := doRequest(r, http.MethodPost, "/user/token/refresh", "", refreshBody(second.RefreshToken)); w.Code != http.StatusOK {
+		t.Errorf("refresh token de outro login: status = %d, esperado %d", w.Code, http.StatusOK)
+	}
+}
+
+func TestPasswordChangeRevokesRefreshTokens(t *testing.T) {
+	r := newTestRouter()
+	user := createTestUser(t, models.RoleCliente, models.StatusApproved)
+	tokens := loginTestUser(t, r, user)
+
+	if w := doRequest(r, http.MethodPut, "/user/me/password", tokens.Token, `{"current_password":"`+testPassword+`","new_password":"Nova-senha-123"}`); w.Code != http.StatusOK {
+		t.Fatalf("troca de senha: status = %d: %s", w.Code, w.Body)
+	}
+	if w := doRequest(r, http.MethodPost, "/user/token/refresh", "", refreshBody(tokens.RefreshToken)); w.Code != http.StatusUnauthorized {
+		t.Errorf("refresh token após troca de senha: status = %d, esperado %d", w.Code, http.StatusUnauthorized)
+	}
+
+	var revocation models.UserTokenRevocation
+	if err := database.DB.First(&revocation, "user_id = ?", user.ID).Error; err != nil {
+		t.Errorf("revogação geral não registrada: %v", err)
+	}
+}
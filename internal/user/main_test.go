@@ -0,0 +1,85 @@
+package user
+
+import (
+	"bytes"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+	"user-service/internal/database"
+	"user-service/internal/testutil"
+	"user-service/internal/user/models"
+	"user-service/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Senha dos usuários criados por createTestUser
+const testPassword = "Senha-de-teste-1"
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	cleanup, err := testutil.Setup()
+	if err != nil {
+		fmt.Println("❌ Erro ao preparar os testes:", err)
+		os.Exit(1)
+	}
+	code := m.Run()
+	cleanup()
+	os.Exit(code)
+}
+
+// newTestRouter monta as rotas com a mesma configuração de proxies do main.
+func newTestRouter() *gin.Engine {
+	r := gin.New()
+	if err := r.SetTrustedProxies(utils.GetEnvList("TRUSTED_PROXIES", nil)); err != nil {
+		panic(err)
+	}
+	RegisterRoutes(r)
+	return r
+}
+
+// createTestUser cria um usuário com e-mail único, verificado, no papel e
+// status informados.
+func createTestUser(t *testing.T, role, status string) models.User {
+	t.Helper()
+	now := time.Now()
+	user := models.User{
+		Name:            "Teste " + role,
+		Email:           uuid.NewString() + "@example.com",
+		EmailVerifiedAt: &now,
+		Password:        testPassword,
+		Role:            role,
+		Status:          status,
+		Authorized:      models.IsActiveStatus(status),
+	}
+	if err := user.HashPassword(); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.DB.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+func bearerToken(t *testing.T, user models.User) string {
+	t.Helper()
+	token, err := utils.GenerateJWT(user.ID, user.Role, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func doRequest(r *gin.Engine, method, path, token, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
@@ -0,0 +1,28 @@
+package user
+
+import (
+	"net/http"
+	"user-service/internal/database"
+	"user-service/internal/user/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// targetUserID retorna o ID do usuário alvo da requisição: o parâmetro :id
+// quando presente ou, nas rotas /user/me, o user_id do token.
+func targetUserID(c *gin.Context) string {
+	if id := c.Param("id"); id != "" {
+		return id
+	}
+	return c.GetString("user_id")
+}
+
+func GetCurrentUser(c *gin.Context) {
+	var user models.User
+	if err := database.DB.First(&user, "id = ?", targetUserID(c)).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, newUserResponse(user))
+}
@@ -0,0 +1,462 @@
+package user
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+	"user-service/internal/database"
+	"user-service/internal/loginguard"
+	"user-service/internal/revocation"
+	"user-service/internal/totp"
+	"user-service/internal/user/models"
+	"user-service/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const recoveryCodeCount = 10
+
+// Alfabeto dos códigos de recuperação, sem caracteres ambíguos (0/o, 1/l/i)
+const recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+
+func mfaIssuer() string {
+	if issuer := os.Getenv("MFA_ISSUER"); issuer != "" {
+		return issuer
+	}
+	return "EletriHub"
+}
+
+// mfaRequiredForRole informa se o papel está em MFA_REQUIRED_ROLES (ex.:
+// "admin,instalador"), tornando o MFA obrigatório para esses usuários.
+func mfaRequiredForRole(role string) bool {
+	for _, required := range utils.GetEnvList("MFA_REQUIRED_ROLES", nil) {
+		if role == required {
+			return true
+		}
+	}
+	return false
+}
+
+// verifyTOTP valida o código do aplicativo autenticador e registra o passo
+// usado, de modo que o mesmo código não seja aceito duas vezes.
+func verifyTOTP(user *models.User, code string) bool {
+	if user.MFASecret == "" {
+		return false
+	}
+
+	step, ok := totp.Validate(user.MFASecret, code, time.Now(), 1)
+	if !ok || step <= user.MFALastStep {
+		return false
+	}
+
+	// Atualização condicional para barrar o mesmo código em requisições concorrentes
+	result := database.DB.Model(&models.User{}).
+		Where("id = ? AND mfa_last_step < ?", user.ID, step).
+		Update("mfa_last_step", step)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false
+	}
+
+	user.MFALastStep = step
+	return true
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), " ", ""))
+}
+
+// useRecoveryCode consome um código de recuperação ainda não usado.
+func useRecoveryCode(userID, code string) bool {
+	result := database.DB.Model(&models.MFARecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, utils.HashToken(normalizeRecoveryCode(code))).
+		Update("used_at", time.Now())
+	return result.Error == nil && result.RowsAffected > 0
+}
+
+// verifyMFACode aceita um código TOTP ou, na falta dele, um código de recuperação.
+func verifyMFACode(user *models.User, code, recoveryCode string) bool {
+	if code != "" {
+		return verifyTOTP(user, code)
+	}
+	if recoveryCode != "" {
+		return useRecoveryCode(user.ID, recoveryCode)
+	}
+	return false
+}
+
+// verifyMFAAttempt valida o código MFA de um usuário já autenticado sob o
+// mesmo limite de tentativas do login em duas etapas, respondendo 429 (conta
+// ou IP bloqueados) ou 401 (código inválido) quando a validação falha.
+func verifyMFAAttempt(c *gin.Context, user *models.User, code, recoveryCode string) bool {
+	accountKey := mfaAccountKey(user.ID)
+	if respondIfLocked(c, accountKey, loginguard.IPKey(c.ClientIP())) {
+		return false
+	}
+
+	if !verifyMFACode(user, code, recoveryCode) {
+		registerLoginFailure(c, accountKey)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Código MFA inválido"})
+		return false
+	}
+
+	if err := loginguard.Reset(accountKey); err != nil {
+		fmt.Println("⚠️ Erro ao limpar tentativas de MFA:", err)
+	}
+	return true
+}
+
+func newRecoveryCode() (string, error) {
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	for i := range b {
+		b[i] = recoveryCodeAlphabet[int(b[i])%len(recoveryCodeAlphabet)]
+	}
+	return string(b[:5]) + "-" + string(b[5:]), nil
+}
+
+// generateRecoveryCodes substitui os códigos de recuperação do usuário por um
+// novo conjunto, devolvido em texto puro apenas nesta chamada.
+func generateRecoveryCodes(tx *gorm.DB, userID string) ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	records := make([]models.MFARecoveryCode, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := newRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+		records = append(records, models.MFARecoveryCode{
+			UserID:   userID,
+			CodeHash: utils.HashToken(normalizeRecoveryCode(code)),
+		})
+	}
+
+	if err := tx.Where("user_id = ?", userID).Delete(&models.MFARecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Create(&records).Error; err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// enableMFA ativa o MFA com o segredo pendente e gera os códigos de recuperação.
+func enableMFA(userID string) ([]string, error) {
+	var codes []string
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("id = ?", userID).Update("mfa_enabled", true).Error; err != nil {
+			return err
+		}
+		var err error
+		codes, err = generateRecoveryCodes(tx, userID)
+		return err
+	})
+	return codes, err
+}
+
+// startMFASetup gera um novo segredo pendente de confirmação e responde com a
+// URI otpauth:// para o aplicativo autenticador.
+func startMFASetup(c *gin.Context, user models.User) {
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao gerar segredo MFA"})
+		return
+	}
+
+	if err := database.DB.Model(&user).Updates(map[string]interface{}{
+		"mfa_secret":    secret,
+		"mfa_last_step": 0,
+	}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao salvar segredo MFA"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"secret":      secret,
+		"otpauth_uri": totp.URI(mfaIssuer(), user.Email, secret),
+	})
+}
+
+// parseMFAChallenge valida o token de desafio entregue pelo LoginUser e
+// carrega o usuário correspondente.
+func parseMFAChallenge(c *gin.Context, tokenString string) (*utils.Claims, models.User, bool) {
+	var user models.User
+
+	claims, err := utils.ParseToken(tokenString)
+	if err != nil || claims.Purpose != utils.TokenPurposeMFA {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Desafio MFA inválido ou expirado"})
+		return nil, user, false
+	}
+
+	if revoked, err := revocation.IsRevoked(claims); err != nil || revoked {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Desafio MFA inválido ou expirado"})
+		return nil, user, false
+	}
+
+	if err := database.DB.First(&user, "id = ?", claims.UserID).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Desafio MFA inválido ou expirado"})
+		return nil, user, false
+	}
+
+	if !user.Authorized {
+		c.JSON(http.StatusForbidden, gin.H{"error": statusDeniedMessage(user.Status), "status": user.Status})
+		return nil, user, false
+	}
+
+	return claims, user, true
+}
+
+// LoginMFA conclui o login em duas etapas: troca o desafio MFA e o código TOTP
+// (ou um código de recuperação) pelo token de acesso. Para papéis com MFA
+// obrigatório ainda não cadastrado, o código confirma o cadastro iniciado em
+// /login/mfa/setup e a resposta inclui os códigos de recuperação.
+func LoginMFA(c *gin.Context) {
+	var body struct {
+		MFAToken     string `json:"mfa_token"`
+		Code         string `json:"code"`
+		RecoveryCode string `json:"recovery_code"`
+		DeviceName   string `json:"device_name"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil || body.MFAToken == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "mfa_token é obrigatório"})
+		return
+	}
+
+	claims, user, ok := parseMFAChallenge(c, body.MFAToken)
+	if !ok {
+		return
+	}
+
+	accountKey := mfaAccountKey(user.ID)
+	if respondIfLocked(c, accountKey, loginguard.IPKey(c.ClientIP())) {
+		return
+	}
+
+	var extra gin.H
+	switch {
+	case user.MFAEnabled:
+		if !verifyMFACode(&user, body.Code, body.RecoveryCode) {
+			registerLoginFailure(c, accountKey)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Código MFA inválido"})
+			return
+		}
+	case mfaRequiredForRole(user.Role):
+		if user.MFASecret == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Cadastro de MFA não iniciado (use /user/login/mfa/setup)"})
+			return
+		}
+		if !verifyTOTP(&user, body.Code) {
+			registerLoginFailure(c, accountKey)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Código MFA inválido"})
+			return
+		}
+		codes, err := enableMFA(user.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao ativar MFA"})
+			return
+		}
+		user.MFAEnabled = true
+		extra = gin.H{"recovery_codes": codes}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "MFA não está ativo para este usuário"})
+		return
+	}
+
+	if err := loginguard.Reset(accountKey); err != nil {
+		fmt.Println("⚠️ Erro ao limpar tentativas de MFA:", err)
+	}
+
+	// O desafio é de uso único
+	if err := revocation.RevokeToken(claims.ID, user.ID, claims.ExpiresAt.Time, "mfa_challenge_used"); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao validar desafio MFA"})
+		return
+	}
+
+	respondLogin(c, user, body.DeviceName, extra)
+}
+
+// LoginMFASetup inicia o cadastro obrigatório de MFA durante o login, para
+// usuários cujo papel exige MFA e que ainda não o configuraram.
+func LoginMFASetup(c *gin.Context) {
+	var body struct {
+		MFAToken string `json:"mfa_token"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil || body.MFAToken == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "mfa_token é obrigatório"})
+		return
+	}
+
+	_, user, ok := parseMFAChallenge(c, body.MFAToken)
+	if !ok {
+		return
+	}
+
+	if user.MFAEnabled || !mfaRequiredForRole(user.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cadastro de MFA não disponível neste fluxo"})
+		return
+	}
+
+	startMFASetup(c, user)
+}
+
+// SetupMFA inicia o cadastro voluntário de MFA pelo usuário autenticado.
+func SetupMFA(c *gin.Context) {
+	var user models.User
+	if err := database.DB.First(&user, "id = ?", c.GetString("user_id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	if user.MFAEnabled {
+		c.JSON(http.StatusConflict, gin.H{"error": "MFA já está ativo"})
+		return
+	}
+
+	startMFASetup(c, user)
+}
+
+// ConfirmMFA ativa o MFA após o usuário informar um código gerado com o
+// segredo de SetupMFA e devolve os códigos de recuperação.
+func ConfirmMFA(c *gin.Context) {
+	var body struct {
+		Code string `json:"code"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil || body.Code == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "code é obrigatório"})
+		return
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, "id = ?", c.GetString("user_id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	if user.MFAEnabled {
+		c.JSON(http.StatusConflict, gin.H{"error": "MFA já está ativo"})
+		return
+	}
+	if user.MFASecret == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cadastro de MFA não iniciado"})
+		return
+	}
+
+	if !verifyMFAAttempt(c, &user, body.Code, "") {
+		return
+	}
+
+	codes, err := enableMFA(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao ativar MFA"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "MFA ativado com sucesso",
+		"recovery_codes": codes,
+	})
+}
+
+// DisableMFA desativa o MFA mediante senha e código, exceto para papéis em
+// que ele é obrigatório.
+func DisableMFA(c *gin.Context) {
+	var body struct {
+		Password     string `json:"password"`
+		Code         string `json:"code"`
+		RecoveryCode string `json:"recovery_code"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON"})
+		return
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, "id = ?", c.GetString("user_id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	if mfaRequiredForRole(user.Role) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "MFA é obrigatório para este perfil"})
+		return
+	}
+	if !user.MFAEnabled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "MFA não está ativo"})
+		return
+	}
+
+	accountKey := mfaAccountKey(user.ID)
+	if respondIfLocked(c, accountKey, loginguard.IPKey(c.ClientIP())) {
+		return
+	}
+	if !user.CheckPassword(body.Password) || !verifyMFACode(&user, body.Code, body.RecoveryCode) {
+		registerLoginFailure(c, accountKey)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Senha ou código MFA inválidos"})
+		return
+	}
+	if err := loginguard.Reset(accountKey); err != nil {
+		fmt.Println("⚠️ Erro ao limpar tentativas de MFA:", err)
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Updates(map[string]interface{}{
+			"mfa_enabled":   false,
+			"mfa_secret":    "",
+			"mfa_last_step": 0,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", user.ID).Delete(&models.MFARecoveryCode{}).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao desativar MFA"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "MFA desativado com sucesso"})
+}
+
+// RegenerateRecoveryCodes invalida os códigos de recuperação atuais e gera
+// um novo conjunto, mediante um código TOTP válido.
+func RegenerateRecoveryCodes(c *gin.Context) {
+	var body struct {
+		Code string `json:"code"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil || body.Code == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "code é obrigatório"})
+		return
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, "id = ?", c.GetString("user_id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	if !user.MFAEnabled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "MFA não está ativo"})
+		return
+	}
+	if !verifyMFAAttempt(c, &user, body.Code, "") {
+		return
+	}
+
+	var codes []string
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		codes, err = generateRecoveryCodes(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao gerar códigos de recuperação"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
@@ -0,0 +1,158 @@
+package user
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+	"user-service/internal/totp"
+	"user-service/internal/user/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// totpCode gera o código do segredo deslocado em offset passos do atual.
+func totpCode(t *testing.T, secret string, offset int64) string {
+	t.Helper()
+	code, err := totp.Code(secret, totp.Step(time.Now())+offset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+// enrollMFA ativa o MFA do usuário pelas rotas /user/me/mfa e retorna o
+// segredo e os códigos de recuperação. O código usado é o do passo atual.
+func enrollMFA(t *testing.T, r *gin.Engine, token string) (string, []string) {
+	t.Helper()
+	w := doRequest(r, http.MethodPost, "/user/me/mfa/setup", token, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("setup: status = %d: %s", w.Code, w.Body)
+	}
+	var setup struct {
+		Secret string `json:"secret"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &setup); err != nil || setup.Secret == "" {
+		t.Fatalf("setup sem segredo: %s", w.Body)
+	}
+
+	w = doRequest(r, http.MethodPost, "/user/me/mfa/confirm", token, `{"code":"`+totpCode(t, setup.Secret, 0)+`"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("confirmação: status = %d: %s", w.Code, w.Body)
+	}
+	var confirm struct {
+		RecoveryCodes []string `json:"recovery_codes"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &confirm); err != nil || len(confirm.RecoveryCodes) != recoveryCodeCount {
+		t.Fatalf("confirmação sem códigos de recuperação: %s", w.Body)
+	}
+	return setup.Secret, confirm.RecoveryCodes
+}
+
+// mfaChallenge faz o login com senha e retorna o desafio MFA.
+func mfaChallenge(t *testing.T, r *gin.Engine, user models.User) string {
+	t.Helper()
+	w := doRequest(r, http.MethodPost, "/user/login", "", `{"email":"`+user.Email+`","password":"`+testPassword+`"}`)
+	var body struct {
+		MFARequired bool   `json:"mfa_required"`
+		MFAToken    string `json:"mfa_token"`
+		Token       string `json:"token"`
+	}
+	if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &body) != nil || !body.MFARequired || body.MFAToken == "" {
+		t.Fatalf("login sem desafio MFA: status = %d: %s", w.Code, w.Body)
+	}
+	if body.Token != "" {
+		t.Fatal("login com MFA entregou token de acesso antes do código")
+	}
+	return body.MFAToken
+}
+
+func mfaLoginBody(challenge, field, code string) string {
+	return `{"mfa_token":"` + challenge + `","` + field + `":"` + code + `"}`
+}
+
+func TestMFALoginFlow(t *testing.T) {
+	r := newTestRouter()
+	user := createTestUser(t, models.RoleCliente, models.StatusApproved)
+	secret, codes := enrollMFA(t, r, bearerToken(t, user))
+
+	challenge := mfaChallenge(t, r, user)
+	if w := doRequest(r, http.MethodPost, "/user/login/mfa", "", mfaLoginBody(challenge, "code", "000000")); w.Code != http.StatusUnauthorized {
+		t.Errorf("código errado: status = %d, esperado %d", w.Code, http.StatusUnauthorized)
+	}
+	// O código do passo já usado na confirmação não vale de novo
+	if w := doRequest(r, http.MethodPost, "/user/login/mfa", "", mfaLoginBody(challenge, "code", totpCode(t, secret, 0))); w.Code != http.StatusUnauthorized {
+		t.Errorf("código repetido: status = %d, esperado %d", w.Code, http.StatusUnauthorized)
+	}
+
+	w := doRequest(r, http.MethodPost, "/user/login/mfa", "", mfaLoginBody(challenge, "code", totpCode(t, secret, 1)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("código válido: status = %d: %s", w.Code, w.Body)
+	}
+	decodeTokens(t, w.Body.Bytes())
+
+	// O desafio é de uso único
+	if w := doRequest(r, http.MethodPost, "/user/login/mfa", "", mfaLoginBody(challenge, "recovery_code", codes[0])); w.Code != http.StatusUnauthorized {
+		t.Errorf("desafio reutilizado: status = %d, esperado %d", w.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestMFARecoveryCodeSingleUse(t *testing.T) {
+	r := newTestRouter()
+	user := createTestUser(t, models.RoleCliente, models.StatusApproved)
+	_, codes := enrollMFA(t, r, bearerToken(t, user))
+
+	w := doRequest(r, http.MethodPost, "/user/login/mfa", "", mfaLoginBody(mfaChallenge(t, r, user), "recovery_code", codes[0]))
+	if w.Code != http.StatusOK {
+		t.Fatalf("código de recuperação: status = %d: %s", w.Code, w.Body)
+	}
+	if w := doRequest(r, http.MethodPost, "/user/login/mfa", "", mfaLoginBody(mfaChallenge(t, r, user), "recovery_code", codes[0])); w.Code != http.StatusUnauthorized {
+		t.Errorf("código de recuperação reutilizado: status = %d, esperado %d", w.Code, http.StatusUnauthorized)
+	}
+}
+
+// Com o papel em MFA_REQUIRED_ROLES, o login exige o cadastro do MFA.
+func TestMFARequiredRoleEnrollsAtLogin(t *testing.T) {
+	t.Setenv("MFA_REQUIRED_ROLES", models.RoleAdmin)
+	r := newTestRouter()
+	admin := createTestUser(t, models.RoleAdmin, models.StatusApproved)
+
+	challenge := mfaChallenge(t, r, admin)
+	w := doRequest(r, http.MethodPost, "/user/login/mfa/setup", "", `{"mfa_token":"`+challenge+`"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("setup: status = %d: %s", w.Code, w.Body)
+	}
+	var setup struct {
+		Secret string `json:"secret"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &setup); err != nil {
+		t.Fatal(err)
+	}
+
+	w = doRequest(r, http.MethodPost, "/user/login/mfa", "", mfaLoginBody(challenge, "code", totpCode(t, setup.Secret, 0)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("login: status = %d: %s", w.Code, w.Body)
+	}
+	decodeTokens(t, w.Body.Bytes())
+
+	if w := doRequest(r, http.MethodPost, "/user/me/mfa/disable", bearerToken(t, admin), `{"password":"`+testPassword+`"}`); w.Code != http.StatusForbidden {
+		t.Errorf("desativar MFA obrigatório: status = %d, esperado %d", w.Code, http.StatusForbidden)
+	}
+}
+
+func TestRegenerateRecoveryCodes(t *testing.T) {
+	r := newTestRouter()
+	user := createTestUser(t, models.RoleCliente, models.StatusApproved)
+	token := bearerToken(t, user)
+	secret, old := enrollMFA(t, r, token)
+
+	if w := doRequest(r, http.MethodPost, "/user/me/mfa/recovery-codes", token, `{"code":"000000"}`); w.Code != http.StatusUnauthorized {
+		t.Errorf("código errado: status = %d, esperado %d", w.Code, http.StatusUnauthorized)
+	}
+	if w := doRequest(r, http.MethodPost, "/user/me/mfa/recovery-codes", token, `{"code":"`+totpCode(t, secret, 1)+`"}`); w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body)
+	}
+	if w := doRequest(r, http.MethodPost, "/user/login/mfa", "", mfaLoginBody(mfaChallenge(t, r, user), "recovery_code", old[0])); w.Code != http.StatusUnauthorized {
+		t.Errorf("código de recuperação antigo: status = %d, esperado %d", w.Code, http.StatusUnauthorized)
+	}
+}
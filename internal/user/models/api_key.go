@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Escopos concedidos a chaves de API de serviços internos
+const (
+	ScopeInstallerStatsWrite    = "installers:stats:write"
+	ScopeInstallerBookingsWrite = "installers:bookings:write"
+)
+
+// APIScopes lista os escopos válidos para novas chaves.
+var APIScopes = []string{
+	ScopeInstallerStatsWrite,
+	ScopeInstallerBookingsWrite,
+}
+
+// APIKey é uma credencial de serviço (ex.: backend de pedidos) enviada no
+// cabeçalho X-API-Key. Apenas o hash é persistido; Prefix identifica a chave
+// em listagens sem revelá-la.
+type APIKey struct {
+	ID         string     `json:"id" gorm:"type:text;primaryKey"`
+	Name       string     `json:"name" gorm:"not null"`
+	Prefix     string     `json:"prefix"`
+	KeyHash    string     `json:"-" gorm:"uniqueIndex;not null"`
+	Scopes     []string   `json:"scopes" gorm:"serializer:json"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedBy  string     `json:"created_by" gorm:"type:text"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	LastUsedIP string     `json:"last_used_ip"`
+	UsageCount int64      `json:"usage_count"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func (k *APIKey) BeforeCreate(tx *gorm.DB) (err error) {
+	k.ID = uuid.New().String()
+	return
+}
+
+// HasScope informa se a chave concede o escopo.
+func (k *APIKey) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AuditLog registra ações sensíveis de administradores (ex.: personificação).
+type AuditLog struct {
+	ID           string                 `json:"id" gorm:"type:text;primaryKey"`
+	ActorID      string                 `json:"actor_id" gorm:"type:text;index;not null"`
+	TargetUserID string                 `json:"target_user_id" gorm:"type:text;index"`
+	Action       string                 `json:"action" gorm:"index;not null"`
+	IP           string                 `json:"ip"`
+	UserAgent    string                 `json:"user_agent"`
+	Details      map[string]interface{} `json:"details" gorm:"serializer:json"`
+	CreatedAt    time.Time              `json:"created_at"`
+}
+
+func (a *AuditLog) BeforeCreate(tx *gorm.DB) (err error) {
+	a.ID = uuid.New().String()
+	return
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// WorkingHours é o expediente do instalador em um dia da semana (0 = domingo),
+// com o número máximo de serviços que ele aceita nesse dia. Dias sem registro
+// são folga.
+type WorkingHours struct {
+	ID        string    `json:"-" gorm:"type:text;primaryKey"`
+	UserID    string    `json:"-" gorm:"type:text;uniqueIndex:idx_working_hours_user_weekday;not null"`
+	Weekday   int       `json:"weekday" gorm:"uniqueIndex:idx_working_hours_user_weekday"`
+	Start     string    `json:"start"`
+	End       string    `json:"end"`
+	Capacity  int       `json:"capacity"`
+	CreatedAt time.Time `json:"-"`
+}
+
+func (w *WorkingHours) BeforeCreate(tx *gorm.DB) (err error) {
+	w.ID = uuid.New().String()
+	return
+}
+
+// BlockedDate é um dia em que o instalador não atende (férias, feriado...).
+// Date no formato AAAA-MM-DD.
+type BlockedDate struct {
+	ID        string    `json:"-" gorm:"type:text;primaryKey"`
+	UserID    string    `json:"-" gorm:"type:text;uniqueIndex:idx_blocked_date_user_date;not null"`
+	Date      string    `json:"date" gorm:"uniqueIndex:idx_blocked_date_user_date;not null"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (b *BlockedDate) BeforeCreate(tx *gorm.DB) (err error) {
+	b.ID = uuid.New().String()
+	return
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Booking é um serviço do instalador para um cliente, registrado pelo
+// backend de pedidos (ServiceID único). Um agendamento (Date, AAAA-MM-DD)
+// ocupa uma vaga da capacidade diária do instalador; o serviço é marcado como
+// concluído quando é executado, e só então o cliente pode avaliá-lo.
+type Booking struct {
+	ID          string     `json:"id" gorm:"type:text;primaryKey"`
+	UserID      string     `json:"user_id" gorm:"type:text;index:idx_booking_user_date;not null"`
+	ClientID    string     `json:"client_id" gorm:"type:text;index"`
+	ServiceID   string     `json:"service_id" gorm:"uniqueIndex;not null"`
+	Date        string     `json:"date" gorm:"index:idx_booking_user_date;not null;default:''"`
+	CanceledAt  *time.Time `json:"canceled_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+func (b *Booking) BeforeCreate(tx *gorm.DB) (err error) {
+	b.ID = uuid.New().String()
+	return
+}
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Specialty é um tipo de serviço do catálogo (ex.: wallbox residencial).
+// Code é o identificador estável usado em filtros e no cadastro do instalador.
+type Specialty struct {
+	ID          string    `json:"id" gorm:"type:text;primaryKey"`
+	Code        string    `json:"code" gorm:"uniqueIndex;not null"`
+	Name        string    `json:"name" gorm:"not null"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"-"`
+}
+
+func (s *Specialty) BeforeCreate(tx *gorm.DB) (err error) {
+	s.ID = uuid.New().String()
+	return
+}
+
+// ChargerBrand é um fabricante de carregadores cujo equipamento o instalador
+// pode declarar que instala (com certificação, se houver).
+type ChargerBrand struct {
+	ID        string    `json:"id" gorm:"type:text;primaryKey"`
+	Code      string    `json:"code" gorm:"uniqueIndex;not null"`
+	Name      string    `json:"name" gorm:"not null"`
+	CreatedAt time.Time `json:"-"`
+}
+
+func (b *ChargerBrand) BeforeCreate(tx *gorm.DB) (err error) {
+	b.ID = uuid.New().String()
+	return
+}
+
+// Catálogo inicial, criado na migração se ainda não existir
+var DefaultSpecialties = []Specialty{
+	{Code: "wallbox_residencial", Name: "Wallbox residencial", Description: "Instalação de carregadores AC de parede em residências"},
+	{Code: "carregador_dc", Name: "Carregador rápido DC", Description: "Instalação de carregadores rápidos de corrente contínua"},
+	{Code: "infraestrutura_condominio", Name: "Infraestrutura para condomínios", Description: "Projeto e adequação elétrica de garagens coletivas"},
+	{Code: "integracao_solar", Name: "Integração com energia solar", Description: "Integração do carregador com sistemas fotovoltaicos"},
+}
+
+var DefaultChargerBrands = []ChargerBrand{
+	{Code: "abb", Name: "ABB"},
+	{Code: "byd", Name: "BYD"},
+	{Code: "intelbras", Name: "Intelbras"},
+	{Code: "schneider", Name: "Schneider Electric"},
+	{Code: "siemens", Name: "Siemens"},
+	{Code: "tesla", Name: "Tesla"},
+	{Code: "wallbox", Name: "Wallbox"},
+	{Code: "weg", Name: "WEG"},
+}
+
+// InstallerSpecialty vincula um instalador a uma especialidade, com um
+// documento comprobatório opcional.
+type InstallerSpecialty struct {
+	ID          string    `json:"id" gorm:"type:text;primaryKey"`
+	UserID      string    `json:"user_id" gorm:"type:text;uniqueIndex:idx_installer_specialty;not null"`
+	SpecialtyID string    `json:"specialty_id" gorm:"type:text;uniqueIndex:idx_installer_specialty;not null"`
+	DocumentID  *string   `json:"document_id" gorm:"type:text"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func (s *InstallerSpecialty) BeforeCreate(tx *gorm.DB) (err error) {
+	s.ID = uuid.New().String()
+	return
+}
+
+// InstallerBrand vincula um instalador a uma marca de carregador, com o código
+// da certificação do fabricante e um documento comprobatório opcionais.
+type InstallerBrand struct {
+	ID                string    `json:"id" gorm:"type:text;primaryKey"`
+	UserID            string    `json:"user_id" gorm:"type:text;uniqueIndex:idx_installer_brand;not null"`
+	BrandID           string    `json:"brand_id" gorm:"type:text;uniqueIndex:idx_installer_brand;not null"`
+	CertificationCode string    `json:"certification_code"`
+	DocumentID        *string   `json:"document_id" gorm:"type:text"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+func (b *InstallerBrand) BeforeCreate(tx *gorm.DB) (err error) {
+	b.ID = uuid.New().String()
+	return
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Situações de EmailDelivery.Status
+const (
+	DeliveryPending = "pending"
+	DeliverySent    = "sent"
+	DeliveryFailed  = "failed"
+)
+
+// Tipos de e-mail registrados em EmailDelivery.Kind
+const (
+	EmailKindStatusChange = "status_change"
+)
+
+// EmailDelivery registra o envio de um e-mail transacional: tentativas,
+// último erro e quando foi entregue à API de e-mail.
+type EmailDelivery struct {
+	ID        string     `json:"id" gorm:"type:text;primaryKey"`
+	UserID    string     `json:"user_id" gorm:"type:text;index"`
+	Kind      string     `json:"kind" gorm:"index;not null"`
+	To        string     `json:"to"`
+	Subject   string     `json:"subject"`
+	Status    string     `json:"status" gorm:"index;not null"`
+	Attempts  int        `json:"attempts"`
+	LastError string     `json:"last_error"`
+	SentAt    *time.Time `json:"sent_at"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+func (d *EmailDelivery) BeforeCreate(tx *gorm.DB) (err error) {
+	d.ID = uuid.New().String()
+	return
+}
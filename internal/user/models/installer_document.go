@@ -0,0 +1,69 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Tipos de documento aceitos em InstallerDocument.Type
+const (
+	DocumentIdentidade          = "identidade"
+	DocumentCNH                 = "cnh"
+	DocumentNR10                = "nr10"
+	DocumentCREA                = "crea"
+	DocumentComprovanteEndereco = "comprovante_endereco"
+	// Certificado de fabricante ou curso, usado como comprovação de
+	// especialidades e marcas
+	DocumentCertificado = "certificado"
+)
+
+var DocumentTypes = []string{
+	DocumentIdentidade,
+	DocumentCNH,
+	DocumentNR10,
+	DocumentCREA,
+	DocumentComprovanteEndereco,
+	DocumentCertificado,
+}
+
+// IsDocumentType informa se o tipo de documento é aceito.
+func IsDocumentType(docType string) bool {
+	for _, t := range DocumentTypes {
+		if t == docType {
+			return true
+		}
+	}
+	return false
+}
+
+// Situações da análise em InstallerDocument.Status
+const (
+	DocumentPending  = "pending"
+	DocumentApproved = "approved"
+	DocumentRejected = "rejected"
+)
+
+// InstallerDocument é um documento enviado pelo instalador para análise. O
+// arquivo fica em armazenamento privado e só é acessado por URL pré-assinada.
+type InstallerDocument struct {
+	ID           string     `json:"id" gorm:"type:text;primaryKey"`
+	UserID       string     `json:"user_id" gorm:"type:text;index;not null"`
+	Type         string     `json:"type" gorm:"index;not null"`
+	FileKey      string     `json:"-" gorm:"not null"`
+	FileName     string     `json:"file_name"`
+	ContentType  string     `json:"content_type"`
+	Size         int64      `json:"size"`
+	Status       string     `json:"status" gorm:"default:pending;index"`
+	ReviewReason string     `json:"review_reason"`
+	ReviewedBy   string     `json:"reviewed_by" gorm:"type:text"`
+	ReviewedAt   *time.Time `json:"reviewed_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+func (d *InstallerDocument) BeforeCreate(tx *gorm.DB) (err error) {
+	d.ID = uuid.New().String()
+	return
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Métricas do instalador somadas por evento em InstallerStatsEvent.Metric
+const (
+	MetricServicesAccepted    = "services_accepted"
+	MetricServicesNotExecuted = "services_not_executed"
+)
+
+// InstallerStatsEvent registra cada métrica já somada para um serviço, para
+// que o backend de pedidos possa repetir a chamada sem contar o serviço duas
+// vezes.
+type InstallerStatsEvent struct {
+	ID        string    `json:"id" gorm:"type:text;primaryKey"`
+	UserID    string    `json:"user_id" gorm:"type:text;uniqueIndex:idx_stats_event;not null"`
+	ServiceID string    `json:"service_id" gorm:"uniqueIndex:idx_stats_event;not null"`
+	Metric    string    `json:"metric" gorm:"uniqueIndex:idx_stats_event;not null"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (e *InstallerStatsEvent) BeforeCreate(tx *gorm.DB) (err error) {
+	e.ID = uuid.New().String()
+	return
+}
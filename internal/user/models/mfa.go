@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MFARecoveryCode é um código de recuperação de uso único que substitui o
+// código TOTP quando o usuário perde o aplicativo autenticador. Apenas o hash
+// é persistido.
+type MFARecoveryCode struct {
+	ID        string     `json:"id" gorm:"type:text;primaryKey"`
+	UserID    string     `json:"user_id" gorm:"type:text;index;not null"`
+	CodeHash  string     `json:"-" gorm:"not null"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (r *MFARecoveryCode) BeforeCreate(tx *gorm.DB) (err error) {
+	r.ID = uuid.New().String()
+	return
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// OIDCNonce registra o nonce de um ID token OIDC já aceito, para que o mesmo
+// token não seja usado duas vezes. Hash é o SHA-256 de provedor + nonce; o
+// registro pode ser apagado após ExpiresAt, quando o token já não é aceito.
+type OIDCNonce struct {
+	Hash      string    `gorm:"type:text;primaryKey"`
+	ExpiresAt time.Time `gorm:"index;not null"`
+	CreatedAt time.Time
+}
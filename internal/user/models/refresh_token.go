@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RefreshToken é um token opaco de longa duração trocado por novos tokens de
+// acesso. Apenas o hash do token é persistido. Tokens emitidos a partir do
+// mesmo login compartilham o FamilyID, permitindo revogar toda a cadeia quando
+// um token já rotacionado é reutilizado.
+type RefreshToken struct {
+	ID           string     `json:"id" gorm:"type:text;primaryKey"`
+	UserID       string     `json:"user_id" gorm:"type:text;index;not null"`
+	FamilyID     string     `json:"family_id" gorm:"type:text;index;not null"`
+	TokenHash    string     `json:"-" gorm:"uniqueIndex;not null"`
+	DeviceName   string     `json:"device_name"`
+	UserAgent    string     `json:"user_agent"`
+	IP           string     `json:"ip"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+	RevokedAt    *time.Time `json:"revoked_at"`
+	ReplacedByID string     `json:"-" gorm:"type:text"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+func (t *RefreshToken) BeforeCreate(tx *gorm.DB) (err error) {
+	t.ID = uuid.New().String()
+	return
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Review é a avaliação de um serviço feita pelo cliente ao instalador. Cada
+// serviço (ServiceID, do backend de pedidos) admite uma única avaliação.
+type Review struct {
+	ID          string     `json:"id" gorm:"type:text;primaryKey"`
+	InstallerID string     `json:"installer_id" gorm:"type:text;index;not null"`
+	ClientID    string     `json:"client_id" gorm:"type:text;index;not null"`
+	ServiceID   string     `json:"service_id" gorm:"uniqueIndex;not null"`
+	Rating      int        `json:"rating" gorm:"not null"`
+	Comment     string     `json:"comment"`
+	Reply       string     `json:"reply"`
+	RepliedAt   *time.Time `json:"replied_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+func (r *Review) BeforeCreate(tx *gorm.DB) (err error) {
+	r.ID = uuid.New().String()
+	return
+}
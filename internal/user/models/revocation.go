@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// RevokedToken registra um token de acesso (pelo jti) invalidado antes da
+// expiração, por exemplo no logout. Pode ser removido após ExpiresAt.
+type RevokedToken struct {
+	JTI       string    `json:"jti" gorm:"type:text;primaryKey"`
+	UserID    string    `json:"user_id" gorm:"type:text;index"`
+	Reason    string    `json:"reason"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"index"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// UserTokenRevocation invalida todos os tokens de acesso de um usuário emitidos
+// antes de RevokedAt (exclusão, troca de senha, desautorização...).
+type UserTokenRevocation struct {
+	UserID    string    `json:"user_id" gorm:"type:text;primaryKey"`
+	Reason    string    `json:"reason"`
+	RevokedAt time.Time `json:"revoked_at"`
+}
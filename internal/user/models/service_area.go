@@ -0,0 +1,66 @@
+package models
+
+import (
+	"strings"
+	"time"
+	"user-service/internal/geo"
+
+	"gorm.io/gorm"
+)
+
+// ServiceArea é a área de atendimento de um instalador. O ponto do cliente é
+// atendido se estiver no raio a partir do endereço do instalador, em uma das
+// cidades ou UFs listadas ou dentro do polígono; qualquer critério basta.
+// Cidades podem vir como "Campinas" ou "Campinas/SP".
+type ServiceArea struct {
+	UserID    string           `json:"user_id" gorm:"type:text;primaryKey"`
+	RadiusKm  float64          `json:"radius_km"`
+	Cities    []string         `json:"cities" gorm:"serializer:json"`
+	States    []string         `json:"states" gorm:"serializer:json"`
+	Polygon   geo.MultiPolygon `json:"-" gorm:"serializer:json"`
+	CreatedAt time.Time        `json:"created_at"`
+	UpdatedAt time.Time        `json:"updated_at"`
+
+	// Calculados ao salvar, para que a busca por proximidade filtre os
+	// candidatos no banco: a caixa que contém o polígono (nula sem polígono),
+	// as UFs atendidas (das UFs e das cidades "Cidade/UF") e os nomes
+	// normalizados das cidades sem UF, no formato "|SP|RJ|"
+	PolygonMinLat *float64 `json:"-"`
+	PolygonMaxLat *float64 `json:"-"`
+	PolygonMinLng *float64 `json:"-"`
+	PolygonMaxLng *float64 `json:"-"`
+	PlaceUFs      string   `json:"-" gorm:"column:place_ufs;not null;default:''"`
+	PlaceCities   string   `json:"-" gorm:"not null;default:''"`
+}
+
+// ServiceAreaIndexColumns são as colunas calculadas por BeforeSave, a incluir
+// em upserts.
+var ServiceAreaIndexColumns = []string{"polygon_min_lat", "polygon_max_lat", "polygon_min_lng", "polygon_max_lng", "place_ufs", "place_cities"}
+
+func (a *ServiceArea) BeforeSave(tx *gorm.DB) (err error) {
+	a.PolygonMinLat, a.PolygonMaxLat, a.PolygonMinLng, a.PolygonMaxLng = nil, nil, nil, nil
+	if minLat, maxLat, minLng, maxLng, ok := a.Polygon.Bounds(); ok {
+		a.PolygonMinLat, a.PolygonMaxLat, a.PolygonMinLng, a.PolygonMaxLng = &minLat, &maxLat, &minLng, &maxLng
+	}
+
+	var ufs, cities []string
+	ufs = append(ufs, a.States...)
+	for _, entry := range a.Cities {
+		name, state, hasState := strings.Cut(entry, "/")
+		if hasState {
+			ufs = append(ufs, strings.ToUpper(state))
+		} else {
+			cities = append(cities, geo.NormalizeName(name))
+		}
+	}
+	a.PlaceUFs, a.PlaceCities = placeList(ufs), placeList(cities)
+	return
+}
+
+// placeList junta os valores como "|a|b|", para filtrar com LIKE '%|a|%'.
+func placeList(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return "|" + strings.Join(values, "|") + "|"
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// Session representa um login em um dispositivo. O ID é o mesmo FamilyID dos
+// refresh tokens emitidos a partir desse login e vai no claim "sid" dos
+// tokens de acesso, permitindo encerrar a sessão remotamente.
+type Session struct {
+	ID         string     `json:"id" gorm:"type:text;primaryKey"`
+	UserID     string     `json:"user_id" gorm:"type:text;index;not null"`
+	DeviceName string     `json:"device_name"`
+	UserAgent  string     `json:"user_agent"`
+	IP         string     `json:"ip"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+}
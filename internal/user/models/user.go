@@ -8,35 +8,48 @@ import (
 	"gorm.io/gorm"
 )
 
+// Papéis aceitos em User.Role
+const (
+	RoleCliente    = "cliente"
+	RoleInstalador = "instalador"
+	RoleAdmin      = "admin"
+)
+
 type User struct {
-	ID                    string    `json:"id" gorm:"type:text;primaryKey"`
-	Name                  string    `json:"name"`
-	Email                 string    `json:"email" gorm:"unique"`
-	Password              string    `json:"password"`
-	Phone                 string    `json:"phone"`
-	CPF                   string    `json:"cpf"`
-	CNPJ                  string    `json:"cnpj"`
-	CompanyName           string    `json:"company_name"`
-	Street                string    `json:"street"`
-	Number                string    `json:"number"`
-	Neighborhood          string    `json:"neighborhood"`
-	City                  string    `json:"city"`
-	State                 string    `json:"state"`
-	Complement            string    `json:"complement"`
-	CEP                   string    `json:"cep"`
-	Latitude              float64   `json:"latitude"`
-	Longitude             float64   `json:"longitude"`
-	BirthDate             string    `json:"birth_date"`
-	Reference             string    `json:"reference"`
-	AceptTerms            bool      `json:"accept_terms"`
-	Role                  string    `json:"role"`
-	Authorized            bool      `json:"authorized" gorm:"default:false"`
-	AverageRating         float64   `json:"average_rating"`
-	TotalServicesAccepted int       `json:"total_services_accepted"`
-	ServicesNotExecuted   int       `json:"services_not_executed"`
-	Photo                 string    `json:"photo"`
-	CreatedAt             time.Time `json:"-"`
-	UpdatedAt             time.Time `json:"-"`
+	ID                    string     `json:"id" gorm:"type:text;primaryKey"`
+	Name                  string     `json:"name"`
+	Email                 string     `json:"email" gorm:"unique"`
+	EmailVerifiedAt       *time.Time `json:"email_verified_at"`
+	Password              string     `json:"password"`
+	Phone                 string     `json:"phone"`
+	CPF                   string     `json:"cpf"`
+	CNPJ                  string     `json:"cnpj"`
+	CompanyName           string     `json:"company_name"`
+	Street                string     `json:"street"`
+	Number                string     `json:"number"`
+	Neighborhood          string     `json:"neighborhood"`
+	City                  string     `json:"city"`
+	State                 string     `json:"state"`
+	Complement            string     `json:"complement"`
+	CEP                   string     `json:"cep"`
+	Latitude              float64    `json:"latitude"`
+	Longitude             float64    `json:"longitude"`
+	BirthDate             string     `json:"birth_date"`
+	Reference             string     `json:"reference"`
+	AceptTerms            bool       `json:"accept_terms"`
+	Role                  string     `json:"role"`
+	Authorized            bool       `json:"authorized" gorm:"default:false"`
+	Status                string     `json:"status" gorm:"default:pending;index"`
+	AverageRating         float64    `json:"average_rating"`
+	ReviewCount           int        `json:"review_count"`
+	TotalServicesAccepted int        `json:"total_services_accepted"`
+	ServicesNotExecuted   int        `json:"services_not_executed"`
+	Photo                 string     `json:"photo"`
+	MFAEnabled            bool       `json:"-" gorm:"default:false"`
+	MFASecret             string     `json:"-"`
+	MFALastStep           int64      `json:"-"`
+	CreatedAt             time.Time  `json:"-"`
+	UpdatedAt             time.Time  `json:"-"`
 }
 
 func (u *User) BeforeCreate(tx *gorm.DB) (err error) {
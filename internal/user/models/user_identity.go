@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UserIdentity vincula um usuário a uma conta de provedor externo (OIDC),
+// identificada pelo par provedor + sub.
+type UserIdentity struct {
+	ID          string    `json:"id" gorm:"type:text;primaryKey"`
+	UserID      string    `json:"user_id" gorm:"type:text;index;not null"`
+	Provider    string    `json:"provider" gorm:"uniqueIndex:idx_identity_provider_subject;not null"`
+	Subject     string    `json:"-" gorm:"uniqueIndex:idx_identity_provider_subject;not null"`
+	Email       string    `json:"email"`
+	CreatedAt   time.Time `json:"created_at"`
+	LastLoginAt time.Time `json:"last_login_at"`
+}
+
+func (i *UserIdentity) BeforeCreate(tx *gorm.DB) (err error) {
+	i.ID = uuid.New().String()
+	return
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Situações do cadastro em User.Status
+const (
+	StatusPending    = "pending"
+	StatusApproved   = "approved"
+	StatusRejected   = "rejected"
+	StatusSuspended  = "suspended"
+	StatusReinstated = "reinstated"
+)
+
+// statusTransitions lista, para cada situação, as situações de destino permitidas.
+var statusTransitions = map[string][]string{
+	StatusPending:    {StatusApproved, StatusRejected},
+	StatusRejected:   {StatusApproved},
+	StatusApproved:   {StatusSuspended},
+	StatusSuspended:  {StatusReinstated, StatusRejected},
+	StatusReinstated: {StatusSuspended},
+}
+
+// CanTransition informa se a mudança de situação é permitida.
+func CanTransition(from, to string) bool {
+	for _, allowed := range statusTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsActiveStatus informa se a situação permite acesso (User.Authorized).
+func IsActiveStatus(status string) bool {
+	return status == StatusApproved || status == StatusReinstated
+}
+
+// UserStatusHistory registra cada mudança de situação: quem, quando e por quê.
+type UserStatusHistory struct {
+	ID         string    `json:"id" gorm:"type:text;primaryKey"`
+	UserID     string    `json:"user_id" gorm:"type:text;index;not null"`
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status" gorm:"not null"`
+	Reason     string    `json:"reason"`
+	ChangedBy  string    `json:"changed_by" gorm:"type:text"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (h *UserStatusHistory) BeforeCreate(tx *gorm.DB) (err error) {
+	h.ID = uuid.New().String()
+	return
+}
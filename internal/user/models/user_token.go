@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Finalidades de UserToken
+const (
+	TokenPurposePasswordReset     = "password_reset"
+	TokenPurposeEmailVerification = "email_verification"
+)
+
+// UserToken é um token de uso único enviado ao usuário por e-mail. Apenas o
+// hash é persistido.
+type UserToken struct {
+	ID        string     `json:"id" gorm:"type:text;primaryKey"`
+	UserID    string     `json:"user_id" gorm:"type:text;index;not null"`
+	Purpose   string     `json:"purpose" gorm:"index;not null"`
+	TokenHash string     `json:"-" gorm:"uniqueIndex;not null"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (t *UserToken) BeforeCreate(tx *gorm.DB) (err error) {
+	t.ID = uuid.New().String()
+	return
+}
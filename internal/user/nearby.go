@@ -1,25 +1,36 @@
 package user
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"math"
 	"net/http"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 	"user-service/internal/database"
+	"user-service/internal/geo"
 	"user-service/internal/user/models"
+	"user-service/internal/utils"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-func calcularDistanciaKm(lat1, lon1, lat2, lon2 float64) float64 {
-	const R = 6371
-	dLat := (lat2 - lat1) * math.Pi / 180
-	dLon := (lon2 - lon1) * math.Pi / 180
-	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
-		math.Cos(lat1*math.Pi/180)*math.Cos(lat2*math.Pi/180)*math.Sin(dLon/2)*math.Sin(dLon/2)
-	c := 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
-	return R * c
-}
-
+// ListNearbyInstallers lista os instaladores cuja área de atendimento cobre o
+// ponto informado (lat, lng). city e state são opcionais e evitam a
+// geocodificação reversa para áreas definidas por cidade ou UF; specialty e
+// brand filtram por especialidades e marcas; available_on (AAAA-MM-DD) exclui
+// quem está de folga ou sem vagas no dia. Cada resultado traz distance_km e o
+// score descrito em ranking.go; sort escolhe a ordem (distance, rating ou
+// score, o padrão). radius_km (limitado por NEARBY_MAX_RADIUS_KM) restringe a
+// busca a quem mora a essa distância do ponto; sem ele, são avaliados no
+// máximo NEARBY_MAX_CANDIDATES instaladores, os mais próximos. A resposta é
+// paginada por limit e cursor: {items, total, next_cursor}; o cursor marca o
+// último item entregue (chave de ordenação e ID), não uma posição.
 func ListNearbyInstallers(c *gin.Context) {
 	lat := c.Query("lat")
 	lng := c.Query("lng")
@@ -32,37 +43,290 @@ func ListNearbyInstallers(c *gin.Context) {
 		return
 	}
 
+	sortBy := c.DefaultQuery("sort", sortByScore)
+	if !validNearbySort(sortBy) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "sort deve ser distance, rating ou score"})
+		return
+	}
+
+	var radiusKm float64
+	if value := c.Query("radius_km"); value != "" {
+		radius, err := strconv.ParseFloat(value, 64)
+		if err != nil || radius <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "radius_km deve ser um número positivo"})
+			return
+		}
+		radiusKm = math.Min(radius, maxNearbyRadiusKm())
+	}
+
+	limit, after, err := nearbyPage(c, sortBy)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	loc := &clientLocation{
+		Lat:  latF,
+		Lng:  lngF,
+		City: strings.TrimSpace(c.Query("city")),
+		UF:   strings.ToUpper(strings.TrimSpace(c.Query("state"))),
+	}
+
 	var users []models.User
-	if err := database.DB.
-		Where(&models.User{Role: "instalador", Authorized: true}).
-		Find(&users).Error; err != nil {
+	query := serviceAreaCandidates(database.DB.Where(&models.User{Role: "instalador", Authorized: true}), loc)
+	if radiusKm > 0 {
+		// Pré-filtro pela caixa que contém o círculo; a distância exata é
+		// conferida abaixo
+		minLat, maxLat, minLng, maxLng := geo.BoundingBox(latF, lngF, radiusKm)
+		query = query.Where("users.latitude BETWEEN ? AND ? AND users.longitude BETWEEN ? AND ?", minLat, maxLat, minLng, maxLng)
+	} else {
+		// Sem raio, áreas por UF ou polígono podem casar com muitos
+		// instaladores: ficam os mais próximos, até o limite de candidatos
+		query = nearestCandidates(query, latF, lngF, maxNearbyCandidates())
+	}
+	if err := applyQualificationFilters(query, c).Find(&users).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar instaladores"})
 		return
 	}
 
-	const limiteKm = 150.0
-	var proximos []UserInstalerResponse
+	ids := make([]string, 0, len(users))
+	for _, user := range users {
+		ids = append(ids, user.ID)
+	}
+	var areas []models.ServiceArea
+	if err := database.DB.Where("user_id IN ?", ids).Find(&areas).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar áreas de atendimento"})
+		return
+	}
+	areaByUser := make(map[string]*models.ServiceArea, len(areas))
+	for i := range areas {
+		areaByUser[areas[i].UserID] = &areas[i]
+	}
 
+	var cobertos []models.User
 	for _, user := range users {
-		if user.Latitude == 0 && user.Longitude == 0 {
+		if radiusKm > 0 && geo.DistanceKm(latF, lngF, user.Latitude, user.Longitude) > radiusKm {
 			continue
 		}
-		dist := calcularDistanciaKm(latF, lngF, user.Latitude, user.Longitude)
-		if dist <= limiteKm {
-			proximos = append(proximos, UserInstalerResponse{
-				ID:                    user.ID,
-				Name:                  user.Name,
-				CompanyName:           user.CompanyName,
-				AverageRating:         user.AverageRating,
-				TotalServicesAccepted: user.TotalServicesAccepted,
-				ServicesNotExecuted:   user.ServicesNotExecuted,
-				Role:                  user.Role,
-				Photo:                 user.Photo,
-				Phone:                 user.Phone,
-				State:                 user.State,
-			})
+		if serviceAreaCovers(areaByUser[user.ID], user, loc) {
+			cobertos = append(cobertos, user)
+		}
+	}
+
+	if value := c.Query("available_on"); value != "" {
+		date, err := parseAgendaDate(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if cobertos, err = filterAvailable(cobertos, date); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao verificar disponibilidade"})
+			return
+		}
+	}
+
+	cfg := loadRankingConfig()
+	proximos := make([]NearbyInstallerResponse, 0, len(cobertos))
+	byID := make(map[string]models.User, len(cobertos))
+	for _, user := range cobertos {
+		byID[user.ID] = user
+		item := NearbyInstallerResponse{
+			UserInstalerResponse: UserInstalerResponse{ID: user.ID, AverageRating: user.AverageRating, ReviewCount: user.ReviewCount},
+			ReliabilityScore:     round(reliabilityScore(user, cfg), 3),
+		}
+		if user.Latitude != 0 || user.Longitude != 0 {
+			distance := round(geo.DistanceKm(latF, lngF, user.Latitude, user.Longitude), 2)
+			item.DistanceKm = &distance
 		}
+		item.Score = round(installerScore(user, item.DistanceKm, cfg), 4)
+		proximos = append(proximos, item)
 	}
+	sortNearby(proximos, sortBy)
 
-	c.JSON(http.StatusOK, proximos)
+	// Pagina antes de carregar especialidades e marcas, só para a página atual.
+	// A página começa no primeiro resultado depois do último já entregue.
+	total := len(proximos)
+	start := 0
+	if after != nil {
+		last := after.item()
+		start = sort.Search(total, func(i int) bool { return nearbyLess(last, proximos[i], sortBy) })
+	}
+	end := min(start+limit, total)
+	page := proximos[start:end]
+
+	pageUsers := make([]models.User, 0, len(page))
+	for _, item := range page {
+		pageUsers = append(pageUsers, byID[item.ID])
+	}
+	responses, err := installerResponses(pageUsers)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar instaladores"})
+		return
+	}
+	for i := range page {
+		page[i].UserInstalerResponse = responses[i]
+	}
+
+	var nextCursor *string
+	if end < total {
+		cursor := newNearbyCursor(page[len(page)-1], sortBy).encode()
+		nextCursor = &cursor
+	}
+
+	response := gin.H{
+		"items":       page,
+		"total":       total,
+		"next_cursor": nextCursor,
+	}
+	if radiusKm > 0 {
+		response["radius_km"] = radiusKm
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// maxNearbyRadiusKm limita o radius_km aceito na busca
+// (NEARBY_MAX_RADIUS_KM, padrão 300 km).
+func maxNearbyRadiusKm() float64 {
+	if radius := utils.GetEnvFloat("NEARBY_MAX_RADIUS_KM", 300); radius > 0 {
+		return radius
+	}
+	return 300
+}
+
+// maxNearbyCandidates limita quantos instaladores a busca sem radius_km
+// avalia (NEARBY_MAX_CANDIDATES, padrão 500).
+func maxNearbyCandidates() int {
+	if n := utils.GetEnvInt("NEARBY_MAX_CANDIDATES", 500); n > 0 {
+		return n
+	}
+	return 500
+}
+
+// nearestCandidates ordena a consulta pela distância aproximada (plana, com a
+// longitude corrigida pela latitude) até o ponto e mantém os limit primeiros.
+func nearestCandidates(query *gorm.DB, lat, lng float64, limit int) *gorm.DB {
+	latPerKm, lngPerKm := geo.DegreesPerKm(lat)
+	lngScale := latPerKm / lngPerKm
+	return query.
+		Clauses(clause.OrderBy{Expression: clause.Expr{
+			SQL:                "(users.latitude - ?) * (users.latitude - ?) + (users.longitude - ?) * (users.longitude - ?) * ?, users.id",
+			Vars:               []interface{}{lat, lat, lng, lng, lngScale * lngScale},
+			WithoutParentheses: true,
+		}}).
+		Limit(limit)
+}
+
+// nearbyPage lê limit (padrão NEARBY_DEFAULT_LIMIT, 20; máximo
+// NEARBY_MAX_LIMIT, 100) e o cursor devolvido como next_cursor pela página
+// anterior, que precisa ter sido gerado com a mesma ordenação.
+func nearbyPage(c *gin.Context, sortBy string) (int, *nearbyCursor, error) {
+	maxLimit := utils.GetEnvInt("NEARBY_MAX_LIMIT", 100)
+	limit := utils.GetEnvInt("NEARBY_DEFAULT_LIMIT", 20)
+	if value := c.Query("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			return 0, nil, errors.New("limit deve ser um inteiro positivo")
+		}
+		limit = n
+	}
+	limit = max(min(limit, maxLimit), 1)
+
+	value := c.Query("cursor")
+	if value == "" {
+		return limit, nil, nil
+	}
+	cursor, err := decodeNearbyCursor(value)
+	if err != nil || cursor.Sort != sortBy {
+		return 0, nil, errors.New("cursor inválido para esta busca")
+	}
+	return limit, cursor, nil
+}
+
+// nearbyCursor identifica o último resultado entregue pela chave de ordenação
+// e pelo ID. A página seguinte continua logo depois dele, então instaladores
+// que entram ou saem da busca entre uma página e outra não fazem os demais
+// se repetirem ou serem pulados.
+type nearbyCursor struct {
+	Sort        string   `json:"s"`
+	ID          string   `json:"id"`
+	DistanceKm  *float64 `json:"d,omitempty"`
+	Rating      float64  `json:"r,omitempty"`
+	ReviewCount int      `json:"n,omitempty"`
+	Score       float64  `json:"sc,omitempty"`
+}
+
+func newNearbyCursor(item NearbyInstallerResponse, sortBy string) nearbyCursor {
+	return nearbyCursor{
+		Sort:        sortBy,
+		ID:          item.ID,
+		DistanceKm:  item.DistanceKm,
+		Rating:      item.AverageRating,
+		ReviewCount: item.ReviewCount,
+		Score:       item.Score,
+	}
+}
+
+// item reconstrói as chaves de ordenação do resultado para comparar com nearbyLess.
+func (cur nearbyCursor) item() NearbyInstallerResponse {
+	return NearbyInstallerResponse{
+		UserInstalerResponse: UserInstalerResponse{ID: cur.ID, AverageRating: cur.Rating, ReviewCount: cur.ReviewCount},
+		DistanceKm:           cur.DistanceKm,
+		Score:                cur.Score,
+	}
+}
+
+// encode gera o cursor opaco entregue ao cliente.
+func (cur nearbyCursor) encode() string {
+	raw, _ := json.Marshal(cur)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeNearbyCursor(value string) (*nearbyCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	var cursor nearbyCursor
+	if err := json.Unmarshal(raw, &cursor); err != nil {
+		return nil, err
+	}
+	if cursor.ID == "" || !validNearbySort(cursor.Sort) {
+		return nil, errors.New("cursor inválido")
+	}
+	return &cursor, nil
+}
+
+// NearbyInstallerResponse é um resultado da busca por proximidade, com a
+// distância até o ponto pesquisado e os scores usados no ranking.
+type NearbyInstallerResponse struct {
+	UserInstalerResponse
+	DistanceKm       *float64 `json:"distance_km"`
+	ReliabilityScore float64  `json:"reliability_score"`
+	Score            float64  `json:"score"`
+}
+
+func round(value float64, places int) float64 {
+	factor := math.Pow(10, float64(places))
+	return math.Round(value*factor) / factor
+}
+
+// filterAvailable mantém apenas os instaladores disponíveis na data.
+func filterAvailable(users []models.User, date time.Time) ([]models.User, error) {
+	ids := make([]string, 0, len(users))
+	for _, user := range users {
+		ids = append(ids, user.ID)
+	}
+
+	availability, err := availabilityOn(database.DB, ids, date)
+	if err != nil {
+		return nil, err
+	}
+
+	var available []models.User
+	for _, user := range users {
+		if availability[user.ID].Available {
+			available = append(available, user)
+		}
+	}
+	return available, nil
 }
@@ -0,0 +1,151 @@
+package user
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"testing"
+)
+
+func nearbyItem(id string, score float64, distance *float64) NearbyInstallerResponse {
+	item := NearbyInstallerResponse{Score: score, DistanceKm: distance}
+	item.ID = id
+	return item
+}
+
+// Percorre todas as páginas a partir dos cursores e confere que cada
+// resultado aparece exatamente uma vez, na ordem.
+func TestNearbyCursorPagesThroughAllItems(t *testing.T) {
+	items := []NearbyInstallerResponse{
+		nearbyItem("a", 0.9, km(10)),
+		nearbyItem("b", 0.9, km(10)),
+		nearbyItem("c", 0.9, nil),
+		nearbyItem("d", 0.5, km(3)),
+		nearbyItem("e", 0.7, km(40)),
+	}
+	sortNearby(items, sortByScore)
+
+	var seen []string
+	var after *nearbyCursor
+	for page := 0; page < 10; page++ {
+		start := 0
+		if after != nil {
+			last := after.item()
+			start = sort.Search(len(items), func(i int) bool { return nearbyLess(last, items[i], sortByScore) })
+		}
+		end := min(start+2, len(items))
+		for _, item := range items[start:end] {
+			seen = append(seen, item.ID)
+		}
+		if end == len(items) {
+			break
+		}
+		cursor, err := decodeNearbyCursor(newNearbyCursor(items[end-1], sortByScore).encode())
+		if err != nil {
+			t.Fatal(err)
+		}
+		after = cursor
+	}
+
+	want := []string{"a", "b", "c", "e", "d"}
+	if len(seen) != len(want) {
+		t.Fatalf("resultados = %v, esperado %v", seen, want)
+	}
+	for i := range want {
+		if seen[i] != want[i] {
+			t.Fatalf("resultados = %v, esperado %v", seen, want)
+		}
+	}
+}
+
+// Um instalador removido entre as páginas não desloca os seguintes.
+func TestNearbyCursorSurvivesRemovedItem(t *testing.T) {
+	items := []NearbyInstallerResponse{
+		nearbyItem("a", 0.9, km(1)),
+		nearbyItem("b", 0.8, km(1)),
+		nearbyItem("c", 0.7, km(1)),
+	}
+	after := newNearbyCursor(items[1], sortByScore).item()
+
+	remaining := []NearbyInstallerResponse{items[0], items[2]}
+	start := sort.Search(len(remaining), func(i int) bool { return nearbyLess(after, remaining[i], sortByScore) })
+	if remaining[start].ID != "c" {
+		t.Fatalf("página seguinte começa em %q, esperado c", remaining[start].ID)
+	}
+}
+
+func TestDecodeNearbyCursorRejectsInvalid(t *testing.T) {
+	for _, value := range []string{
+		"%%%",
+		base64.RawURLEncoding.EncodeToString([]byte("o:9223372036854775807")),
+		base64.RawURLEncoding.EncodeToString([]byte(`{"s":"score"}`)),
+		base64.RawURLEncoding.EncodeToString([]byte(`{"s":"name","id":"x"}`)),
+	} {
+		if _, err := decodeNearbyCursor(value); err == nil {
+			t.Errorf("cursor %q aceito", value)
+		}
+	}
+}
+
+// As páginas da busca, seguidas pelo cursor, trazem cada instalador uma vez.
+func TestNearbyPagination(t *testing.T) {
+	r := newTestRouter()
+	// Ponto isolado, longe dos instaladores criados por outros testes
+	want := make(map[string]bool)
+	for i := 0; i < 5; i++ {
+		want[createTestInstaller(t, -5+float64(i)*0.01, -70, nil).ID] = true
+	}
+
+	w := doRequest(r, http.MethodGet, "/user/public/installers/nearby?lat=-5&lng=-70&limit=2", "", "")
+	var page struct {
+		Items      []NearbyInstallerResponse `json:"items"`
+		Total      int                       `json:"total"`
+		NextCursor *string                   `json:"next_cursor"`
+	}
+	if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &page) != nil || len(page.Items) != 2 || page.Total != 5 || page.NextCursor == nil {
+		t.Fatalf("primeira página: status = %d: %s", w.Code, w.Body)
+	}
+
+	seen := make(map[string]bool)
+	for _, item := range nearbyResults(t, r, "lat=-5&lng=-70&limit=2&sort=distance") {
+		if seen[item.ID] || !want[item.ID] {
+			t.Errorf("resultado repetido ou inesperado: %s", item.ID)
+		}
+		seen[item.ID] = true
+	}
+	if len(seen) != len(want) {
+		t.Errorf("resultados = %d, esperado %d", len(seen), len(want))
+	}
+
+	for name, query := range map[string]string{
+		"limit inválido":        "limit=0",
+		"radius_km inválido":    "radius_km=-1",
+		"cursor inválido":       "cursor=%25%25",
+		"cursor de outra ordem": "sort=rating&cursor=" + *page.NextCursor,
+	} {
+		if w := doRequest(r, http.MethodGet, "/user/public/installers/nearby?lat=-5&lng=-70&"+query, "", ""); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, esperado %d", name, w.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+// radius_km restringe a quem mora perto do ponto; sem ele, a busca avalia só
+// os NEARBY_MAX_CANDIDATES instaladores mais próximos.
+func TestNearbyRadiusAndCandidateCap(t *testing.T) {
+	r := newTestRouter()
+	near := createTestInstaller(t, -7, -65.01, nil)
+	middle := createTestInstaller(t, -7, -65.2, nil)
+	far := createTestInstaller(t, -7, -65.6, nil)
+
+	ids := nearbyIDs(t, r, "lat=-7&lng=-65&radius_km=30")
+	if !ids[near.ID] || !ids[middle.ID] || ids[far.ID] {
+		t.Errorf("radius_km=30: perto = %v, meio = %v, longe = %v", ids[near.ID], ids[middle.ID], ids[far.ID])
+	}
+
+	t.Setenv("NEARBY_MAX_CANDIDATES", "2")
+	ids = nearbyIDs(t, r, "lat=-7&lng=-65")
+	if !ids[near.ID] || !ids[middle.ID] || ids[far.ID] {
+		t.Errorf("limite de candidatos: perto = %v, meio = %v, longe = %v", ids[near.ID], ids[middle.ID], ids[far.ID])
+	}
+}
@@ -0,0 +1,90 @@
+package user
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+	"user-service/internal/database"
+	"user-service/internal/email"
+	"user-service/internal/user/models"
+	"user-service/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// deliverEmail envia a mensagem registrando a entrega em EmailDelivery. Falhas
+// são repetidas até EMAIL_MAX_ATTEMPTS vezes (padrão 3), com espera crescente
+// a partir de EMAIL_RETRY_DELAY (padrão 2s). Bloqueia até o fim das tentativas;
+// chamar em goroutine.
+func deliverEmail(userID, kind string, msg email.Message) {
+	delivery := models.EmailDelivery{
+		UserID:  userID,
+		Kind:    kind,
+		To:      msg.To,
+		Subject: msg.Subject,
+		Status:  models.DeliveryPending,
+	}
+	if err := database.DB.Create(&delivery).Error; err != nil {
+		fmt.Println("⚠️ Erro ao registrar envio de e-mail:", err)
+	}
+
+	maxAttempts := utils.GetEnvInt("EMAIL_MAX_ATTEMPTS", 3)
+	delay := utils.GetEnvDuration("EMAIL_RETRY_DELAY", 2*time.Second)
+
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		err := email.Send(msg)
+
+		updates := map[string]interface{}{"attempts": attempt}
+		if err == nil {
+			updates["status"] = models.DeliverySent
+			updates["sent_at"] = time.Now()
+			updates["last_error"] = ""
+		} else {
+			updates["last_error"] = err.Error()
+			if attempt == maxAttempts {
+				updates["status"] = models.DeliveryFailed
+			}
+		}
+		if delivery.ID != "" {
+			if dbErr := database.DB.Model(&delivery).Updates(updates).Error; dbErr != nil {
+				fmt.Println("⚠️ Erro ao atualizar registro de envio de e-mail:", dbErr)
+			}
+		}
+
+		if err == nil {
+			return
+		}
+		fmt.Printf("⚠️ Falha ao enviar e-mail %s (tentativa %d/%d): %v\n", kind, attempt, maxAttempts, err)
+		if attempt < maxAttempts {
+			time.Sleep(delay * time.Duration(1<<(attempt-1)))
+		}
+	}
+}
+
+// notifyStatusChange avisa o usuário sobre a nova situação do cadastro.
+func notifyStatusChange(user models.User, reason string) {
+	msg, err := email.StatusChangeMessage(user.Email, email.StatusChangeData{
+		Name:   user.Name,
+		Status: user.Status,
+		Reason: reason,
+	})
+	if err != nil {
+		fmt.Println("⚠️ Erro ao montar e-mail de mudança de status:", err)
+		return
+	}
+	deliverEmail(user.ID, models.EmailKindStatusChange, msg)
+}
+
+// ListEmailDeliveries lista os e-mails transacionais enviados ao usuário e a
+// situação de cada envio.
+func ListEmailDeliveries(c *gin.Context) {
+	var deliveries []models.EmailDelivery
+	if err := database.DB.Where("user_id = ?", c.Param("id")).
+		Order("created_at DESC").
+		Find(&deliveries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao listar envios de e-mail"})
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
@@ -0,0 +1,98 @@
+package user
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"user-service/internal/database"
+	"user-service/internal/email"
+	"user-service/internal/user/models"
+
+	"github.com/google/uuid"
+)
+
+// failingEmailAPI simula a API de e-mail recusando as primeiras failures
+// chamadas e retorna o contador de chamadas recebidas.
+func failingEmailAPI(t *testing.T, failures int32) *int32 {
+	t.Helper()
+	var calls int32
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) <= failures {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(api.Close)
+	t.Setenv("EMAIL_API_URL", api.URL)
+	t.Setenv("EMAIL_MAX_ATTEMPTS", "3")
+	t.Setenv("EMAIL_RETRY_DELAY", "1ms")
+	return &calls
+}
+
+func deliveryFor(t *testing.T, userID string) models.EmailDelivery {
+	t.Helper()
+	var delivery models.EmailDelivery
+	if err := database.DB.First(&delivery, "user_id = ?", userID).Error; err != nil {
+		t.Fatal(err)
+	}
+	return delivery
+}
+
+func TestDeliverEmailRetries(t *testing.T) {
+	calls := failingEmailAPI(t, 2)
+	userID := uuid.NewString()
+
+	deliverEmail(userID, models.EmailKindStatusChange, email.Message{To: "ana@example.com", Subject: "Assunto"})
+
+	delivery := deliveryFor(t, userID)
+	if delivery.Status != models.DeliverySent || delivery.Attempts != 3 || delivery.SentAt == nil || delivery.LastError != "" {
+		t.Errorf("envio = %+v, esperado entregue na terceira tentativa", delivery)
+	}
+	if got := atomic.LoadInt32(calls); got != 3 {
+		t.Errorf("chamadas à API = %d, esperado 3", got)
+	}
+}
+
+func TestDeliverEmailGivesUp(t *testing.T) {
+	calls := failingEmailAPI(t, 10)
+	userID := uuid.NewString()
+
+	deliverEmail(userID, models.EmailKindStatusChange, email.Message{To: "ana@example.com", Subject: "Assunto"})
+
+	delivery := deliveryFor(t, userID)
+	if delivery.Status != models.DeliveryFailed || delivery.Attempts != 3 || delivery.SentAt != nil || delivery.LastError == "" {
+		t.Errorf("envio = %+v, esperado falha após 3 tentativas", delivery)
+	}
+	if got := atomic.LoadInt32(calls); got != 3 {
+		t.Errorf("chamadas à API = %d, esperado 3", got)
+	}
+}
+
+// A mudança de status avisa o usuário com o motivo informado pelo
+// administrador.
+func TestStatusChangeSendsEmail(t *testing.T) {
+	messages := captureEmails(t)
+	r := newTestRouter()
+	installer := createTestUser(t, models.RoleInstalador, models.StatusApproved)
+	token := bearerToken(t, createTestUser(t, models.RoleAdmin, models.StatusApproved))
+
+	if w := doRequest(r, http.MethodPost, "/user/"+installer.ID+"/suspend", token, statusReasonBody); w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body)
+	}
+
+	msg := waitEmail(t, messages, installer.Email)
+	if msg.Subject != "EletriHub - Conta suspensa" || !strings.Contains(msg.HTML, "documentação inconsistente") {
+		t.Errorf("e-mail inesperado: %+v", msg)
+	}
+}
+
+func TestEmailDeliveriesAdminOnly(t *testing.T) {
+	testAdminOnly(t, map[string]adminRoute{
+		"envios de e-mail": {http.MethodGet, "/user/x/email-deliveries", func(t *testing.T) (string, string) {
+			return "/user/" + createTestUser(t, models.RoleInstalador, models.StatusApproved).ID + "/email-deliveries", ""
+		}, http.StatusOK},
+	})
+}
@@ -0,0 +1,205 @@
+package user
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"user-service/internal/database"
+	"user-service/internal/loginguard"
+	"user-service/internal/oidc"
+	"user-service/internal/user/models"
+	"user-service/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var (
+	errOIDCEmailNotVerified = errors.New("e-mail não verificado pelo provedor")
+	errOIDCPasswordRequired = errors.New("senha exigida para vincular o provedor")
+	errOIDCWrongPassword    = errors.New("senha incorreta ao vincular o provedor")
+)
+
+// LoginOIDC autentica com o ID token de um provedor OIDC (Google, Apple...).
+// A conta é encontrada pelo vínculo provedor + sub; na falta dele, pelo e-mail
+// verificado pelo provedor, que é então vinculado. Contas de instalador e de
+// administrador só são vinculadas com a senha atual (campo password). Se não
+// houver conta, um cliente é criado. Cada ID token é aceito uma única vez
+// (pelo nonce). A resposta é a mesma de LoginUser.
+func LoginOIDC(c *gin.Context) {
+	var body struct {
+		Provider   string `json:"provider"`
+		IDToken    string `json:"id_token"`
+		Nonce      string `json:"nonce"`
+		Password   string `json:"password"`
+		DeviceName string `json:"device_name"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil || body.Provider == "" || body.IDToken == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "provider e id_token são obrigatórios"})
+		return
+	}
+
+	if respondIfLocked(c, loginguard.IPKey(c.ClientIP())) {
+		return
+	}
+
+	verifier, err := oidc.Get(body.Provider)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Provedor não suportado"})
+		return
+	}
+
+	identity, err := verifier.Verify(c.Request.Context(), body.IDToken, body.Nonce)
+	if err != nil {
+		fmt.Println("⚠️ ID token OIDC rejeitado:", err)
+		loginguard.Fail(loginguard.IPKey(c.ClientIP()), loginguard.IPPolicy())
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Token do provedor inválido ou expirado"})
+		return
+	}
+
+	accountKey := loginAccountKey(identity.Email)
+	if body.Password != "" && respondIfLocked(c, accountKey) {
+		return
+	}
+
+	user, err := findOrCreateOIDCUser(identity, body.Password)
+	switch {
+	case errors.Is(err, errOIDCEmailNotVerified):
+		c.JSON(http.StatusForbidden, gin.H{"error": "O provedor não confirmou o e-mail da conta"})
+		return
+	case errors.Is(err, errOIDCPasswordRequired):
+		c.JSON(http.StatusConflict, gin.H{
+			"error":             "Já existe uma conta com este e-mail; informe a senha para vincular o provedor",
+			"password_required": true,
+		})
+		return
+	case errors.Is(err, errOIDCWrongPassword):
+		registerLoginFailure(c, accountKey)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": invalidCredentialsMessage})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao autenticar com o provedor"})
+		return
+	}
+
+	// Consumido só depois de resolver a conta, para que o cliente possa repetir
+	// o mesmo token informando a senha
+	first, err := oidc.ConsumeNonce(identity)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao autenticar com o provedor"})
+		return
+	}
+	if !first {
+		loginguard.Fail(loginguard.IPKey(c.ClientIP()), loginguard.IPPolicy())
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Token do provedor já utilizado"})
+		return
+	}
+
+	if body.Password != "" {
+		if err := loginguard.Reset(accountKey); err != nil {
+			fmt.Println("⚠️ Erro ao limpar tentativas de login:", err)
+		}
+	}
+
+	completeLogin(c, user, body.DeviceName)
+}
+
+// findOrCreateOIDCUser resolve a conta do ID token. O vínculo automático pelo
+// e-mail vale só para clientes; para outros papéis, que têm acesso a dados de
+// terceiros, password precisa ser a senha atual da conta.
+func findOrCreateOIDCUser(identity *oidc.Identity, password string) (models.User, error) {
+	var user models.User
+	now := time.Now()
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var link models.UserIdentity
+		result := tx.Where("provider = ? AND subject = ?", identity.Provider, identity.Subject).Limit(1).Find(&link)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			result = tx.Where("id = ?", link.UserID).Limit(1).Find(&user)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected > 0 {
+				return tx.Model(&link).Update("last_login_at", now).Error
+			}
+
+			// Vínculo de uma conta que já não existe: é descartado e o login
+			// segue como se o provedor nunca tivesse sido vinculado
+			if err := tx.Delete(&link).Error; err != nil {
+				return err
+			}
+		}
+
+		// Sem vínculo, só um e-mail confirmado pelo provedor identifica a conta
+		if !identity.EmailVerified || identity.Email == "" {
+			return errOIDCEmailNotVerified
+		}
+
+		result = tx.Where("LOWER(email) = LOWER(?)", identity.Email).Limit(1).Find(&user)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 && user.Role != models.RoleCliente {
+			switch {
+			case password == "":
+				return errOIDCPasswordRequired
+			case !user.CheckPassword(password):
+				return errOIDCWrongPassword
+			}
+		}
+		if result.RowsAffected == 0 {
+			if err := createOIDCUser(tx, &user, identity); err != nil {
+				return err
+			}
+		} else if user.EmailVerifiedAt == nil {
+			if err := tx.Model(&user).Update("email_verified_at", now).Error; err != nil {
+				return err
+			}
+			user.EmailVerifiedAt = &now
+		}
+
+		return tx.Create(&models.UserIdentity{
+			UserID:      user.ID,
+			Provider:    identity.Provider,
+			Subject:     identity.Subject,
+			Email:       identity.Email,
+			LastLoginAt: now,
+		}).Error
+	})
+	return user, err
+}
+
+// createOIDCUser cria um cliente com os dados do provedor. A senha é aleatória
+// e desconhecida; para entrar com e-mail e senha, o usuário usa a redefinição.
+func createOIDCUser(tx *gorm.DB, user *models.User, identity *oidc.Identity) error {
+	password, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	name := strings.TrimSpace(identity.Name)
+	if name == "" {
+		name = strings.Split(identity.Email, "@")[0]
+	}
+
+	*user = models.User{
+		Name:            name,
+		Email:           identity.Email,
+		EmailVerifiedAt: &now,
+		Password:        password,
+		Role:            models.RoleCliente,
+		Status:          models.StatusApproved,
+		Authorized:      true,
+	}
+	if err := user.HashPassword(); err != nil {
+		return err
+	}
+	return tx.Create(user).Error
+}
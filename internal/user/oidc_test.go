@@ -0,0 +1,162 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+	"user-service/internal/database"
+	"user-service/internal/loginguard"
+	"user-service/internal/oidc"
+	"user-service/internal/user/models"
+	"user-service/internal/utils"
+
+	"github.com/google/uuid"
+)
+
+// stubVerifier aceita como ID token a chave de uma identidade conhecida.
+type stubVerifier map[string]oidc.Identity
+
+func (s stubVerifier) Verify(ctx context.Context, rawIDToken, nonce string) (*oidc.Identity, error) {
+	identity, ok := s[rawIDToken]
+	if !ok || identity.Nonce != nonce {
+		return nil, errors.New("token inválido")
+	}
+	return &identity, nil
+}
+
+func oidcLoginBody(idToken, nonce, password string) string {
+	return `{"provider":"stub","id_token":"` + idToken + `","nonce":"` + nonce + `","password":"` + password + `"}`
+}
+
+func registerStubIdentity(verifier stubVerifier, email string, verified bool) (string, string) {
+	return registerStubSubject(verifier, uuid.NewString(), email, verified)
+}
+
+// registerStubSubject registra um novo ID token para a conta do provedor
+// identificada por subject.
+func registerStubSubject(verifier stubVerifier, subject, email string, verified bool) (string, string) {
+	idToken, nonce := uuid.NewString(), uuid.NewString()
+	verifier[idToken] = oidc.Identity{
+		Provider:      "stub",
+		Subject:       subject,
+		Nonce:         nonce,
+		ExpiresAt:     time.Now().Add(time.Hour),
+		Email:         email,
+		EmailVerified: verified,
+		Name:          "Pessoa OIDC",
+	}
+	return idToken, nonce
+}
+
+func setupStubOIDC(t *testing.T) stubVerifier {
+	verifier := stubVerifier{}
+	oidc.Register("stub", verifier)
+	t.Cleanup(func() { loginguard.Reset(loginguard.IPKey("192.0.2.1")) })
+	return verifier
+}
+
+func TestLoginOIDCCreatesClientAndRejectsReplay(t *testing.T) {
+	verifier := setupStubOIDC(t)
+	r := newTestRouter()
+	email := uuid.NewString() + "@example.com"
+	idToken, nonce := registerStubIdentity(verifier, email, true)
+
+	if w := doRequest(r, http.MethodPost, "/user/login/oidc", "", oidcLoginBody(idToken, nonce, "")); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, esperado %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+	var user models.User
+	if err := database.DB.First(&user, "email = ?", email).Error; err != nil {
+		t.Fatal(err)
+	}
+	if user.Role != models.RoleCliente {
+		t.Errorf("papel = %q, esperado %q", user.Role, models.RoleCliente)
+	}
+
+	// O mesmo ID token não pode ser usado de novo
+	if w := doRequest(r, http.MethodPost, "/user/login/oidc", "", oidcLoginBody(idToken, nonce, "")); w.Code != http.StatusUnauthorized {
+		t.Errorf("reuso: status = %d, esperado %d: %s", w.Code, http.StatusUnauthorized, w.Body)
+	}
+
+	var nonces int64
+	database.DB.Model(&models.OIDCNonce{}).Where("hash = ?", utils.HashToken("stub:"+nonce)).Count(&nonces)
+	if nonces != 1 {
+		t.Errorf("nonces registrados = %d, esperado 1", nonces)
+	}
+}
+
+// Um vínculo cuja conta já não existe é descartado e o login segue como o de
+// uma identidade nova.
+func TestLoginOIDCIgnoresDanglingLink(t *testing.T) {
+	verifier := setupStubOIDC(t)
+	r := newTestRouter()
+	subject := uuid.NewString()
+	email := uuid.NewString() + "@example.com"
+
+	idToken, nonce := registerStubSubject(verifier, subject, email, true)
+	if w := doRequest(r, http.MethodPost, "/user/login/oidc", "", oidcLoginBody(idToken, nonce, "")); w.Code != http.StatusOK {
+		t.Fatalf("primeiro login: status = %d: %s", w.Code, w.Body)
+	}
+	var previous models.User
+	if err := database.DB.First(&previous, "email = ?", email).Error; err != nil {
+		t.Fatal(err)
+	}
+	// Remove só a conta, como em exclusões anteriores à limpeza dos vínculos
+	if err := database.DB.Delete(&previous).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	idToken, nonce = registerStubSubject(verifier, subject, email, true)
+	if w := doRequest(r, http.MethodPost, "/user/login/oidc", "", oidcLoginBody(idToken, nonce, "")); w.Code != http.StatusOK {
+		t.Fatalf("login após exclusão: status = %d, esperado %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+
+	var links []models.UserIdentity
+	if err := database.DB.Where("provider = ? AND subject = ?", "stub", subject).Find(&links).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(links) != 1 || links[0].UserID == previous.ID {
+		t.Errorf("vínculos = %+v, esperado um vínculo com a nova conta", links)
+	}
+}
+
+func TestLoginOIDCRequiresVerifiedEmail(t *testing.T) {
+	verifier := setupStubOIDC(t)
+	r := newTestRouter()
+	idToken, nonce := registerStubIdentity(verifier, uuid.NewString()+"@example.com", false)
+
+	if w := doRequest(r, http.MethodPost, "/user/login/oidc", "", oidcLoginBody(idToken, nonce, "")); w.Code != http.StatusForbidden {
+		t.Errorf("status = %d, esperado %d: %s", w.Code, http.StatusForbidden, w.Body)
+	}
+}
+
+// Contas que não são de cliente só são vinculadas com a senha atual; o mesmo
+// token pode ser repetido com a senha.
+func TestLoginOIDCLinksInstallerOnlyWithPassword(t *testing.T) {
+	verifier := setupStubOIDC(t)
+	r := newTestRouter()
+	installer := createTestUser(t, models.RoleInstalador, models.StatusApproved)
+	idToken, nonce := registerStubIdentity(verifier, installer.Email, true)
+	t.Cleanup(func() { loginguard.Reset(loginAccountKey(installer.Email)) })
+
+	steps := []struct {
+		password string
+		want     int
+	}{
+		{"", http.StatusConflict},
+		{"senha-errada", http.StatusUnauthorized},
+		{testPassword, http.StatusOK},
+	}
+	for _, step := range steps {
+		if w := doRequest(r, http.MethodPost, "/user/login/oidc", "", oidcLoginBody(idToken, nonce, step.password)); w.Code != step.want {
+			t.Fatalf("senha %q: status = %d, esperado %d: %s", step.password, w.Code, step.want, w.Body)
+		}
+	}
+
+	var links int64
+	database.DB.Model(&models.UserIdentity{}).Where("user_id = ? AND provider = ?", installer.ID, "stub").Count(&links)
+	if links != 1 {
+		t.Errorf("vínculos = %d, esperado 1", links)
+	}
+}
@@ -0,0 +1,78 @@
+package user
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"user-service/internal/database"
+	"user-service/internal/user/models"
+)
+
+func TestMutationRoutesRejectOtherUsers(t *testing.T) {
+	r := newTestRouter()
+	token := bearerToken(t, createTestUser(t, models.RoleCliente, models.StatusApproved))
+	other := createTestUser(t, models.RoleCliente, models.StatusApproved)
+
+	routes := []struct{ method, path, body string }{
+		{http.MethodPut, "/user/" + other.ID, `{"name":"Invasor"}`},
+		{http.MethodPut, "/user/" + other.ID + "/password", `{"new_password":"Outra-senha-1"}`},
+		{http.MethodPut, "/user/" + other.ID + "/photo", ""},
+		{http.MethodDelete, "/user/" + other.ID, ""},
+	}
+	for _, route := range routes {
+		if w := doRequest(r, route.method, route.path, token, route.body); w.Code != http.StatusForbidden {
+			t.Errorf("%s %s: status = %d, esperado %d: %s", route.method, route.path, w.Code, http.StatusForbidden, w.Body)
+		}
+	}
+
+	if err := database.DB.First(&other, "id = ?", other.ID).Error; err != nil {
+		t.Fatalf("usuário alvo removido: %v", err)
+	}
+	if other.Name == "Invasor" || !other.CheckPassword(testPassword) {
+		t.Errorf("usuário alvo alterado: %+v", other)
+	}
+}
+
+func TestMutationRoutesAllowOwnerAndAdmin(t *testing.T) {
+	r := newTestRouter()
+	owner := createTestUser(t, models.RoleCliente, models.StatusApproved)
+	admin := createTestUser(t, models.RoleAdmin, models.StatusApproved)
+
+	if w := doRequest(r, http.MethodPut, "/user/"+owner.ID, bearerToken(t, owner), `{"name":"Dono"}`); w.Code != http.StatusOK {
+		t.Errorf("dono: status = %d: %s", w.Code, w.Body)
+	}
+	if w := doRequest(r, http.MethodPut, "/user/"+owner.ID, bearerToken(t, admin), `{"name":"Pelo admin"}`); w.Code != http.StatusOK {
+		t.Errorf("admin: status = %d: %s", w.Code, w.Body)
+	}
+	if err := database.DB.First(&owner, "id = ?", owner.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if owner.Name != "Pelo admin" {
+		t.Errorf("nome = %q, esperado %q", owner.Name, "Pelo admin")
+	}
+}
+
+func TestMeRoutesUseTokenIdentity(t *testing.T) {
+	r := newTestRouter()
+	user := createTestUser(t, models.RoleCliente, models.StatusApproved)
+	token := bearerToken(t, user)
+
+	w := doRequest(r, http.MethodGet, "/user/me", token, "")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), user.ID) {
+		t.Fatalf("GET /user/me: status = %d: %s", w.Code, w.Body)
+	}
+
+	if w := doRequest(r, http.MethodPut, "/user/me", token, `{"name":"Pelo me"}`); w.Code != http.StatusOK {
+		t.Fatalf("PUT /user/me: status = %d: %s", w.Code, w.Body)
+	}
+	if err := database.DB.First(&user, "id = ?", user.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if user.Name != "Pelo me" {
+		t.Errorf("nome = %q, esperado %q", user.Name, "Pelo me")
+	}
+
+	if w := doRequest(r, http.MethodGet, "/user/me", "", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("sem token: status = %d, esperado %d", w.Code, http.StatusUnauthorized)
+	}
+}
@@ -0,0 +1,204 @@
+package user
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+	"user-service/internal/database"
+	"user-service/internal/email"
+	"user-service/internal/ratelimit"
+	"user-service/internal/revocation"
+	"user-service/internal/user/models"
+	"user-service/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var errInvalidUserToken = errors.New("token inválido ou expirado")
+
+// Limites de envio dos e-mails de conta, por endereço e por IP de origem, para
+// que as rotas públicas não sirvam para inundar caixas de entrada
+var (
+	accountEmailWindow   = utils.GetEnvDuration("ACCOUNT_EMAIL_LIMIT_WINDOW", time.Hour)
+	accountEmailsPerAddr = ratelimit.New(utils.GetEnvInt("ACCOUNT_EMAIL_LIMIT_PER_ADDRESS", 3), accountEmailWindow)
+	accountEmailsPerIP   = ratelimit.New(utils.GetEnvInt("ACCOUNT_EMAIL_LIMIT_PER_IP", 10), accountEmailWindow)
+)
+
+// allowAccountEmail registra um pedido de e-mail de conta e informa se o
+// endereço e o IP da requisição ainda estão dentro dos limites.
+func allowAccountEmail(c *gin.Context, address string) bool {
+	ipAllowed := accountEmailsPerIP.Allow(c.ClientIP())
+	addressAllowed := accountEmailsPerAddr.Allow(strings.ToLower(address))
+	return ipAllowed && addressAllowed
+}
+
+// createUserToken gera um token de uso único para a finalidade informada,
+// descartando tokens anteriores ainda não usados da mesma finalidade.
+func createUserToken(userID, purpose string, ttl time.Duration) (string, error) {
+	raw, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+			Delete(&models.UserToken{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.UserToken{
+			UserID:    userID,
+			Purpose:   purpose,
+			TokenHash: utils.HashToken(raw),
+			ExpiresAt: time.Now().Add(ttl),
+		}).Error
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return raw, nil
+}
+
+// consumeUserToken valida e marca como usado um token de uso único dentro da
+// transação informada.
+func consumeUserToken(tx *gorm.DB, raw, purpose string) (models.UserToken, error) {
+	var token models.UserToken
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("token_hash = ? AND purpose = ?", utils.HashToken(raw), purpose).
+		First(&token).Error; err != nil {
+		return token, errInvalidUserToken
+	}
+
+	if token.UsedAt != nil || time.Now().After(token.ExpiresAt) {
+		return token, errInvalidUserToken
+	}
+
+	now := time.Now()
+	if err := tx.Model(&token).Update("used_at", now).Error; err != nil {
+		return token, err
+	}
+	token.UsedAt = &now
+	return token, nil
+}
+
+// frontendLink monta o link do front-end configurado na variável envKey com o
+// token como parâmetro. Retorna vazio se a variável não estiver definida.
+func frontendLink(envKey, token string) string {
+	base := os.Getenv(envKey)
+	if base == "" {
+		return ""
+	}
+	separator := "?"
+	if strings.Contains(base, "?") {
+		separator = "&"
+	}
+	return base + separator + url.Values{"token": {token}}.Encode()
+}
+
+// sendPasswordReset gera o token e envia o e-mail de redefinição, se o e-mail
+// pertencer a um usuário cadastrado.
+func sendPasswordReset(address string) {
+	var user models.User
+	if err := database.DB.Where("email = ?", address).First(&user).Error; err != nil {
+		return
+	}
+
+	ttl := utils.GetEnvDuration("PASSWORD_RESET_TTL", time.Hour)
+	token, err := createUserToken(user.ID, models.TokenPurposePasswordReset, ttl)
+	if err != nil {
+		fmt.Println("⚠️ Erro ao gerar token de redefinição de senha:", err)
+		return
+	}
+
+	err = email.SendPasswordReset(user.Email, email.PasswordResetData{
+		Name:         user.Name,
+		Link:         frontendLink("PASSWORD_RESET_URL", token),
+		Token:        token,
+		ValidMinutes: int(ttl.Minutes()),
+	})
+	if err != nil {
+		fmt.Println("⚠️ Erro ao enviar e-mail de redefinição de senha:", err)
+	}
+}
+
+// ForgotPassword inicia a redefinição de senha. A resposta é sempre a mesma,
+// exista ou não o e-mail, e o envio ocorre em segundo plano para que o tempo
+// de resposta também não revele a existência da conta.
+func ForgotPassword(c *gin.Context) {
+	var body struct {
+		Email string `json:"email"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil || body.Email == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "email é obrigatório"})
+		return
+	}
+
+	address := strings.TrimSpace(body.Email)
+	if !allowAccountEmail(c, address) {
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "Muitas solicitações; tente novamente mais tarde"})
+		return
+	}
+
+	go sendPasswordReset(address)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Se o e-mail estiver cadastrado, você receberá as instruções para redefinir a senha",
+	})
+}
+
+// ResetPassword define uma nova senha a partir de um token de redefinição
+// válido e encerra todas as sessões do usuário.
+func ResetPassword(c *gin.Context) {
+	var body struct {
+		Token       string `json:"token"`
+		NewPassword string `json:"new_password"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil || body.Token == "" || body.NewPassword == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "token e new_password são obrigatórios"})
+		return
+	}
+
+	if problems := utils.CheckPasswordPolicy(body.NewPassword); len(problems) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Senha não atende à política de segurança", "details": problems})
+		return
+	}
+
+	var userID string
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		token, err := consumeUserToken(tx, body.Token, models.TokenPurposePasswordReset)
+		if err != nil {
+			return err
+		}
+		userID = token.UserID
+
+		user := models.User{Password: body.NewPassword}
+		if err := user.HashPassword(); err != nil {
+			return err
+		}
+		// Quem recebeu o token por e-mail comprovou ser dono do endereço
+		return tx.Model(&models.User{}).Where("id = ?", token.UserID).Updates(map[string]interface{}{
+			"password":          user.Password,
+			"email_verified_at": gorm.Expr("COALESCE(email_verified_at, ?)", time.Now()),
+		}).Error
+	})
+	if errors.Is(err, errInvalidUserToken) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Token inválido ou expirado"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao redefinir senha"})
+		return
+	}
+
+	if err := revocation.RevokeUser(userID, "password_reset"); err != nil {
+		fmt.Println("⚠️ Erro ao revogar sessões após redefinição de senha:", err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Senha redefinida com sucesso"})
+}
@@ -0,0 +1,203 @@
+package user
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+	"user-service/internal/email"
+	"user-service/internal/ratelimit"
+	"user-service/internal/user/models"
+)
+
+// captureEmails direciona a API de e-mail para um servidor local e entrega
+// as mensagens recebidas no canal retornado.
+func captureEmails(t *testing.T) <-chan email.Message {
+	t.Helper()
+	messages := make(chan email.Message, 10)
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg email.Message
+		if err := json.NewDecoder(r.Body).Decode(&msg); err == nil {
+			messages <- msg
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(api.Close)
+	t.Setenv("EMAIL_API_URL", api.URL)
+	return messages
+}
+
+// resetAccountEmailLimits usa limitadores novos durante o teste, para que os
+// pedidos de outros testes não contem.
+func resetAccountEmailLimits(t *testing.T, perAddress, perIP int) {
+	t.Helper()
+	previousAddr, previousIP := accountEmailsPerAddr, accountEmailsPerIP
+	accountEmailsPerAddr = ratelimit.New(perAddress, time.Hour)
+	accountEmailsPerIP = ratelimit.New(perIP, time.Hour)
+	t.Cleanup(func() { accountEmailsPerAddr, accountEmailsPerIP = previousAddr, previousIP })
+}
+
+// waitEmail aguarda o e-mail enviado ao destinatário, ignorando envios em
+// segundo plano de outros testes.
+func waitEmail(t *testing.T, messages <-chan email.Message, to string) email.Message {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case msg := <-messages:
+			if msg.To == to {
+				return msg
+			}
+		case <-timeout:
+			t.Fatalf("nenhum e-mail enviado para %s", to)
+			return email.Message{}
+		}
+	}
+}
+
+var emailTokenPattern = regexp.MustCompile(`<strong>([A-Za-z0-9_-]+)</strong>`)
+
+func TestPasswordResetFlow(t *testing.T) {
+	resetAccountEmailLimits(t, 3, 10)
+	messages := captureEmails(t)
+	r := newTestRouter()
+	user := createTestUser(t, models.RoleCliente, models.StatusApproved)
+
+	if w := doRequest(r, http.MethodPost, "/user/password/forgot", "", `{"email":"`+user.Email+`"}`); w.Code != http.StatusOK {
+		t.Fatalf("forgot: status = %d: %s", w.Code, w.Body)
+	}
+	msg := waitEmail(t, messages, user.Email)
+	match := emailTokenPattern.FindStringSubmatch(msg.HTML)
+	if match == nil {
+		t.Fatalf("e-mail sem token: %s", msg.HTML)
+	}
+
+	body := `{"token":"` + match[1] + `","new_password":"Nova-senha-123"}`
+	if w := doRequest(r, http.MethodPost, "/user/password/reset", "", body); w.Code != http.StatusOK {
+		t.Fatalf("reset: status = %d: %s", w.Code, w.Body)
+	}
+	if w := doRequest(r, http.MethodPost, "/user/password/reset", "", body); w.Code != http.StatusBadRequest {
+		t.Errorf("token reutilizado: status = %d, esperado %d", w.Code, http.StatusBadRequest)
+	}
+
+	login := `{"email":"` + user.Email + `","password":"Nova-senha-123"}`
+	if w := doRequest(r, http.MethodPost, "/user/login", "", login); w.Code != http.StatusOK {
+		t.Errorf("login com a nova senha: status = %d: %s", w.Code, w.Body)
+	}
+}
+
+// A resposta para um e-mail desconhecido é a mesma, sem envio.
+func TestForgotPasswordUnknownEmail(t *testing.T) {
+	resetAccountEmailLimits(t, 3, 10)
+	messages := captureEmails(t)
+	r := newTestRouter()
+
+	if w := doRequest(r, http.MethodPost, "/user/password/forgot", "", `{"email":"ninguem@example.com"}`); w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body)
+	}
+	timeout := time.After(200 * time.Millisecond)
+	for {
+		select {
+		case msg := <-messages:
+			if msg.To == "ninguem@example.com" {
+				t.Errorf("e-mail enviado para conta inexistente: %+v", msg)
+			}
+		case <-timeout:
+			return
+		}
+	}
+}
+
+func TestForgotPasswordThrottledPerAddress(t *testing.T) {
+	resetAccountEmailLimits(t, 2, 10)
+	r := newTestRouter()
+	body := `{"email":"limite@example.com"}`
+
+	for i := 0; i < 2; i++ {
+		if w := doRequest(r, http.MethodPost, "/user/password/forgot", "", body); w.Code != http.StatusOK {
+			t.Fatalf("pedido %d: status = %d: %s", i+1, w.Code, w.Body)
+		}
+	}
+	// Maiúsculas não contornam o limite do endereço
+	if w := doRequest(r, http.MethodPost, "/user/password/forgot", "", `{"email":"LIMITE@example.com"}`); w.Code != http.StatusTooManyRequests {
+		t.Errorf("status = %d, esperado %d", w.Code, http.StatusTooManyRequests)
+	}
+	if w := doRequest(r, http.MethodPost, "/user/password/forgot", "", `{"email":"outro@example.com"}`); w.Code != http.StatusOK {
+		t.Errorf("outro endereço: status = %d, esperado %d", w.Code, http.StatusOK)
+	}
+}
+
+func TestForgotPasswordThrottledPerIP(t *testing.T) {
+	resetAccountEmailLimits(t, 3, 2)
+	r := newTestRouter()
+
+	for i, address := range []string{"a@example.com", "b@example.com"} {
+		if w := doRequest(r, http.MethodPost, "/user/password/forgot", "", `{"email":"`+address+`"}`); w.Code != http.StatusOK {
+			t.Fatalf("pedido %d: status = %d: %s", i+1, w.Code, w.Body)
+		}
+	}
+	if w := doRequest(r, http.MethodPost, "/user/password/forgot", "", `{"email":"c@example.com"}`); w.Code != http.StatusTooManyRequests {
+		t.Errorf("status = %d, esperado %d", w.Code, http.StatusTooManyRequests)
+	}
+}
+
+func TestRegisterRejectsWeakPassword(t *testing.T) {
+	r := newTestRouter()
+	body := `{"name":"Fraca","email":"fraca@example.com","password":"123456","role":"cliente"}`
+	if w := doRequest(r, http.MethodPost, "/user/register", "", body); w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, esperado %d: %s", w.Code, http.StatusBadRequest, w.Body)
+	}
+}
+
+func TestUpdatePasswordPolicy(t *testing.T) {
+	r := newTestRouter()
+	user := createTestUser(t, models.RoleCliente, models.StatusApproved)
+	token := bearerToken(t, user)
+
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{"senha atual incorreta", `{"current_password":"errada","new_password":"Nova-senha-123"}`, http.StatusUnauthorized},
+		{"senha fraca", `{"current_password":"` + testPassword + `","new_password":"12345678"}`, http.StatusBadRequest},
+		{"mesma senha", `{"current_password":"` + testPassword + `","new_password":"` + testPassword + `"}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := doRequest(r, http.MethodPut, "/user/me/password", token, tt.body); w.Code != tt.want {
+				t.Errorf("status = %d, esperado %d: %s", w.Code, tt.want, w.Body)
+			}
+		})
+	}
+
+	w := doRequest(r, http.MethodPut, "/user/me/password", token, `{"current_password":"`+testPassword+`","new_password":"Nova-senha-123"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("troca válida: status = %d: %s", w.Code, w.Body)
+	}
+	decodeTokens(t, w.Body.Bytes())
+}
+
+// O administrador troca a senha de outro usuário sem conhecer a atual.
+func TestAdminUpdatesPasswordWithoutCurrent(t *testing.T) {
+	r := newTestRouter()
+	user := createTestUser(t, models.RoleCliente, models.StatusApproved)
+	admin := createTestUser(t, models.RoleAdmin, models.StatusApproved)
+
+	if w := doRequest(r, http.MethodPut, "/user/"+user.ID+"/password", bearerToken(t, admin), `{"new_password":"Nova-senha-123"}`); w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body)
+	}
+	login := `{"email":"` + user.Email + `","password":"Nova-senha-123"}`
+	if w := doRequest(r, http.MethodPost, "/user/login", "", login); w.Code != http.StatusOK {
+		t.Errorf("login com a nova senha: status = %d: %s", w.Code, w.Body)
+	}
+}
+
+func TestResetPasswordRejectsWeakPassword(t *testing.T) {
+	r := newTestRouter()
+	if w := doRequest(r, http.MethodPost, "/user/password/reset", "", `{"token":"qualquer","new_password":"123"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, esperado %d: %s", w.Code, http.StatusBadRequest, w.Body)
+	}
+}
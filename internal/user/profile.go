@@ -0,0 +1,182 @@
+package user
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+	"user-service/internal/geo"
+	"user-service/internal/user/models"
+	"user-service/internal/utils"
+)
+
+// UpdateUserRequest contém os campos de perfil editáveis. Campos ausentes não
+// são alterados; métricas, papel, status e credenciais não fazem parte dele.
+type UpdateUserRequest struct {
+	Name         *string  `json:"name"`
+	Phone        *string  `json:"phone"`
+	CPF          *string  `json:"cpf"`
+	CNPJ         *string  `json:"cnpj"`
+	CompanyName  *string  `json:"company_name"`
+	Street       *string  `json:"street"`
+	Number       *string  `json:"number"`
+	Neighborhood *string  `json:"neighborhood"`
+	City         *string  `json:"city"`
+	State        *string  `json:"state"`
+	Complement   *string  `json:"complement"`
+	CEP          *string  `json:"cep"`
+	Latitude     *float64 `json:"latitude"`
+	Longitude    *float64 `json:"longitude"`
+	BirthDate    *string  `json:"birth_date"`
+	Reference    *string  `json:"reference"`
+	AceptTerms   *bool    `json:"accept_terms"`
+}
+
+var clientProfileFields = []string{
+	"name", "phone", "cpf", "street", "number", "neighborhood", "city", "state",
+	"complement", "cep", "latitude", "longitude", "birth_date", "reference", "accept_terms",
+}
+
+// Campos de perfil que cada papel pode editar
+var profileFieldsByRole = map[string][]string{
+	models.RoleCliente:    clientProfileFields,
+	models.RoleInstalador: append([]string{"cnpj", "company_name"}, clientProfileFields...),
+	models.RoleAdmin:      {"name", "phone"},
+}
+
+// decodeUpdateUserRequest lê o corpo, recusando campos fora da lista permitida
+// para o papel do usuário editado.
+func decodeUpdateUserRequest(body []byte, role string) (UpdateUserRequest, error) {
+	var req UpdateUserRequest
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return req, fmt.Errorf("JSON inválido")
+	}
+
+	allowed := map[string]bool{}
+	for _, field := range profileFieldsByRole[role] {
+		allowed[field] = true
+	}
+	var rejected []string
+	for field := range fields {
+		if !allowed[field] {
+			rejected = append(rejected, field)
+		}
+	}
+	if len(rejected) > 0 {
+		sort.Strings(rejected)
+		return req, fmt.Errorf("campos não permitidos: %s", strings.Join(rejected, ", "))
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		return req, fmt.Errorf("JSON inválido: %v", err)
+	}
+	return req, nil
+}
+
+// Validate confere cada campo informado e retorna a lista de problemas.
+// Campos opcionais podem ser enviados vazios para limpar o valor.
+func (r UpdateUserRequest) Validate() []string {
+	var problems []string
+	check := func(ok bool, message string) {
+		if !ok {
+			problems = append(problems, message)
+		}
+	}
+
+	if r.Name != nil {
+		name := strings.TrimSpace(*r.Name)
+		check(utf8.RuneCountInString(name) >= 2 && utf8.RuneCountInString(name) <= 120, "name deve ter entre 2 e 120 caracteres")
+	}
+	if r.Phone != nil && *r.Phone != "" {
+		check(utils.ValidPhone(*r.Phone), "phone inválido (informe DDD e número)")
+	}
+	if r.CPF != nil && *r.CPF != "" {
+		check(utils.ValidCPF(*r.CPF), "cpf inválido")
+	}
+	if r.CNPJ != nil && *r.CNPJ != "" {
+		check(utils.ValidCNPJ(*r.CNPJ), "cnpj inválido")
+	}
+	if r.CEP != nil && *r.CEP != "" {
+		check(utils.ValidCEP(*r.CEP), "cep inválido")
+	}
+	if r.State != nil && *r.State != "" {
+		check(geo.IsUF(*r.State), "state deve ser a sigla de uma UF")
+	}
+	if r.Latitude != nil {
+		check(*r.Latitude >= -90 && *r.Latitude <= 90, "latitude deve estar entre -90 e 90")
+	}
+	if r.Longitude != nil {
+		check(*r.Longitude >= -180 && *r.Longitude <= 180, "longitude deve estar entre -180 e 180")
+	}
+	if r.BirthDate != nil && *r.BirthDate != "" {
+		date, ok := utils.ParseBirthDate(*r.BirthDate)
+		check(ok && date.Before(time.Now()) && date.Year() >= 1900, "birth_date inválida (use AAAA-MM-DD)")
+	}
+
+	lengths := map[string]*string{
+		"company_name": r.CompanyName,
+		"street":       r.Street,
+		"number":       r.Number,
+		"neighborhood": r.Neighborhood,
+		"city":         r.City,
+		"complement":   r.Complement,
+		"reference":    r.Reference,
+	}
+	for _, field := range []string{"company_name", "street", "number", "neighborhood", "city", "complement", "reference"} {
+		if value := lengths[field]; value != nil {
+			check(utf8.RuneCountInString(*value) <= 255, field+" deve ter no máximo 255 caracteres")
+		}
+	}
+
+	return problems
+}
+
+// Updates converte os campos informados nas colunas a atualizar.
+func (r UpdateUserRequest) Updates() map[string]interface{} {
+	updates := map[string]interface{}{}
+	setString := func(column string, value *string) {
+		if value != nil {
+			updates[column] = strings.TrimSpace(*value)
+		}
+	}
+
+	setString("name", r.Name)
+	setString("phone", r.Phone)
+	setString("cpf", r.CPF)
+	setString("cnpj", r.CNPJ)
+	setString("company_name", r.CompanyName)
+	setString("street", r.Street)
+	setString("number", r.Number)
+	setString("neighborhood", r.Neighborhood)
+	setString("city", r.City)
+	setString("complement", r.Complement)
+	setString("cep", r.CEP)
+	setString("reference", r.Reference)
+	if r.BirthDate != nil {
+		// Gravada sempre como AAAA-MM-DD; Validate já recusou datas inválidas
+		updates["birth_date"] = ""
+		if date, ok := utils.ParseBirthDate(*r.BirthDate); ok {
+			updates["birth_date"] = date.Format("2006-01-02")
+		}
+	}
+	if r.State != nil {
+		updates["state"] = strings.ToUpper(strings.TrimSpace(*r.State))
+	}
+	if r.Latitude != nil {
+		updates["latitude"] = *r.Latitude
+	}
+	if r.Longitude != nil {
+		updates["longitude"] = *r.Longitude
+	}
+	if r.AceptTerms != nil {
+		updates["acept_terms"] = *r.AceptTerms
+	}
+	return updates
+}
@@ -0,0 +1,112 @@
+package user
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"user-service/internal/user/models"
+)
+
+// Métricas, papel e status não são editáveis pelo perfil, nem por um
+// instalador sobre si mesmo.
+func TestUpdateUserRejectsRestrictedFields(t *testing.T) {
+	r := newTestRouter()
+	installer := createTestUser(t, models.RoleInstalador, models.StatusApproved)
+	token := bearerToken(t, installer)
+
+	for _, field := range []string{`"average_rating":5`, `"review_count":10`, `"total_services_accepted":3`, `"role":"admin"`, `"status":"approved"`} {
+		w := doRequest(r, http.MethodPut, "/user/me", token, `{"name":"Novo Nome",`+field+`}`)
+		name := strings.Split(field, ":")[0]
+		if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), strings.Trim(name, `"`)) {
+			t.Errorf("%s: status = %d: %s", name, w.Code, w.Body)
+		}
+	}
+	reloadUser(t, &installer)
+	if installer.AverageRating != 0 || installer.ReviewCount != 0 || installer.Name == "Novo Nome" || installer.Role != models.RoleInstalador {
+		t.Errorf("usuário alterado: %+v", installer)
+	}
+
+	// Campos de instalador não valem para clientes
+	client := bearerToken(t, createTestUser(t, models.RoleCliente, models.StatusApproved))
+	if w := doRequest(r, http.MethodPut, "/user/me", client, `{"company_name":"Empresa"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("company_name de cliente: status = %d, esperado %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestUpdateUserValidatesAndNormalizes(t *testing.T) {
+	r := newTestRouter()
+	installer := createTestUser(t, models.RoleInstalador, models.StatusApproved)
+	token := bearerToken(t, installer)
+
+	w := doRequest(r, http.MethodPut, "/user/me", token, `{"cpf":"111.111.111-11","phone":"1234","state":"XX","birth_date":"31/02/1990"}`)
+	var invalid struct {
+		Details []string `json:"details"`
+	}
+	if w.Code != http.StatusBadRequest || json.Unmarshal(w.Body.Bytes(), &invalid) != nil || len(invalid.Details) != 4 {
+		t.Fatalf("dados inválidos: status = %d: %s", w.Code, w.Body)
+	}
+
+	body := `{"name":" Maria Instaladora ","cpf":"529.982.247-25","cnpj":"11.222.333/0001-81","phone":"+55 55 99876-5432",` +
+		`"state":"rs","cep":"90010-000","birth_date":"17/05/1990"}`
+	if w := doRequest(r, http.MethodPut, "/user/me", token, body); w.Code != http.StatusOK {
+		t.Fatalf("atualizar: status = %d: %s", w.Code, w.Body)
+	}
+	reloadUser(t, &installer)
+	if installer.Name != "Maria Instaladora" || installer.State != "RS" || installer.BirthDate != "1990-05-17" {
+		t.Errorf("perfil gravado: nome = %q, UF = %q, nascimento = %q", installer.Name, installer.State, installer.BirthDate)
+	}
+}
+
+// Incrementos simultâneos não se perdem, e repetir a chamada de um serviço
+// não o conta de novo.
+func TestIncrementInstallerStats(t *testing.T) {
+	r := newTestRouter()
+	installer := createTestUser(t, models.RoleInstalador, models.StatusApproved)
+	key := createTestAPIKey(t, []string{models.ScopeInstallerStatsWrite}, nil)
+	path := "/user/" + installer.ID + "/stats/increment"
+	increment := func(body string) {
+		if w := doAPIKeyRequest(r, http.MethodPost, path, key, body); w.Code != http.StatusOK {
+			t.Errorf("incrementar %s: status = %d: %s", body, w.Code, w.Body)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			increment(`{"service_id":"pedido-` + strconv.Itoa(i) + `","services_accepted":1}`)
+		}()
+		// Repetições do mesmo serviço
+		go func() {
+			defer wg.Done()
+			increment(`{"service_id":"pedido-0","services_accepted":1}`)
+		}()
+	}
+	wg.Wait()
+	increment(`{"service_id":"pedido-3","services_not_executed":1}`)
+	increment(`{"service_id":"pedido-3","services_accepted":1,"services_not_executed":1}`)
+
+	reloadUser(t, &installer)
+	if installer.TotalServicesAccepted != 10 || installer.ServicesNotExecuted != 1 {
+		t.Errorf("métricas = %d aceitos, %d não executados; esperado 10 e 1", installer.TotalServicesAccepted, installer.ServicesNotExecuted)
+	}
+
+	for name, body := range map[string]string{
+		"sem service_id": `{"services_accepted":1}`,
+		"negativo":       `{"service_id":"x","services_accepted":-1}`,
+		"acima de 1":     `{"service_id":"x","services_accepted":2}`,
+		"vazio":          `{"service_id":"x"}`,
+	} {
+		if w := doAPIKeyRequest(r, http.MethodPost, path, key, body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, esperado %d", name, w.Code, http.StatusBadRequest)
+		}
+	}
+	client := createTestUser(t, models.RoleCliente, models.StatusApproved)
+	if w := doAPIKeyRequest(r, http.MethodPost, "/user/"+client.ID+"/stats/increment", key, `{"service_id":"x","services_accepted":1}`); w.Code != http.StatusNotFound {
+		t.Errorf("cliente: status = %d, esperado %d", w.Code, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,357 @@
+package user
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"user-service/internal/database"
+	"user-service/internal/user/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Qualification é uma especialidade ou marca do instalador. Verified indica
+// que o documento comprobatório foi aprovado.
+type Qualification struct {
+	Code              string  `json:"code"`
+	Name              string  `json:"name"`
+	CertificationCode string  `json:"certification_code,omitempty"`
+	DocumentID        *string `json:"document_id,omitempty"`
+	Verified          bool    `json:"verified"`
+}
+
+type installerQualifications struct {
+	Specialties []Qualification
+	Brands      []Qualification
+}
+
+// loadQualifications carrega as especialidades e marcas dos usuários informados.
+func loadQualifications(userIDs []string) (map[string]*installerQualifications, error) {
+	result := make(map[string]*installerQualifications, len(userIDs))
+	for _, id := range userIDs {
+		result[id] = &installerQualifications{Specialties: []Qualification{}, Brands: []Qualification{}}
+	}
+	if len(userIDs) == 0 {
+		return result, nil
+	}
+
+	type row struct {
+		UserID            string
+		Code              string
+		Name              string
+		CertificationCode string
+		DocumentID        *string
+		Verified          bool
+	}
+
+	var specialties []row
+	if err := database.DB.Table("installer_specialties AS l").
+		Select("l.user_id, s.code, s.name, l.document_id, COALESCE(d.status = ?, false) AS verified", models.DocumentApproved).
+		Joins("JOIN specialties s ON s.id = l.specialty_id").
+		Joins("LEFT JOIN installer_documents d ON d.id = l.document_id").
+		Where("l.user_id IN ?", userIDs).
+		Order("s.name").
+		Scan(&specialties).Error; err != nil {
+		return nil, err
+	}
+	for _, r := range specialties {
+		q := result[r.UserID]
+		q.Specialties = append(q.Specialties, Qualification{Code: r.Code, Name: r.Name, DocumentID: r.DocumentID, Verified: r.Verified})
+	}
+
+	var brands []row
+	if err := database.DB.Table("installer_brands AS l").
+		Select("l.user_id, b.code, b.name, l.certification_code, l.document_id, COALESCE(d.status = ?, false) AS verified", models.DocumentApproved).
+		Joins("JOIN charger_brands b ON b.id = l.brand_id").
+		Joins("LEFT JOIN installer_documents d ON d.id = l.document_id").
+		Where("l.user_id IN ?", userIDs).
+		Order("b.name").
+		Scan(&brands).Error; err != nil {
+		return nil, err
+	}
+	for _, r := range brands {
+		q := result[r.UserID]
+		q.Brands = append(q.Brands, Qualification{
+			Code:              r.Code,
+			Name:              r.Name,
+			CertificationCode: r.CertificationCode,
+			DocumentID:        r.DocumentID,
+			Verified:          r.Verified,
+		})
+	}
+
+	return result, nil
+}
+
+// normalizeCode padroniza um código do catálogo, gravado em minúsculas.
+func normalizeCode(code string) string {
+	return strings.ToLower(strings.TrimSpace(code))
+}
+
+// queryCodes lê um filtro com códigos separados por vírgula.
+func queryCodes(c *gin.Context, key string) []string {
+	var codes []string
+	for _, code := range strings.Split(c.Query(key), ",") {
+		if code = normalizeCode(code); code != "" {
+			codes = append(codes, code)
+		}
+	}
+	return codes
+}
+
+// applyQualificationFilters restringe a busca de instaladores aos que têm
+// todas as especialidades (specialty) e marcas (brand) pedidas.
+func applyQualificationFilters(query *gorm.DB, c *gin.Context) *gorm.DB {
+	for _, code := range queryCodes(c, "specialty") {
+		query = query.Where("users.id IN (?)", database.DB.Table("installer_specialties AS l").
+			Select("l.user_id").
+			Joins("JOIN specialties s ON s.id = l.specialty_id").
+			Where("s.code = ?", code))
+	}
+	for _, code := range queryCodes(c, "brand") {
+		query = query.Where("users.id IN (?)", database.DB.Table("installer_brands AS l").
+			Select("l.user_id").
+			Joins("JOIN charger_brands b ON b.id = l.brand_id").
+			Where("b.code = ?", code))
+	}
+	return query
+}
+
+// installerResponses monta a resposta pública dos instaladores, com suas
+// especialidades e marcas.
+func installerResponses(users []models.User) ([]UserInstalerResponse, error) {
+	ids := make([]string, 0, len(users))
+	for _, user := range users {
+		ids = append(ids, user.ID)
+	}
+
+	qualifications, err := loadQualifications(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]UserInstalerResponse, 0, len(users))
+	for _, user := range users {
+		response := newInstallerResponse(user)
+		response.Specialties = qualifications[user.ID].Specialties
+		response.Brands = qualifications[user.ID].Brands
+		responses = append(responses, response)
+	}
+	return responses, nil
+}
+
+// ListSpecialties lista o catálogo de especialidades.
+func ListSpecialties(c *gin.Context) {
+	var specialties []models.Specialty
+	if err := database.DB.Order("name").Find(&specialties).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao listar especialidades"})
+		return
+	}
+	c.JSON(http.StatusOK, specialties)
+}
+
+// ListChargerBrands lista o catálogo de marcas de carregadores.
+func ListChargerBrands(c *gin.Context) {
+	var brands []models.ChargerBrand
+	if err := database.DB.Order("name").Find(&brands).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao listar marcas"})
+		return
+	}
+	c.JSON(http.StatusOK, brands)
+}
+
+// CreateSpecialty adiciona uma especialidade ao catálogo.
+func CreateSpecialty(c *gin.Context) {
+	var specialty models.Specialty
+	if err := c.ShouldBindJSON(&specialty); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON"})
+		return
+	}
+	specialty.Code = normalizeCode(specialty.Code)
+	specialty.Name = strings.TrimSpace(specialty.Name)
+	if specialty.Code == "" || specialty.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "code e name são obrigatórios"})
+		return
+	}
+
+	if err := database.DB.Create(&specialty).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Especialidade já cadastrada"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao salvar especialidade"})
+		return
+	}
+	c.JSON(http.StatusCreated, specialty)
+}
+
+// CreateChargerBrand adiciona uma marca ao catálogo.
+func CreateChargerBrand(c *gin.Context) {
+	var brand models.ChargerBrand
+	if err := c.ShouldBindJSON(&brand); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON"})
+		return
+	}
+	brand.Code = normalizeCode(brand.Code)
+	brand.Name = strings.TrimSpace(brand.Name)
+	if brand.Code == "" || brand.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "code e name são obrigatórios"})
+		return
+	}
+
+	if err := database.DB.Create(&brand).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Marca já cadastrada"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao salvar marca"})
+		return
+	}
+	c.JSON(http.StatusCreated, brand)
+}
+
+type qualificationInput struct {
+	Code              string  `json:"code"`
+	CertificationCode string  `json:"certification_code"`
+	DocumentID        *string `json:"document_id"`
+}
+
+var errInvalidQualification = errors.New("qualificação inválida")
+
+// checkProofDocument exige que o documento comprobatório, se informado,
+// pertença ao usuário.
+func checkProofDocument(tx *gorm.DB, userID string, documentID *string) error {
+	if documentID == nil || *documentID == "" {
+		return nil
+	}
+	var count int64
+	if err := tx.Model(&models.InstallerDocument{}).
+		Where("id = ? AND user_id = ?", *documentID, userID).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return fmt.Errorf("%w: documento %s não encontrado", errInvalidQualification, *documentID)
+	}
+	return nil
+}
+
+func respondQualifications(c *gin.Context, userID string) {
+	qualifications, err := loadQualifications([]string{userID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao listar especialidades e marcas"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"specialties": qualifications[userID].Specialties,
+		"brands":      qualifications[userID].Brands,
+	})
+}
+
+func respondQualificationError(c *gin.Context, err error) {
+	if errors.Is(err, errInvalidQualification) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao salvar especialidades e marcas"})
+}
+
+// GetMyQualifications lista as especialidades e marcas do instalador autenticado.
+func GetMyQualifications(c *gin.Context) {
+	respondQualifications(c, c.GetString("user_id"))
+}
+
+// UpdateMySpecialties substitui as especialidades do instalador autenticado.
+func UpdateMySpecialties(c *gin.Context) {
+	var body struct {
+		Specialties []qualificationInput `json:"specialties"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON"})
+		return
+	}
+
+	userID := c.GetString("user_id")
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.InstallerSpecialty{}).Error; err != nil {
+			return err
+		}
+		seen := map[string]bool{}
+		for _, input := range body.Specialties {
+			var specialty models.Specialty
+			if err := tx.Where("code = ?", normalizeCode(input.Code)).First(&specialty).Error; err != nil {
+				return fmt.Errorf("%w: especialidade desconhecida %q", errInvalidQualification, input.Code)
+			}
+			if seen[specialty.ID] {
+				continue
+			}
+			seen[specialty.ID] = true
+			if err := checkProofDocument(tx, userID, input.DocumentID); err != nil {
+				return err
+			}
+			if err := tx.Create(&models.InstallerSpecialty{
+				UserID:      userID,
+				SpecialtyID: specialty.ID,
+				DocumentID:  input.DocumentID,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		respondQualificationError(c, err)
+		return
+	}
+
+	respondQualifications(c, userID)
+}
+
+// UpdateMyBrands substitui as marcas de carregador do instalador autenticado.
+func UpdateMyBrands(c *gin.Context) {
+	var body struct {
+		Brands []qualificationInput `json:"brands"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON"})
+		return
+	}
+
+	userID := c.GetString("user_id")
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.InstallerBrand{}).Error; err != nil {
+			return err
+		}
+		seen := map[string]bool{}
+		for _, input := range body.Brands {
+			var brand models.ChargerBrand
+			if err := tx.Where("code = ?", normalizeCode(input.Code)).First(&brand).Error; err != nil {
+				return fmt.Errorf("%w: marca desconhecida %q", errInvalidQualification, input.Code)
+			}
+			if seen[brand.ID] {
+				continue
+			}
+			seen[brand.ID] = true
+			if err := checkProofDocument(tx, userID, input.DocumentID); err != nil {
+				return err
+			}
+			if err := tx.Create(&models.InstallerBrand{
+				UserID:            userID,
+				BrandID:           brand.ID,
+				CertificationCode: strings.TrimSpace(input.CertificationCode),
+				DocumentID:        input.DocumentID,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		respondQualificationError(c, err)
+		return
+	}
+
+	respondQualifications(c, userID)
+}
@@ -0,0 +1,176 @@
+package user
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"user-service/internal/database"
+	"user-service/internal/user/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// publicInstallerIDs retorna os IDs listados por uma busca pública de
+// instaladores.
+func publicInstallerIDs(t *testing.T, r *gin.Engine, path string) map[string]bool {
+	t.Helper()
+	w := doRequest(r, http.MethodGet, path, "", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("%s: status = %d: %s", path, w.Code, w.Body)
+	}
+	var installers []UserInstalerResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &installers); err != nil {
+		t.Fatal(err)
+	}
+	ids := make(map[string]bool, len(installers))
+	for _, installer := range installers {
+		ids[installer.ID] = true
+	}
+	return ids
+}
+
+func TestCatalogRoutesAdminOnly(t *testing.T) {
+	entry := func(path string) func(t *testing.T) (string, string) {
+		return func(t *testing.T) (string, string) {
+			return path, `{"code":"teste_` + uuid.NewString()[:8] + `","name":"Teste"}`
+		}
+	}
+	testAdminOnly(t, map[string]adminRoute{
+		"criar especialidade": {http.MethodPost, "/user/catalog/specialties", entry("/user/catalog/specialties"), http.StatusCreated},
+		"criar marca":         {http.MethodPost, "/user/catalog/brands", entry("/user/catalog/brands"), http.StatusCreated},
+	})
+}
+
+// O código é gravado sem espaços e em minúsculas; um código repetido, em
+// qualquer grafia, é recusado com 409.
+func TestCreateCatalogEntryNormalizesCode(t *testing.T) {
+	r := newTestRouter()
+	token := bearerToken(t, createTestUser(t, models.RoleAdmin, models.StatusApproved))
+
+	for _, path := range []string{"/user/catalog/specialties", "/user/catalog/brands"} {
+		code := "Novo_" + uuid.NewString()[:8]
+		w := doRequest(r, http.MethodPost, path, token, `{"code":"  `+code+` ","name":" Novo "}`)
+		var created struct{ Code, Name string }
+		if w.Code != http.StatusCreated || json.Unmarshal(w.Body.Bytes(), &created) != nil {
+			t.Fatalf("%s: status = %d: %s", path, w.Code, w.Body)
+		}
+		if created.Code != normalizeCode(code) || created.Name != "Novo" {
+			t.Errorf("%s: criado %+v", path, created)
+		}
+
+		if w := doRequest(r, http.MethodPost, path, token, `{"code":"`+created.Code+`","name":"Outro"}`); w.Code != http.StatusConflict {
+			t.Errorf("%s: código repetido: status = %d, esperado %d", path, w.Code, http.StatusConflict)
+		}
+		for _, body := range []string{`{"code":"   ","name":"Vazio"}`, `{"code":"vazio","name":" "}`, `{"code":`} {
+			if w := doRequest(r, http.MethodPost, path, token, body); w.Code != http.StatusBadRequest {
+				t.Errorf("%s: corpo %s: status = %d, esperado %d", path, body, w.Code, http.StatusBadRequest)
+			}
+		}
+	}
+}
+
+// O instalador declara especialidades e marcas pelo código, em qualquer
+// grafia; o documento comprobatório precisa ser dele e, aprovado, verifica a
+// qualificação.
+func TestUpdateMyQualifications(t *testing.T) {
+	r := newTestRouter()
+	installer := createTestUser(t, models.RoleInstalador, models.StatusApproved)
+	token := bearerToken(t, installer)
+
+	doc := models.InstallerDocument{UserID: installer.ID, Type: models.DocumentNR10, FileKey: "x", Status: models.DocumentPending}
+	other := models.InstallerDocument{UserID: createTestUser(t, models.RoleInstalador, models.StatusApproved).ID, Type: models.DocumentNR10, FileKey: "y", Status: models.DocumentApproved}
+	for _, d := range []*models.InstallerDocument{&doc, &other} {
+		if err := database.DB.Create(d).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for name, body := range map[string]string{
+		"código desconhecido":   `{"specialties":[{"code":"inexistente"}]}`,
+		"documento de terceiro": `{"specialties":[{"code":"carregador_dc","document_id":"` + other.ID + `"}]}`,
+	} {
+		if w := doRequest(r, http.MethodPut, "/user/me/specialties", token, body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, esperado %d", name, w.Code, http.StatusBadRequest)
+		}
+	}
+
+	body := `{"specialties":[{"code":" Wallbox_Residencial ","document_id":"` + doc.ID + `"},{"code":"wallbox_residencial"}]}`
+	if w := doRequest(r, http.MethodPut, "/user/me/specialties", token, body); w.Code != http.StatusOK {
+		t.Fatalf("especialidades: status = %d: %s", w.Code, w.Body)
+	}
+	if w := doRequest(r, http.MethodPut, "/user/me/brands", token, `{"brands":[{"code":"ABB","certification_code":" CERT-1 "}]}`); w.Code != http.StatusOK {
+		t.Fatalf("marcas: status = %d: %s", w.Code, w.Body)
+	}
+
+	var qualifications struct {
+		Specialties []Qualification `json:"specialties"`
+		Brands      []Qualification `json:"brands"`
+	}
+	read := func() {
+		t.Helper()
+		w := doRequest(r, http.MethodGet, "/user/me/qualifications", token, "")
+		if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &qualifications) != nil {
+			t.Fatalf("qualificações: status = %d: %s", w.Code, w.Body)
+		}
+	}
+	read()
+	if len(qualifications.Specialties) != 1 || qualifications.Specialties[0].Code != "wallbox_residencial" || qualifications.Specialties[0].Verified {
+		t.Errorf("especialidades = %+v", qualifications.Specialties)
+	}
+	if len(qualifications.Brands) != 1 || qualifications.Brands[0].Code != "abb" || qualifications.Brands[0].CertificationCode != "CERT-1" {
+		t.Errorf("marcas = %+v", qualifications.Brands)
+	}
+
+	if err := database.DB.Model(&doc).Update("status", models.DocumentApproved).Error; err != nil {
+		t.Fatal(err)
+	}
+	read()
+	if !qualifications.Specialties[0].Verified {
+		t.Error("especialidade com documento aprovado não verificada")
+	}
+}
+
+// Os filtros specialty e brand exigem todos os códigos pedidos, em qualquer
+// grafia, na listagem pública e na busca por proximidade.
+func TestQualificationFilters(t *testing.T) {
+	r := newTestRouter()
+	full := createTestInstaller(t, -15.8, -47.9, nil)
+	partial := createTestInstaller(t, -15.8, -47.9, nil)
+	none := createTestInstaller(t, -15.8, -47.9, nil)
+
+	for _, installer := range []models.User{full, partial} {
+		token := bearerToken(t, installer)
+		if w := doRequest(r, http.MethodPut, "/user/me/specialties", token, `{"specialties":[{"code":"carregador_dc"}]}`); w.Code != http.StatusOK {
+			t.Fatalf("especialidades: status = %d: %s", w.Code, w.Body)
+		}
+	}
+	fullToken := bearerToken(t, full)
+	if w := doRequest(r, http.MethodPut, "/user/me/specialties", fullToken, `{"specialties":[{"code":"carregador_dc"},{"code":"integracao_solar"}]}`); w.Code != http.StatusOK {
+		t.Fatalf("especialidades: status = %d: %s", w.Code, w.Body)
+	}
+	if w := doRequest(r, http.MethodPut, "/user/me/brands", fullToken, `{"brands":[{"code":"weg"}]}`); w.Code != http.StatusOK {
+		t.Fatalf("marcas: status = %d: %s", w.Code, w.Body)
+	}
+
+	cases := []struct {
+		query         string
+		full, partial bool
+	}{
+		{"specialty=carregador_dc", true, true},
+		{"specialty=%20CARREGADOR_DC%20", true, true},
+		{"specialty=carregador_dc,integracao_solar", true, false},
+		{"specialty=carregador_dc&brand=WEG", true, false},
+		{"brand=abb", false, false},
+	}
+	for _, tc := range cases {
+		for path, ids := range map[string]map[string]bool{
+			"listagem": publicInstallerIDs(t, r, "/user/public/installers?"+tc.query),
+			"nearby":   nearbyIDs(t, r, "lat=-15.8&lng=-47.9&"+tc.query),
+		} {
+			if ids[full.ID] != tc.full || ids[partial.ID] != tc.partial || ids[none.ID] {
+				t.Errorf("%s %s: completo = %v, parcial = %v, sem qualificações = %v", path, tc.query, ids[full.ID], ids[partial.ID], ids[none.ID])
+			}
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package user
+
+import (
+	"math"
+	"sort"
+	"user-service/internal/user/models"
+	"user-service/internal/utils"
+)
+
+// Ranking da busca de instaladores próximos
+//
+// Cada instalador recebe notas parciais entre 0 e 1:
+//
+//   - distância:      1 - d / NEARBY_SCORE_MAX_DISTANCE_KM (padrão: o raio de
+//     NEARBY_DEFAULT_RADIUS_KM; mínimo 0); 0 se a distância for desconhecida
+//     (instalador sem coordenadas)
+//   - nota:           média bayesiana das avaliações, (C·m + n·média) / (C + n),
+//     com m = NEARBY_SCORE_PRIOR_RATING e C = NEARBY_SCORE_PRIOR_WEIGHT,
+//     normalizada de 1–5 para 0–1; poucas avaliações puxam a nota para m
+//   - avaliações:     log(1 + n) / log(1 + NEARBY_SCORE_REVIEWS_CAP) (máximo 1)
+//   - confiabilidade: serviços executados sobre aceitos,
+//     (aceitos - não executados + C·p) / (aceitos + C), com p = 0,9 e o mesmo
+//     C da nota, para que quem ainda não tem histórico fique perto de p
+//
+// O score é a média ponderada das notas parciais pelos pesos
+// NEARBY_WEIGHT_DISTANCE, NEARBY_WEIGHT_RATING, NEARBY_WEIGHT_REVIEWS e
+// NEARBY_WEIGHT_RELIABILITY (padrões 0,35, 0,3, 0,1 e 0,25).
+
+const priorReliability = 0.9
+
+type rankingConfig struct {
+	MaxDistanceKm float64
+	PriorRating   float64
+	PriorWeight   float64
+	ReviewsCap    float64
+
+	WeightDistance    float64
+	WeightRating      float64
+	WeightReviews     float64
+	WeightReliability float64
+}
+
+func loadRankingConfig() rankingConfig {
+	nonNegative := func(v float64) float64 { return math.Max(v, 0) }
+	return rankingConfig{
+		MaxDistanceKm:     math.Max(utils.GetEnvFloat("NEARBY_SCORE_MAX_DISTANCE_KM", defaultServiceRadiusKm()), 1),
+		PriorRating:       utils.GetEnvFloat("NEARBY_SCORE_PRIOR_RATING", 4),
+		PriorWeight:       nonNegative(utils.GetEnvFloat("NEARBY_SCORE_PRIOR_WEIGHT", 5)),
+		ReviewsCap:        math.Max(utils.GetEnvFloat("NEARBY_SCORE_REVIEWS_CAP", 50), 1),
+		WeightDistance:    nonNegative(utils.GetEnvFloat("NEARBY_WEIGHT_DISTANCE", 0.35)),
+		WeightRating:      nonNegative(utils.GetEnvFloat("NEARBY_WEIGHT_RATING", 0.3)),
+		WeightReviews:     nonNegative(utils.GetEnvFloat("NEARBY_WEIGHT_REVIEWS", 0.1)),
+		WeightReliability: nonNegative(utils.GetEnvFloat("NEARBY_WEIGHT_RELIABILITY", 0.25)),
+	}
+}
+
+// reliabilityScore estima a fração de serviços aceitos que o instalador
+// executou, suavizada para quem tem pouco histórico.
+func reliabilityScore(user models.User, cfg rankingConfig) float64 {
+	accepted := float64(user.TotalServicesAccepted)
+	executed := math.Max(accepted-float64(user.ServicesNotExecuted), 0)
+	if accepted+cfg.PriorWeight == 0 {
+		return priorReliability
+	}
+	return (executed + cfg.PriorWeight*priorReliability) / (accepted + cfg.PriorWeight)
+}
+
+// installerScore calcula o score de 0 a 1 descrito acima.
+func installerScore(user models.User, distanceKm *float64, cfg rankingConfig) float64 {
+	distance := 0.0
+	if distanceKm != nil {
+		distance = math.Max(1-*distanceKm/cfg.MaxDistanceKm, 0)
+	}
+
+	n := float64(user.ReviewCount)
+	rating := cfg.PriorRating
+	if n+cfg.PriorWeight > 0 {
+		rating = (cfg.PriorWeight*cfg.PriorRating + n*user.AverageRating) / (cfg.PriorWeight + n)
+	}
+	rating = math.Min(math.Max((rating-1)/4, 0), 1)
+
+	reviews := math.Min(math.Log1p(n)/math.Log1p(cfg.ReviewsCap), 1)
+
+	total := cfg.WeightDistance + cfg.WeightRating + cfg.WeightReviews + cfg.WeightReliability
+	if total == 0 {
+		return 0
+	}
+	return (cfg.WeightDistance*distance +
+		cfg.WeightRating*rating +
+		cfg.WeightReviews*reviews +
+		cfg.WeightReliability*reliabilityScore(user, cfg)) / total
+}
+
+// Ordenações aceitas em ListNearbyInstallers (parâmetro sort)
+const (
+	sortByDistance = "distance"
+	sortByRating   = "rating"
+	sortByScore    = "score"
+)
+
+func validNearbySort(value string) bool {
+	return value == sortByDistance || value == sortByRating || value == sortByScore
+}
+
+// lessDistance ordena distâncias conhecidas primeiro, da menor para a maior.
+func lessDistance(a, b *float64) bool {
+	switch {
+	case a == nil:
+		return false
+	case b == nil:
+		return true
+	default:
+		return *a < *b
+	}
+}
+
+// nearbyLess define a ordem dos resultados conforme o critério; empates são
+// desfeitos pela distância e, por fim, pelo ID, para que a ordem seja total e
+// o cursor de paginação aponte sempre para a mesma posição.
+func nearbyLess(a, b NearbyInstallerResponse, by string) bool {
+	switch by {
+	case sortByRating:
+		if a.AverageRating != b.AverageRating {
+			return a.AverageRating > b.AverageRating
+		}
+		if a.ReviewCount != b.ReviewCount {
+			return a.ReviewCount > b.ReviewCount
+		}
+	case sortByScore:
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+	}
+	if lessDistance(a.DistanceKm, b.DistanceKm) || lessDistance(b.DistanceKm, a.DistanceKm) {
+		return lessDistance(a.DistanceKm, b.DistanceKm)
+	}
+	return a.ID < b.ID
+}
+
+// sortNearby ordena os resultados conforme o critério (ver nearbyLess).
+func sortNearby(items []NearbyInstallerResponse, by string) {
+	sort.Slice(items, func(i, j int) bool {
+		return nearbyLess(items[i], items[j], by)
+	})
+}
@@ -0,0 +1,163 @@
+package user
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"testing"
+	"user-service/internal/database"
+	"user-service/internal/user/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+var testRankingConfig = rankingConfig{
+	MaxDistanceKm:     50,
+	PriorRating:       4,
+	PriorWeight:       5,
+	ReviewsCap:        50,
+	WeightDistance:    0.35,
+	WeightRating:      0.3,
+	WeightReviews:     0.1,
+	WeightReliability: 0.25,
+}
+
+func approxEqual(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+
+func TestReliabilityScore(t *testing.T) {
+	noPrior := testRankingConfig
+	noPrior.PriorWeight = 0
+
+	tests := []struct {
+		name        string
+		accepted    int
+		notExecuted int
+		cfg         rankingConfig
+		want        float64
+	}{
+		{"sem histórico", 0, 0, testRankingConfig, 0.9},
+		{"sem histórico nem prior", 0, 0, noPrior, 0.9},
+		{"tudo executado", 10, 0, testRankingConfig, (10 + 5*0.9) / 15},
+		{"nada executado", 10, 10, testRankingConfig, (5 * 0.9) / 15},
+		{"mais falhas que aceitos", 2, 5, testRankingConfig, (5 * 0.9) / 7},
+		{"sem prior", 4, 1, noPrior, 0.75},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := models.User{TotalServicesAccepted: tt.accepted, ServicesNotExecuted: tt.notExecuted}
+			if got := reliabilityScore(user, tt.cfg); !approxEqual(got, tt.want) {
+				t.Errorf("reliabilityScore = %v, esperado %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInstallerScore(t *testing.T) {
+	user := models.User{AverageRating: 5, ReviewCount: 5}
+
+	// distância 1 - 10/50; nota (5·4 + 5·5) / 10 = 4,5, normalizada (4,5-1)/4;
+	// avaliações log(6)/log(51); confiabilidade p = 0,9
+	want := 0.35*0.8 + 0.3*0.875 + 0.1*math.Log(6)/math.Log(51) + 0.25*0.9
+	if got := installerScore(user, km(10), testRankingConfig); !approxEqual(got, want) {
+		t.Errorf("installerScore = %v, esperado %v", got, want)
+	}
+
+	// Sem coordenadas, a nota de distância é zero
+	if got, want := installerScore(user, nil, testRankingConfig), want-0.35*0.8; !approxEqual(got, want) {
+		t.Errorf("installerScore sem distância = %v, esperado %v", got, want)
+	}
+
+	// Além da distância máxima, a nota de distância não fica negativa
+	if got, want := installerScore(user, km(500), testRankingConfig), want-0.35*0.8; !approxEqual(got, want) {
+		t.Errorf("installerScore distante = %v, esperado %v", got, want)
+	}
+
+	zero := rankingConfig{MaxDistanceKm: 50, ReviewsCap: 50}
+	if got := installerScore(user, km(10), zero); got != 0 {
+		t.Errorf("installerScore sem pesos = %v, esperado 0", got)
+	}
+}
+
+// Com a média bayesiana, uma única nota 5 não supera uma média alta com
+// muitas avaliações.
+func TestInstallerScorePrefersConsistentRatings(t *testing.T) {
+	single := models.User{AverageRating: 5, ReviewCount: 1}
+	many := models.User{AverageRating: 4.8, ReviewCount: 40}
+
+	if installerScore(single, km(5), testRankingConfig) >= installerScore(many, km(5), testRankingConfig) {
+		t.Error("instalador com uma avaliação ficou à frente de um com 40")
+	}
+}
+
+func TestLoadRankingConfigClampsWeights(t *testing.T) {
+	t.Setenv("NEARBY_WEIGHT_RATING", "-1")
+	t.Setenv("NEARBY_SCORE_MAX_DISTANCE_KM", "0")
+
+	cfg := loadRankingConfig()
+	if cfg.WeightRating != 0 {
+		t.Errorf("WeightRating = %v, esperado 0", cfg.WeightRating)
+	}
+	if cfg.MaxDistanceKm != 1 {
+		t.Errorf("MaxDistanceKm = %v, esperado 1", cfg.MaxDistanceKm)
+	}
+}
+
+func km(v float64) *float64 { return &v }
+
+// nearbyResults retorna os resultados da busca por proximidade, na ordem,
+// seguindo next_cursor por todas as páginas.
+func nearbyResults(t *testing.T, r *gin.Engine, query string) []NearbyInstallerResponse {
+	t.Helper()
+	var items []NearbyInstallerResponse
+	path := "/user/public/installers/nearby?" + query
+	for cursor := ""; ; {
+		w := doRequest(r, http.MethodGet, path+cursor, "", "")
+		var page struct {
+			Items      []NearbyInstallerResponse `json:"items"`
+			NextCursor *string                   `json:"next_cursor"`
+		}
+		if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &page) != nil {
+			t.Fatalf("busca: status = %d: %s", w.Code, w.Body)
+		}
+		items = append(items, page.Items...)
+		if page.NextCursor == nil {
+			return items
+		}
+		cursor = "&cursor=" + *page.NextCursor
+	}
+}
+
+// A busca traz distance_km e score e ordena pelo critério pedido.
+func TestNearbySort(t *testing.T) {
+	r := newTestRouter()
+	// Ponto isolado, longe dos instaladores criados por outros testes
+	near := createTestInstaller(t, -9.001, -60.001, nil)
+	rated := createTestInstaller(t, -9.2, -60.2, nil)
+	if err := database.DB.Model(&rated).Updates(map[string]interface{}{"average_rating": 4.9, "review_count": 40}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	order := func(sort string) []string {
+		var ids []string
+		for _, item := range nearbyResults(t, r, "lat=-9&lng=-60&sort="+sort) {
+			if item.ID == near.ID || item.ID == rated.ID {
+				ids = append(ids, item.ID)
+			}
+		}
+		return ids
+	}
+	for sort, first := range map[string]string{sortByDistance: near.ID, sortByRating: rated.ID, sortByScore: rated.ID} {
+		if ids := order(sort); len(ids) != 2 || ids[0] != first {
+			t.Errorf("sort=%s: ordem = %v", sort, ids)
+		}
+	}
+
+	for _, item := range nearbyResults(t, r, "lat=-9&lng=-60") {
+		if item.ID == near.ID && (item.DistanceKm == nil || *item.DistanceKm > 1 || item.Score <= 0 || item.ReliabilityScore != 0.9) {
+			t.Errorf("resultado = %+v", item)
+		}
+	}
+	if w := doRequest(r, http.MethodGet, "/user/public/installers/nearby?lat=-9&lng=-60&sort=name", "", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("sort inválido: status = %d, esperado %d", w.Code, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,223 @@
+package user
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"user-service/internal/database"
+	"user-service/internal/user/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	errReviewExists         = errors.New("serviço já avaliado")
+	errInstallerNotApproved = errors.New("instalador não aprovado")
+	errServiceNotReviewable = errors.New("serviço não concluído para este cliente e instalador")
+)
+
+// recalculateRating atualiza a nota média e o total de avaliações do
+// instalador. Chamar dentro da transação que alterou as avaliações, após
+// travar a linha do instalador.
+func recalculateRating(tx *gorm.DB, installerID string) error {
+	return tx.Model(&models.User{}).Where("id = ?", installerID).Updates(map[string]interface{}{
+		"average_rating": gorm.Expr("(SELECT COALESCE(AVG(rating), 0) FROM reviews WHERE installer_id = ?)", installerID),
+		"review_count":   gorm.Expr("(SELECT COUNT(*) FROM reviews WHERE installer_id = ?)", installerID),
+	}).Error
+}
+
+func lockInstaller(tx *gorm.DB, installerID string) (models.User, error) {
+	var installer models.User
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND role = ?", installerID, models.RoleInstalador).
+		First(&installer).Error
+	return installer, err
+}
+
+// lockApprovedInstaller trava o instalador como lockInstaller e recusa, com
+// errInstallerNotApproved, quem não está aprovado.
+func lockApprovedInstaller(tx *gorm.DB, installerID string) (models.User, error) {
+	installer, err := lockInstaller(tx, installerID)
+	if err == nil && !models.IsActiveStatus(installer.Status) {
+		err = errInstallerNotApproved
+	}
+	return installer, err
+}
+
+// CreateReview registra a avaliação (1 a 5 estrelas) de um serviço feita pelo
+// cliente autenticado e recalcula a nota do instalador. O serviço precisa ser
+// um agendamento concluído desse cliente com esse instalador, e o instalador
+// precisa estar aprovado.
+func CreateReview(c *gin.Context) {
+	var body struct {
+		ServiceID string `json:"service_id"`
+		Rating    int    `json:"rating"`
+		Comment   string `json:"comment"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil || strings.TrimSpace(body.ServiceID) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "service_id e rating são obrigatórios"})
+		return
+	}
+	if body.Rating < 1 || body.Rating > 5 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "rating deve estar entre 1 e 5"})
+		return
+	}
+
+	review := models.Review{
+		InstallerID: c.Param("id"),
+		ClientID:    c.GetString("user_id"),
+		ServiceID:   strings.TrimSpace(body.ServiceID),
+		Rating:      body.Rating,
+		Comment:     strings.TrimSpace(body.Comment),
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if _, err := lockApprovedInstaller(tx, review.InstallerID); err != nil {
+			return err
+		}
+
+		var completed int64
+		if err := tx.Model(&models.Booking{}).
+			Where("service_id = ? AND user_id = ? AND client_id = ? AND completed_at IS NOT NULL AND canceled_at IS NULL",
+				review.ServiceID, review.InstallerID, review.ClientID).
+			Count(&completed).Error; err != nil {
+			return err
+		}
+		if completed == 0 {
+			return errServiceNotReviewable
+		}
+
+		var count int64
+		if err := tx.Model(&models.Review{}).Where("service_id = ?", review.ServiceID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return errReviewExists
+		}
+
+		if err := tx.Create(&review).Error; err != nil {
+			return err
+		}
+		return recalculateRating(tx, review.InstallerID)
+	})
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Instalador não encontrado"})
+		return
+	case errors.Is(err, errInstallerNotApproved):
+		c.JSON(http.StatusConflict, gin.H{"error": "Instalador não está aprovado"})
+		return
+	case errors.Is(err, errServiceNotReviewable):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Só é possível avaliar serviços concluídos por este instalador para você"})
+		return
+	case errors.Is(err, errReviewExists):
+		c.JSON(http.StatusConflict, gin.H{"error": "Este serviço já foi avaliado"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao salvar avaliação"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, review)
+}
+
+// ListReviews lista as avaliações do instalador, das mais recentes para as
+// mais antigas, com paginação por limit e offset.
+func ListReviews(c *gin.Context) {
+	var installer models.User
+	if err := database.DB.Where("id = ? AND role = ?", c.Param("id"), models.RoleInstalador).
+		First(&installer).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Instalador não encontrado"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	var reviews []models.Review
+	if err := database.DB.Where("installer_id = ?", installer.ID).
+		Order("created_at DESC").
+		Limit(limit).Offset(offset).
+		Find(&reviews).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao listar avaliações"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"items":          reviews,
+		"total":          installer.ReviewCount,
+		"average_rating": installer.AverageRating,
+	})
+}
+
+// ReplyReview grava (ou altera) a resposta do instalador autenticado a uma
+// avaliação recebida.
+func ReplyReview(c *gin.Context) {
+	var body struct {
+		Reply string `json:"reply"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil || strings.TrimSpace(body.Reply) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "reply é obrigatório"})
+		return
+	}
+
+	var review models.Review
+	if err := database.DB.Where("id = ? AND installer_id = ?", c.Param("review_id"), c.GetString("user_id")).
+		First(&review).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Avaliação não encontrada"})
+		return
+	}
+
+	now := time.Now()
+	review.Reply = strings.TrimSpace(body.Reply)
+	review.RepliedAt = &now
+	if err := database.DB.Model(&review).Updates(map[string]interface{}{
+		"reply":      review.Reply,
+		"replied_at": review.RepliedAt,
+	}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao salvar resposta"})
+		return
+	}
+
+	c.JSON(http.StatusOK, review)
+}
+
+// DeleteReview remove uma avaliação (moderação) e recalcula a nota do
+// instalador.
+func DeleteReview(c *gin.Context) {
+	installerID := c.Param("id")
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if _, err := lockInstaller(tx, installerID); err != nil {
+			return err
+		}
+		result := tx.Where("id = ? AND installer_id = ?", c.Param("review_id"), installerID).Delete(&models.Review{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return recalculateRating(tx, installerID)
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Avaliação não encontrada"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao remover avaliação"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Avaliação removida com sucesso"})
+}
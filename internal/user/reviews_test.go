@@ -0,0 +1,194 @@
+package user
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"user-service/internal/database"
+	"user-service/internal/user/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// completeService registra, pelo backend de pedidos, um serviço concluído do
+// instalador para o cliente e retorna seu service_id.
+func completeService(t *testing.T, r *gin.Engine, installer, client models.User) string {
+	t.Helper()
+	serviceID := "pedido-" + uuid.NewString()
+	key := createTestAPIKey(t, []string{models.ScopeInstallerBookingsWrite}, nil)
+	path := "/user/" + installer.ID + "/bookings/" + serviceID + "/complete"
+	if w := doAPIKeyRequest(r, http.MethodPost, path, key, `{"client_id":"`+client.ID+`"}`); w.Code != http.StatusOK {
+		t.Fatalf("concluir serviço: status = %d: %s", w.Code, w.Body)
+	}
+	return serviceID
+}
+
+func postReview(r *gin.Engine, installer models.User, token, serviceID string, rating int) (int, string) {
+	body, _ := json.Marshal(map[string]interface{}{"service_id": serviceID, "rating": rating, "comment": "Serviço de teste"})
+	w := doRequest(r, http.MethodPost, "/user/"+installer.ID+"/reviews", token, string(body))
+	return w.Code, w.Body.String()
+}
+
+func reloadUser(t *testing.T, user *models.User) {
+	t.Helper()
+	if err := database.DB.First(user, "id = ?", user.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+}
+
+// A nota média e o total de avaliações acompanham cada avaliação criada ou
+// removida.
+func TestReviewsRecalculateRating(t *testing.T) {
+	r := newTestRouter()
+	installer := createTestUser(t, models.RoleInstalador, models.StatusApproved)
+	first := createTestUser(t, models.RoleCliente, models.StatusApproved)
+	second := createTestUser(t, models.RoleCliente, models.StatusApproved)
+
+	if code, body := postReview(r, installer, bearerToken(t, first), completeService(t, r, installer, first), 5); code != http.StatusCreated {
+		t.Fatalf("primeira avaliação: status = %d: %s", code, body)
+	}
+	if code, body := postReview(r, installer, bearerToken(t, second), completeService(t, r, installer, second), 2); code != http.StatusCreated {
+		t.Fatalf("segunda avaliação: status = %d: %s", code, body)
+	}
+	reloadUser(t, &installer)
+	if installer.AverageRating != 3.5 || installer.ReviewCount != 2 {
+		t.Fatalf("nota = %v, avaliações = %d, esperado 3.5 e 2", installer.AverageRating, installer.ReviewCount)
+	}
+
+	var list struct {
+		Items         []models.Review `json:"items"`
+		Total         int             `json:"total"`
+		AverageRating float64         `json:"average_rating"`
+	}
+	w := doRequest(r, http.MethodGet, "/user/"+installer.ID+"/reviews", "", "")
+	if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &list) != nil || len(list.Items) != 2 || list.Total != 2 || list.AverageRating != 3.5 {
+		t.Fatalf("listar avaliações: status = %d: %s", w.Code, w.Body)
+	}
+
+	admin := bearerToken(t, createTestUser(t, models.RoleAdmin, models.StatusApproved))
+	var lowest models.Review
+	for _, review := range list.Items {
+		if review.Rating == 2 {
+			lowest = review
+		}
+	}
+	if w := doRequest(r, http.MethodDelete, "/user/"+installer.ID+"/reviews/"+lowest.ID, admin, ""); w.Code != http.StatusOK {
+		t.Fatalf("remover avaliação: status = %d: %s", w.Code, w.Body)
+	}
+	reloadUser(t, &installer)
+	if installer.AverageRating != 5 || installer.ReviewCount != 1 {
+		t.Errorf("após remover: nota = %v, avaliações = %d, esperado 5 e 1", installer.AverageRating, installer.ReviewCount)
+	}
+}
+
+func TestReviewOnePerService(t *testing.T) {
+	r := newTestRouter()
+	installer := createTestUser(t, models.RoleInstalador, models.StatusApproved)
+	client := createTestUser(t, models.RoleCliente, models.StatusApproved)
+	token := bearerToken(t, client)
+	serviceID := completeService(t, r, installer, client)
+
+	if code, body := postReview(r, installer, token, serviceID, 4); code != http.StatusCreated {
+		t.Fatalf("avaliação: status = %d: %s", code, body)
+	}
+	if code, _ := postReview(r, installer, token, serviceID, 1); code != http.StatusConflict {
+		t.Errorf("segunda avaliação do serviço: status = %d, esperado %d", code, http.StatusConflict)
+	}
+	reloadUser(t, &installer)
+	if installer.AverageRating != 4 || installer.ReviewCount != 1 {
+		t.Errorf("nota = %v, avaliações = %d, esperado 4 e 1", installer.AverageRating, installer.ReviewCount)
+	}
+}
+
+// Só o cliente do serviço concluído avalia, e só instaladores aprovados.
+func TestReviewRequiresCompletedService(t *testing.T) {
+	r := newTestRouter()
+	installer := createTestUser(t, models.RoleInstalador, models.StatusApproved)
+	client := createTestUser(t, models.RoleCliente, models.StatusApproved)
+	other := createTestUser(t, models.RoleCliente, models.StatusApproved)
+	serviceID := completeService(t, r, installer, client)
+
+	if code, _ := postReview(r, installer, bearerToken(t, client), "pedido-inexistente", 5); code != http.StatusForbidden {
+		t.Errorf("serviço não registrado: status = %d, esperado %d", code, http.StatusForbidden)
+	}
+	if code, _ := postReview(r, installer, bearerToken(t, other), serviceID, 5); code != http.StatusForbidden {
+		t.Errorf("serviço de outro cliente: status = %d, esperado %d", code, http.StatusForbidden)
+	}
+	if code, _ := postReview(r, installer, bearerToken(t, client), serviceID, 6); code != http.StatusBadRequest {
+		t.Errorf("nota fora da escala: status = %d, esperado %d", code, http.StatusBadRequest)
+	}
+
+	suspended := createTestUser(t, models.RoleInstalador, models.StatusApproved)
+	suspendedService := completeService(t, r, suspended, client)
+	if err := database.DB.Model(&suspended).Updates(map[string]interface{}{"status": models.StatusSuspended, "authorized": false}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if code, _ := postReview(r, suspended, bearerToken(t, client), suspendedService, 5); code != http.StatusConflict {
+		t.Errorf("instalador suspenso: status = %d, esperado %d", code, http.StatusConflict)
+	}
+}
+
+func TestCompleteBooking(t *testing.T) {
+	r := newTestRouter()
+	installer := createTestUser(t, models.RoleInstalador, models.StatusApproved)
+	client := createTestUser(t, models.RoleCliente, models.StatusApproved)
+	key := createTestAPIKey(t, []string{models.ScopeInstallerBookingsWrite}, nil)
+	serviceID := completeService(t, r, installer, client)
+	path := "/user/" + installer.ID + "/bookings/" + serviceID + "/complete"
+
+	var first models.Booking
+	if err := database.DB.First(&first, "service_id = ?", serviceID).Error; err != nil || first.CompletedAt == nil {
+		t.Fatalf("serviço não registrado como concluído: %+v (err = %v)", first, err)
+	}
+	if w := doAPIKeyRequest(r, http.MethodPost, path, key, `{"client_id":"`+client.ID+`"}`); w.Code != http.StatusOK {
+		t.Fatalf("repetir conclusão: status = %d: %s", w.Code, w.Body)
+	}
+	var again models.Booking
+	if err := database.DB.First(&again, "service_id = ?", serviceID).Error; err != nil || !again.CompletedAt.Equal(*first.CompletedAt) {
+		t.Errorf("data de conclusão alterada: %v -> %v", first.CompletedAt, again.CompletedAt)
+	}
+
+	other := createTestUser(t, models.RoleCliente, models.StatusApproved)
+	if w := doAPIKeyRequest(r, http.MethodPost, path, key, `{"client_id":"`+other.ID+`"}`); w.Code != http.StatusNotFound {
+		t.Errorf("serviço de outro cliente: status = %d, esperado %d", w.Code, http.StatusNotFound)
+	}
+	newPath := "/user/" + installer.ID + "/bookings/pedido-" + uuid.NewString() + "/complete"
+	for name, body := range map[string]string{
+		"sem client_id":           `{}`,
+		"cliente desconhecido":    `{"client_id":"` + uuid.NewString() + `"}`,
+		"instalador como cliente": `{"client_id":"` + installer.ID + `"}`,
+	} {
+		if w := doAPIKeyRequest(r, http.MethodPost, newPath, key, body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, esperado %d", name, w.Code, http.StatusBadRequest)
+		}
+	}
+	if w := doAPIKeyRequest(r, http.MethodPost, "/user/"+client.ID+"/bookings/x/complete", key, `{"client_id":"`+client.ID+`"}`); w.Code != http.StatusNotFound {
+		t.Errorf("usuário que não é instalador: status = %d, esperado %d", w.Code, http.StatusNotFound)
+	}
+}
+
+// O instalador responde às avaliações que recebeu; as de outros não são
+// encontradas.
+func TestReplyReview(t *testing.T) {
+	r := newTestRouter()
+	installer := createTestUser(t, models.RoleInstalador, models.StatusApproved)
+	client := createTestUser(t, models.RoleCliente, models.StatusApproved)
+	code, body := postReview(r, installer, bearerToken(t, client), completeService(t, r, installer, client), 3)
+	var review models.Review
+	if code != http.StatusCreated || json.Unmarshal([]byte(body), &review) != nil {
+		t.Fatalf("avaliação: status = %d: %s", code, body)
+	}
+
+	path := "/user/me/reviews/" + review.ID + "/reply"
+	other := bearerToken(t, createTestUser(t, models.RoleInstalador, models.StatusApproved))
+	if w := doRequest(r, http.MethodPut, path, other, `{"reply":"Não é minha"}`); w.Code != http.StatusNotFound {
+		t.Errorf("outro instalador: status = %d, esperado %d", w.Code, http.StatusNotFound)
+	}
+	if w := doRequest(r, http.MethodPut, path, bearerToken(t, installer), `{"reply":" Obrigado! "}`); w.Code != http.StatusOK {
+		t.Fatalf("responder: status = %d: %s", w.Code, w.Body)
+	}
+	if err := database.DB.First(&review, "id = ?", review.ID).Error; err != nil || review.Reply != "Obrigado!" || review.RepliedAt == nil {
+		t.Errorf("resposta não gravada: %+v (err = %v)", review, err)
+	}
+}
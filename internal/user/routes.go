@@ -2,24 +2,107 @@ package user
 
 import (
 	"user-service/internal/middlewares"
+	"user-service/internal/user/models"
+	"user-service/internal/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
 func RegisterRoutes(r *gin.Engine) {
+	adminOnly := middlewares.RequireRole(models.RoleAdmin)
+	ownerOrAdmin := middlewares.RequireOwnerOrRole(models.RoleAdmin)
+	notImpersonating := middlewares.BlockImpersonation()
+	installerOnly := middlewares.RequireRole(models.RoleInstalador)
+	clientOnly := middlewares.RequireRole(models.RoleCliente)
+
+	r.GET("/.well-known/jwks.json", GetJWKS)
+
 	group := r.Group("/user")
 	{
 		group.POST("/register", RegisterUser)
 		group.POST("/login", LoginUser)
+		group.POST("/login/mfa", LoginMFA)
+		group.POST("/login/mfa/setup", LoginMFASetup)
+		group.POST("/login/oidc", LoginOIDC)
+		group.POST("/token/refresh", RefreshAccessToken)
+		group.POST("/logout", middlewares.AuthMiddleware(), LogoutUser)
+		group.POST("/password/forgot", ForgotPassword)
+		group.POST("/password/reset", ResetPassword)
+		group.GET("/verify-email", VerifyEmail)
+		group.POST("/verify-email/resend", ResendEmailVerification)
 		group.GET("/public/installers", ListPublicInstallers)
-		group.GET("/list", middlewares.AuthMiddleware(), ListUsers)
-		group.GET("/installers/pending", middlewares.AuthMiddleware(), ListPendingInstallers)
-		group.PATCH("/:id/authorize", middlewares.AuthMiddleware(), AuthorizeUser)
-		group.PUT("/:id/password", middlewares.AuthMiddleware(), UpdatePassword)
-		group.PUT("/:id", middlewares.AuthMiddleware(), UpdateUser)
-		group.PUT("/:id/photo", middlewares.AuthMiddleware(), UpdateUserPhoto)
-		group.DELETE("/:id", middlewares.AuthMiddleware(), DeleteUser)
+		group.GET("/list", middlewares.AuthMiddleware(), adminOnly, ListUsers)
+		group.GET("/installers/pending", middlewares.AuthMiddleware(), adminOnly, ListPendingInstallers)
+		group.PATCH("/:id/authorize", middlewares.AuthMiddleware(), adminOnly, AuthorizeUser)
+		group.POST("/:id/reject", middlewares.AuthMiddleware(), adminOnly, RejectUser)
+		group.POST("/:id/suspend", middlewares.AuthMiddleware(), adminOnly, SuspendUser)
+		group.POST("/:id/reinstate", middlewares.AuthMiddleware(), adminOnly, ReinstateUser)
+		group.GET("/:id/status-history", middlewares.AuthMiddleware(), adminOnly, GetUserStatusHistory)
+		group.GET("/:id/email-deliveries", middlewares.AuthMiddleware(), adminOnly, ListEmailDeliveries)
+		group.GET("/:id/documents", middlewares.AuthMiddleware(), adminOnly, ListUserDocuments)
+		group.GET("/:id/documents/:document_id/download", middlewares.AuthMiddleware(), adminOnly, DownloadDocument)
+		group.PATCH("/:id/documents/:document_id/review", middlewares.AuthMiddleware(), adminOnly, ReviewDocument)
+		group.POST("/:id/unlock", middlewares.AuthMiddleware(), adminOnly, UnlockUser)
+		group.POST("/:id/impersonate", middlewares.AuthMiddleware(), adminOnly, ImpersonateUser)
+		group.GET("/audit-logs", middlewares.AuthMiddleware(), adminOnly, ListAuditLogs)
+		group.POST("/api-keys", middlewares.AuthMiddleware(), adminOnly, CreateAPIKey)
+		group.GET("/api-keys", middlewares.AuthMiddleware(), adminOnly, ListAPIKeys)
+		group.DELETE("/api-keys/:key_id", middlewares.AuthMiddleware(), adminOnly, RevokeAPIKey)
+		group.PUT("/:id/password", middlewares.AuthMiddleware(), notImpersonating, ownerOrAdmin, UpdatePassword)
+		group.PUT("/:id", middlewares.AuthMiddleware(), ownerOrAdmin, UpdateUser)
+		group.PUT("/:id/photo", middlewares.AuthMiddleware(), ownerOrAdmin, UpdateUserPhoto)
+		group.DELETE("/:id", middlewares.AuthMiddleware(), notImpersonating, ownerOrAdmin, DeleteUser)
 		group.GET("/public/installers/nearby", ListNearbyInstallers)
+		group.GET("/:id/reviews", ListReviews)
+		group.POST("/:id/reviews", middlewares.AuthMiddleware(), notImpersonating, clientOnly, CreateReview)
+		group.DELETE("/:id/reviews/:review_id", middlewares.AuthMiddleware(), adminOnly, DeleteReview)
+		group.GET("/:id/availability", GetInstallerAvailability)
+		group.GET("/catalog/specialties", ListSpecialties)
+		group.GET("/catalog/brands", ListChargerBrands)
+		group.POST("/catalog/specialties", middlewares.AuthMiddleware(), adminOnly, CreateSpecialty)
+		group.POST("/catalog/brands", middlewares.AuthMiddleware(), adminOnly, CreateChargerBrand)
+
+		// Rotas chamadas por outros serviços (X-API-Key)
+		group.PUT("/:id/stats", middlewares.APIKeyMiddleware(models.ScopeInstallerStatsWrite), UpdateInstallerStats)
+		group.POST("/:id/stats/increment", middlewares.APIKeyMiddleware(models.ScopeInstallerStatsWrite), IncrementInstallerStats)
+		group.POST("/:id/bookings", middlewares.APIKeyMiddleware(models.ScopeInstallerBookingsWrite), CreateBooking)
+		group.DELETE("/:id/bookings/:service_id", middlewares.APIKeyMiddleware(models.ScopeInstallerBookingsWrite), CancelBooking)
+		group.POST("/:id/bookings/:service_id/complete", middlewares.APIKeyMiddleware(models.ScopeInstallerBookingsWrite), CompleteBooking)
+
+	}
 
+	// Rotas do próprio usuário, com o ID resolvido a partir do token
+	me := r.Group("/user/me", middlewares.AuthMiddleware())
+	{
+		me.GET("", GetCurrentUser)
+		me.PUT("", UpdateUser)
+		me.DELETE("", notImpersonating, DeleteUser)
+		me.PUT("/photo", UpdateUserPhoto)
+		me.PUT("/password", notImpersonating, UpdatePassword)
+		me.POST("/mfa/setup", notImpersonating, SetupMFA)
+		me.POST("/mfa/confirm", notImpersonating, ConfirmMFA)
+		me.POST("/mfa/disable", notImpersonating, DisableMFA)
+		me.POST("/mfa/recovery-codes", notImpersonating, RegenerateRecoveryCodes)
+		me.GET("/sessions", ListSessions)
+		me.DELETE("/sessions/:session_id", notImpersonating, RevokeUserSession)
+		me.GET("/service-area", installerOnly, GetServiceArea)
+		me.PUT("/service-area", installerOnly, UpdateServiceArea)
+		me.DELETE("/service-area", installerOnly, DeleteServiceArea)
+		me.GET("/qualifications", installerOnly, GetMyQualifications)
+		me.PUT("/specialties", installerOnly, UpdateMySpecialties)
+		me.PUT("/brands", installerOnly, UpdateMyBrands)
+		me.PUT("/reviews/:review_id/reply", notImpersonating, installerOnly, ReplyReview)
+		me.GET("/availability", installerOnly, GetMyAvailability)
+		me.PUT("/availability/hours", installerOnly, UpdateWorkingHours)
+		me.POST("/availability/blocked-dates", installerOnly, BlockDate)
+		me.DELETE("/availability/blocked-dates/:date", installerOnly, UnblockDate)
+	}
+
+	// Documentos do instalador: também acessíveis com o token de cadastro,
+	// antes da aprovação
+	documents := r.Group("/user/me/documents", middlewares.AuthMiddlewareWithPurposes(utils.TokenPurposeOnboarding), installerOnly)
+	{
+		documents.POST("", UploadDocument)
+		documents.GET("", ListMyDocuments)
 	}
 }
@@ -0,0 +1,324 @@
+package user
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"user-service/internal/database"
+	"user-service/internal/geo"
+	"user-service/internal/user/models"
+	"user-service/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// defaultServiceRadiusKm é o raio de atendimento de instaladores sem área
+// definida (NEARBY_DEFAULT_RADIUS_KM, padrão 150 km).
+func defaultServiceRadiusKm() float64 {
+	if radius := utils.GetEnvFloat("NEARBY_DEFAULT_RADIUS_KM", 150); radius > 0 {
+		return radius
+	}
+	return 150
+}
+
+type ServiceAreaResponse struct {
+	RadiusKm float64                `json:"radius_km"`
+	Cities   []string               `json:"cities"`
+	States   []string               `json:"states"`
+	Polygon  map[string]interface{} `json:"polygon"`
+	Default  bool                   `json:"default"`
+}
+
+func newServiceAreaResponse(area models.ServiceArea) ServiceAreaResponse {
+	response := ServiceAreaResponse{
+		RadiusKm: area.RadiusKm,
+		Cities:   area.Cities,
+		States:   area.States,
+	}
+	if len(area.Polygon) > 0 {
+		response.Polygon = area.Polygon.GeoJSON()
+	}
+	if response.Cities == nil {
+		response.Cities = []string{}
+	}
+	if response.States == nil {
+		response.States = []string{}
+	}
+	return response
+}
+
+// clientLocation é o ponto pesquisado pelo cliente. Cidade e UF vêm da
+// consulta ou, quando alguma área por cidade/UF precisa delas, da
+// geocodificação reversa (feita no máximo uma vez por busca).
+type clientLocation struct {
+	Lat, Lng float64
+	City, UF string
+	resolved bool
+}
+
+func (l *clientLocation) cityAndUF() (string, string) {
+	if !l.resolved && (l.City == "" || l.UF == "") {
+		city, uf, err := reverseGeocode(l.Lat, l.Lng)
+		if err != nil {
+			fmt.Println("⚠️ Erro na geocodificação reversa:", err)
+		}
+		if l.City == "" {
+			l.City = city
+		}
+		if l.UF == "" {
+			l.UF = uf
+		}
+	}
+	l.resolved = true
+	return l.City, l.UF
+}
+
+// Cache da geocodificação reversa, por ponto arredondado a 0,01° (~1 km), e
+// limite de consultas por minuto à API paga (REVERSE_GEOCODE_MAX_PER_MINUTE,
+// padrão 60). Acima do limite a busca segue sem cidade/UF, casando só por
+// raio e polígono ou pelos city/state informados na consulta.
+var (
+	geocodeMu     sync.Mutex
+	geocodeCache  = map[string]geocodeEntry{}
+	geocodeWindow time.Time
+	geocodeCalls  int
+)
+
+type geocodeEntry struct {
+	city, uf  string
+	err       error
+	expiresAt time.Time
+}
+
+var errGeocodeRateLimited = errors.New("limite de consultas de geocodificação reversa atingido")
+
+// reverseGeocode resolve cidade e UF do ponto usando o cache. Falhas ficam
+// em cache por um minuto, para não repetir a consulta a cada busca.
+func reverseGeocode(lat, lng float64) (string, string, error) {
+	key := fmt.Sprintf("%.2f,%.2f", lat, lng)
+	now := time.Now()
+
+	geocodeMu.Lock()
+	if entry, ok := geocodeCache[key]; ok && now.Before(entry.expiresAt) {
+		geocodeMu.Unlock()
+		return entry.city, entry.uf, entry.err
+	}
+	if now.Sub(geocodeWindow) >= time.Minute {
+		geocodeWindow, geocodeCalls = now, 0
+	}
+	if geocodeCalls >= utils.GetEnvInt("REVERSE_GEOCODE_MAX_PER_MINUTE", 60) {
+		geocodeMu.Unlock()
+		return "", "", errGeocodeRateLimited
+	}
+	geocodeCalls++
+	geocodeMu.Unlock()
+
+	city, uf, err := utils.BuscarCidade(lat, lng)
+
+	entry := geocodeEntry{city: city, uf: uf, err: err, expiresAt: now.Add(utils.GetEnvDuration("REVERSE_GEOCODE_CACHE_TTL", 24*time.Hour))}
+	if err != nil {
+		entry.expiresAt = now.Add(time.Minute)
+	}
+	geocodeMu.Lock()
+	// Evita crescimento indefinido do cache
+	if len(geocodeCache) > 10000 {
+		for k, e := range geocodeCache {
+			if now.After(e.expiresAt) {
+				delete(geocodeCache, k)
+			}
+		}
+		if len(geocodeCache) > 10000 {
+			geocodeCache = map[string]geocodeEntry{}
+		}
+	}
+	geocodeCache[key] = entry
+	geocodeMu.Unlock()
+
+	return city, uf, err
+}
+
+// serviceAreaCandidates restringe no banco os instaladores cuja área pode
+// cobrir o ponto: sem área, pelo raio padrão; com área, pelo raio dela, pela
+// caixa que contém o polígono ou pela cidade/UF do ponto. Se cidade e UF não
+// forem conhecidas, entram todas as áreas com cidades ou UFs. A confirmação
+// exata é feita em serviceAreaCovers.
+func serviceAreaCandidates(query *gorm.DB, loc *clientLocation) *gorm.DB {
+	latPerKm, lngPerKm := geo.DegreesPerKm(loc.Lat)
+	minLat, maxLat, minLng, maxLng := geo.BoundingBox(loc.Lat, loc.Lng, defaultServiceRadiusKm())
+
+	covers := database.DB.
+		Where("service_areas.user_id IS NULL AND users.latitude BETWEEN ? AND ? AND users.longitude BETWEEN ? AND ?",
+			minLat, maxLat, minLng, maxLng).
+		Or("service_areas.radius_km > 0 AND ABS(users.latitude - ?) <= service_areas.radius_km * ? AND ABS(users.longitude - ?) <= service_areas.radius_km * ?",
+			loc.Lat, latPerKm, loc.Lng, lngPerKm).
+		Or("service_areas.polygon_min_lat <= ? AND service_areas.polygon_max_lat >= ? AND service_areas.polygon_min_lng <= ? AND service_areas.polygon_max_lng >= ?",
+			loc.Lat, loc.Lat, loc.Lng, loc.Lng)
+
+	// Só resolve cidade/UF (geocodificação reversa) se houver áreas por
+	// cidade ou UF
+	var withPlaces []string
+	database.DB.Model(&models.ServiceArea{}).Where("place_ufs <> '' OR place_cities <> ''").Limit(1).Pluck("user_id", &withPlaces)
+	if len(withPlaces) > 0 {
+		if city, uf := loc.cityAndUF(); city != "" || uf != "" {
+			covers = covers.
+				Or("service_areas.place_ufs LIKE ?", "%|"+strings.ToUpper(uf)+"|%").
+				Or("service_areas.place_cities LIKE ?", "%|"+geo.NormalizeName(city)+"|%")
+		} else {
+			covers = covers.Or("service_areas.place_ufs <> '' OR service_areas.place_cities <> ''")
+		}
+	}
+
+	return query.
+		Joins("LEFT JOIN service_areas ON service_areas.user_id = users.id").
+		Where(covers)
+}
+
+// serviceAreaCovers informa se o instalador atende o ponto do cliente. Sem
+// área definida, vale o raio padrão a partir do endereço do instalador.
+func serviceAreaCovers(area *models.ServiceArea, installer models.User, loc *clientLocation) bool {
+	hasHome := installer.Latitude != 0 || installer.Longitude != 0
+
+	if area == nil {
+		return hasHome && geo.DistanceKm(loc.Lat, loc.Lng, installer.Latitude, installer.Longitude) <= defaultServiceRadiusKm()
+	}
+
+	if area.RadiusKm > 0 && hasHome &&
+		geo.DistanceKm(loc.Lat, loc.Lng, installer.Latitude, installer.Longitude) <= area.RadiusKm {
+		return true
+	}
+	if len(area.Polygon) > 0 && area.Polygon.Contains(loc.Lat, loc.Lng) {
+		return true
+	}
+	if len(area.States) == 0 && len(area.Cities) == 0 {
+		return false
+	}
+
+	city, uf := loc.cityAndUF()
+	for _, state := range area.States {
+		if strings.EqualFold(state, uf) {
+			return true
+		}
+	}
+	for _, entry := range area.Cities {
+		name, state, _ := strings.Cut(entry, "/")
+		if geo.NormalizeName(name) == geo.NormalizeName(city) && (state == "" || strings.EqualFold(state, uf)) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetServiceArea retorna a área de atendimento do instalador autenticado.
+func GetServiceArea(c *gin.Context) {
+	var area models.ServiceArea
+	result := database.DB.Where("user_id = ?", c.GetString("user_id")).Limit(1).Find(&area)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar área de atendimento"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		response := newServiceAreaResponse(models.ServiceArea{RadiusKm: defaultServiceRadiusKm()})
+		response.Default = true
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	c.JSON(http.StatusOK, newServiceAreaResponse(area))
+}
+
+// UpdateServiceArea define a área de atendimento do instalador autenticado:
+// raio em km, cidades, UFs e/ou polígono GeoJSON (Polygon ou MultiPolygon).
+func UpdateServiceArea(c *gin.Context) {
+	var body struct {
+		RadiusKm float64         `json:"radius_km"`
+		Cities   []string        `json:"cities"`
+		States   []string        `json:"states"`
+		Polygon  json.RawMessage `json:"polygon"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON"})
+		return
+	}
+
+	maxRadius := float64(utils.GetEnvInt("SERVICE_AREA_MAX_RADIUS_KM", 500))
+	if body.RadiusKm < 0 || body.RadiusKm > maxRadius {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("radius_km deve estar entre 0 e %.0f", maxRadius)})
+		return
+	}
+
+	area := models.ServiceArea{
+		UserID:   c.GetString("user_id"),
+		RadiusKm: body.RadiusKm,
+		Cities:   []string{},
+		States:   []string{},
+	}
+
+	for _, state := range body.States {
+		state = strings.ToUpper(strings.TrimSpace(state))
+		if !geo.IsUF(state) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("UF inválida: %q", state)})
+			return
+		}
+		area.States = append(area.States, state)
+	}
+
+	for _, city := range body.Cities {
+		name, state, hasState := strings.Cut(city, "/")
+		name = strings.TrimSpace(name)
+		state = strings.ToUpper(strings.TrimSpace(state))
+		if name == "" || (hasState && !geo.IsUF(state)) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Cidade inválida: %q (use \"Cidade\" ou \"Cidade/UF\")", city)})
+			return
+		}
+		if hasState {
+			name += "/" + state
+		}
+		area.Cities = append(area.Cities, name)
+	}
+
+	if len(body.Polygon) > 0 && string(body.Polygon) != "null" {
+		polygon, err := geo.ParseGeoJSON(body.Polygon)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		// O polígono é testado em toda busca; limita seu tamanho
+		maxVertices := utils.GetEnvInt("SERVICE_AREA_MAX_VERTICES", 1000)
+		if polygon.Vertices() > maxVertices {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("O polígono deve ter no máximo %d vértices", maxVertices)})
+			return
+		}
+		area.Polygon = polygon
+	}
+
+	if area.RadiusKm == 0 && len(area.Cities) == 0 && len(area.States) == 0 && len(area.Polygon) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Informe ao menos um critério: radius_km, cities, states ou polygon"})
+		return
+	}
+
+	if err := database.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns(append([]string{"radius_km", "cities", "states", "polygon", "updated_at"}, models.ServiceAreaIndexColumns...)),
+	}).Create(&area).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao salvar área de atendimento"})
+		return
+	}
+
+	c.JSON(http.StatusOK, newServiceAreaResponse(area))
+}
+
+// DeleteServiceArea remove a área de atendimento, voltando ao raio padrão.
+func DeleteServiceArea(c *gin.Context) {
+	if err := database.DB.Where("user_id = ?", c.GetString("user_id")).Delete(&models.ServiceArea{}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao remover área de atendimento"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Área de atendimento removida; vale o raio padrão"})
+}
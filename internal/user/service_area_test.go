@@ -0,0 +1,185 @@
+package user
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"user-service/internal/database"
+	"user-service/internal/geo"
+	"user-service/internal/user/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Quadrado de 1° ao redor de (-23.5, -46.5)
+const testPolygon = `{"type":"Polygon","coordinates":[[[-47,-24],[-46,-24],[-46,-23],[-47,-23],[-47,-24]]]}`
+
+// createTestInstaller cria um instalador aprovado com endereço em (lat, lng)
+// e, se informada, a área de atendimento.
+func createTestInstaller(t *testing.T, lat, lng float64, area *models.ServiceArea) models.User {
+	t.Helper()
+	installer := createTestUser(t, models.RoleInstalador, models.StatusApproved)
+	if err := database.DB.Model(&installer).Updates(map[string]interface{}{"latitude": lat, "longitude": lng}).Error; err != nil {
+		t.Fatal(err)
+	}
+	installer.Latitude, installer.Longitude = lat, lng
+	if area != nil {
+		area.UserID = installer.ID
+		if err := database.DB.Create(area).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	return installer
+}
+
+// nearbyIDs retorna os IDs listados pela busca de instaladores próximos.
+func nearbyIDs(t *testing.T, r *gin.Engine, query string) map[string]bool {
+	t.Helper()
+	ids := make(map[string]bool)
+	for _, item := range nearbyResults(t, r, query) {
+		ids[item.ID] = true
+	}
+	return ids
+}
+
+func TestServiceAreaUpdateGetDelete(t *testing.T) {
+	r := newTestRouter()
+	installer := createTestUser(t, models.RoleInstalador, models.StatusApproved)
+	token := bearerToken(t, installer)
+
+	var area ServiceAreaResponse
+	w := doRequest(r, http.MethodGet, "/user/me/service-area", token, "")
+	if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &area) != nil || !area.Default || area.RadiusKm != defaultServiceRadiusKm() {
+		t.Fatalf("área padrão: status = %d: %s", w.Code, w.Body)
+	}
+
+	body := `{"radius_km":30,"cities":[" Campinas / sp ","Santos"],"states":["rj"],"polygon":` + testPolygon + `}`
+	if w := doRequest(r, http.MethodPut, "/user/me/service-area", token, body); w.Code != http.StatusOK {
+		t.Fatalf("salvar área: status = %d: %s", w.Code, w.Body)
+	}
+	// Salvar de novo substitui a área
+	if w := doRequest(r, http.MethodPut, "/user/me/service-area", token, body); w.Code != http.StatusOK {
+		t.Fatalf("atualizar área: status = %d: %s", w.Code, w.Body)
+	}
+
+	area = ServiceAreaResponse{}
+	w = doRequest(r, http.MethodGet, "/user/me/service-area", token, "")
+	if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &area) != nil {
+		t.Fatalf("buscar área: status = %d: %s", w.Code, w.Body)
+	}
+	if area.Default || area.RadiusKm != 30 || strings.Join(area.Cities, ",") != "Campinas/SP,Santos" ||
+		strings.Join(area.States, ",") != "RJ" || area.Polygon["type"] != "MultiPolygon" {
+		t.Errorf("área inesperada: %+v", area)
+	}
+
+	var stored models.ServiceArea
+	if err := database.DB.First(&stored, "user_id = ?", installer.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if stored.PlaceUFs != "|RJ|SP|" || stored.PlaceCities != "|santos|" ||
+		stored.PolygonMinLat == nil || *stored.PolygonMinLat != -24 || *stored.PolygonMaxLng != -46 {
+		t.Errorf("colunas de filtro: ufs = %q, cidades = %q, caixa = %v..%v", stored.PlaceUFs, stored.PlaceCities, stored.PolygonMinLat, stored.PolygonMaxLng)
+	}
+
+	if w := doRequest(r, http.MethodDelete, "/user/me/service-area", token, ""); w.Code != http.StatusOK {
+		t.Fatalf("remover área: status = %d: %s", w.Code, w.Body)
+	}
+	area = ServiceAreaResponse{}
+	w = doRequest(r, http.MethodGet, "/user/me/service-area", token, "")
+	if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &area) != nil || !area.Default {
+		t.Errorf("após remover: status = %d: %s", w.Code, w.Body)
+	}
+}
+
+func TestUpdateServiceAreaValidation(t *testing.T) {
+	t.Setenv("SERVICE_AREA_MAX_VERTICES", "4")
+	r := newTestRouter()
+	token := bearerToken(t, createTestUser(t, models.RoleInstalador, models.StatusApproved))
+
+	cases := map[string]string{
+		"sem critérios":      `{}`,
+		"raio negativo":      `{"radius_km":-1}`,
+		"raio acima do teto": `{"radius_km":501}`,
+		"UF inválida":        `{"states":["XX"]}`,
+		"cidade vazia":       `{"cities":[" /SP"]}`,
+		"UF da cidade":       `{"cities":["Campinas/XX"]}`,
+		"polígono aberto":    `{"polygon":{"type":"Polygon","coordinates":[[[-47,-24],[-46,-24],[-46,-23]]]}}`,
+		"tipo não suportado": `{"polygon":{"type":"Point","coordinates":[-46,-23]}}`,
+		"vértices demais":    `{"polygon":` + testPolygon + `}`,
+	}
+	for name, body := range cases {
+		if w := doRequest(r, http.MethodPut, "/user/me/service-area", token, body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, esperado %d", name, w.Code, http.StatusBadRequest)
+		}
+	}
+
+	client := bearerToken(t, createTestUser(t, models.RoleCliente, models.StatusApproved))
+	if w := doRequest(r, http.MethodPut, "/user/me/service-area", client, `{"radius_km":10}`); w.Code != http.StatusForbidden {
+		t.Errorf("cliente: status = %d, esperado %d", w.Code, http.StatusForbidden)
+	}
+}
+
+// A busca lista quem atende o ponto por raio, polígono, UF ou cidade e deixa
+// de fora quem não atende. Cidade e UF vêm da consulta.
+func TestNearbyMatchesServiceArea(t *testing.T) {
+	r := newTestRouter()
+	polygon, err := geo.ParseGeoJSON([]byte(testPolygon))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Ponto pesquisado: (-23.5, -46.5), em Guarulhos/SP
+	defaultNear := createTestInstaller(t, -23.6, -46.6, nil)
+	defaultFar := createTestInstaller(t, -12.9, -38.5, nil)
+	byRadius := createTestInstaller(t, -22.9, -47.06, &models.ServiceArea{RadiusKm: 100})
+	radiusTooSmall := createTestInstaller(t, -22.9, -47.06, &models.ServiceArea{RadiusKm: 10})
+	byPolygon := createTestInstaller(t, -3.7, -38.5, &models.ServiceArea{Polygon: polygon})
+	byState := createTestInstaller(t, -3.7, -38.5, &models.ServiceArea{States: []string{"SP"}})
+	otherState := createTestInstaller(t, -3.7, -38.5, &models.ServiceArea{States: []string{"CE"}})
+	byCity := createTestInstaller(t, -3.7, -38.5, &models.ServiceArea{Cities: []string{"Guarulhos"}})
+	byCityUF := createTestInstaller(t, -3.7, -38.5, &models.ServiceArea{Cities: []string{"Guarulhos/SP"}})
+	cityOtherUF := createTestInstaller(t, -3.7, -38.5, &models.ServiceArea{Cities: []string{"Guarulhos/RJ"}})
+	pending := createTestUser(t, models.RoleInstalador, models.StatusPending)
+	if err := database.DB.Create(&models.ServiceArea{UserID: pending.ID, States: []string{"SP"}}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	ids := nearbyIDs(t, r, "lat=-23.5&lng=-46.5&city=Guarulhos&state=sp")
+	for name, want := range map[string]struct {
+		user   models.User
+		listed bool
+	}{
+		"raio padrão":         {defaultNear, true},
+		"fora do raio padrão": {defaultFar, false},
+		"raio da área":        {byRadius, true},
+		"raio pequeno":        {radiusTooSmall, false},
+		"polígono":            {byPolygon, true},
+		"UF":                  {byState, true},
+		"outra UF":            {otherState, false},
+		"cidade":              {byCity, true},
+		"cidade/UF":           {byCityUF, true},
+		"cidade de outra UF":  {cityOtherUF, false},
+		"pendente":            {pending, false},
+	} {
+		if ids[want.user.ID] != want.listed {
+			t.Errorf("%s: listado = %v, esperado %v", name, ids[want.user.ID], want.listed)
+		}
+	}
+
+	// Sem cidade/UF na consulta nem geocodificação disponível, as áreas por
+	// cidade ou UF não casam
+	ids = nearbyIDs(t, r, fmt.Sprintf("lat=%v&lng=%v", -23.55, -46.55))
+	if !ids[byPolygon.ID] || ids[byState.ID] || ids[byCity.ID] {
+		t.Errorf("sem cidade/UF: polígono = %v, UF = %v, cidade = %v", ids[byPolygon.ID], ids[byState.ID], ids[byCity.ID])
+	}
+}
+
+func TestReverseGeocodeRespectsRateLimit(t *testing.T) {
+	t.Setenv("REVERSE_GEOCODE_MAX_PER_MINUTE", "0")
+	if _, _, err := reverseGeocode(-23.5505, -46.6333); !errors.Is(err, errGeocodeRateLimited) {
+		t.Fatalf("err = %v, esperado limite atingido", err)
+	}
+}
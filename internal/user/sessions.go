@@ -0,0 +1,70 @@
+package user
+
+import (
+	"net/http"
+	"time"
+	"user-service/internal/database"
+	"user-service/internal/revocation"
+	"user-service/internal/user/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SessionResponse struct {
+	ID         string    `json:"id"`
+	DeviceName string    `json:"device_name"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"`
+}
+
+// ListSessions lista as sessões ativas do usuário autenticado, indicando a atual.
+func ListSessions(c *gin.Context) {
+	var sessions []models.Session
+	if err := database.DB.
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", c.GetString("user_id"), time.Now()).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao listar sessões"})
+		return
+	}
+
+	currentID := c.GetString("session_id")
+	responses := make([]SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		responses = append(responses, SessionResponse{
+			ID:         session.ID,
+			DeviceName: session.DeviceName,
+			UserAgent:  session.UserAgent,
+			IP:         session.IP,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+			ExpiresAt:  session.ExpiresAt,
+			Current:    session.ID == currentID,
+		})
+	}
+
+	c.JSON(http.StatusOK, responses)
+}
+
+// RevokeUserSession encerra uma sessão do usuário autenticado (ex.: celular
+// perdido).
+func RevokeUserSession(c *gin.Context) {
+	var session models.Session
+	if err := database.DB.
+		Where("id = ? AND user_id = ?", c.Param("session_id"), c.GetString("user_id")).
+		First(&session).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Sessão não encontrada"})
+		return
+	}
+
+	if err := revocation.RevokeSession(session.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao encerrar sessão"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Sessão encerrada com sucesso"})
+}
@@ -0,0 +1,93 @@
+package user
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"user-service/internal/user/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func listTestSessions(t *testing.T, r *gin.Engine, token string) []SessionResponse {
+	t.Helper()
+	w := doRequest(r, http.MethodGet, "/user/me/sessions", token, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("listar sessões: status = %d: %s", w.Code, w.Body)
+	}
+	var sessions []SessionResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &sessions); err != nil {
+		t.Fatal(err)
+	}
+	return sessions
+}
+
+// Cada login abre uma sessão; a listagem marca a sessão do token usado.
+func TestListSessions(t *testing.T) {
+	r := newTestRouter()
+	user := createTestUser(t, models.RoleCliente, models.StatusApproved)
+	first := loginTestUser(t, r, user)
+	loginTestUser(t, r, user)
+
+	sessions := listTestSessions(t, r, first.Token)
+	if len(sessions) != 2 {
+		t.Fatalf("sessões = %d, esperado 2", len(sessions))
+	}
+	current := 0
+	for _, session := range sessions {
+		if session.Current {
+			current++
+		}
+	}
+	if current != 1 {
+		t.Errorf("sessões marcadas como atual = %d, esperado 1", current)
+	}
+}
+
+// Encerrar uma sessão invalida seus tokens de acesso e refresh, sem afetar as
+// demais sessões do usuário.
+func TestRevokeUserSession(t *testing.T) {
+	r := newTestRouter()
+	user := createTestUser(t, models.RoleCliente, models.StatusApproved)
+	lost := loginTestUser(t, r, user)
+	current := loginTestUser(t, r, user)
+
+	var lostID string
+	for _, session := range listTestSessions(t, r, current.Token) {
+		if !session.Current {
+			lostID = session.ID
+		}
+	}
+
+	if w := doRequest(r, http.MethodDelete, "/user/me/sessions/"+lostID, current.Token, ""); w.Code != http.StatusOK {
+		t.Fatalf("encerrar sessão: status = %d: %s", w.Code, w.Body)
+	}
+
+	if w := doRequest(r, http.MethodGet, "/user/me", lost.Token, ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("token de acesso da sessão encerrada: status = %d, esperado %d", w.Code, http.StatusUnauthorized)
+	}
+	if w := doRequest(r, http.MethodPost, "/user/token/refresh", "", refreshBody(lost.RefreshToken)); w.Code != http.StatusUnauthorized {
+		t.Errorf("refresh token da sessão encerrada: status = %d, esperado %d", w.Code, http.StatusUnauthorized)
+	}
+	if w := doRequest(r, http.MethodGet, "/user/me", current.Token, ""); w.Code != http.StatusOK {
+		t.Errorf("sessão atual: status = %d, esperado %d", w.Code, http.StatusOK)
+	}
+	if sessions := listTestSessions(t, r, current.Token); len(sessions) != 1 {
+		t.Errorf("sessões ativas = %d, esperado 1", len(sessions))
+	}
+}
+
+// A sessão de outro usuário não é encontrada.
+func TestRevokeOtherUserSession(t *testing.T) {
+	r := newTestRouter()
+	other := loginTestUser(t, r, createTestUser(t, models.RoleCliente, models.StatusApproved))
+	otherID := listTestSessions(t, r, other.Token)[0].ID
+
+	token := bearerToken(t, createTestUser(t, models.RoleCliente, models.StatusApproved))
+	if w := doRequest(r, http.MethodDelete, "/user/me/sessions/"+otherID, token, ""); w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, esperado %d: %s", w.Code, http.StatusNotFound, w.Body)
+	}
+	if w := doRequest(r, http.MethodGet, "/user/me", other.Token, ""); w.Code != http.StatusOK {
+		t.Errorf("sessão do outro usuário afetada: status = %d", w.Code)
+	}
+}
@@ -0,0 +1,149 @@
+package user
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"user-service/internal/database"
+	"user-service/internal/user/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UpdateInstallerStats é chamado pelo backend de pedidos (chave de API com
+// escopo installers:stats:write) para atualizar as métricas do instalador.
+// Apenas os campos enviados são alterados. A nota média é calculada a partir
+// das avaliações e não pode ser definida aqui.
+func UpdateInstallerStats(c *gin.Context) {
+	var body struct {
+		TotalServicesAccepted *int `json:"total_services_accepted"`
+		ServicesNotExecuted   *int `json:"services_not_executed"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON"})
+		return
+	}
+
+	updates := map[string]interface{}{}
+	if body.TotalServicesAccepted != nil {
+		if *body.TotalServicesAccepted < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "total_services_accepted não pode ser negativo"})
+			return
+		}
+		updates["total_services_accepted"] = *body.TotalServicesAccepted
+	}
+	if body.ServicesNotExecuted != nil {
+		if *body.ServicesNotExecuted < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "services_not_executed não pode ser negativo"})
+			return
+		}
+		updates["services_not_executed"] = *body.ServicesNotExecuted
+	}
+	if len(updates) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Nenhuma métrica informada"})
+		return
+	}
+
+	result := database.DB.Model(&models.User{}).
+		Where("id = ? AND role = ?", c.Param("id"), models.RoleInstalador).
+		Updates(updates)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao atualizar métricas"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Instalador não encontrado"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Métricas atualizadas com sucesso"})
+}
+
+// statsMetricColumns associa cada métrica somada por serviço à coluna do
+// instalador.
+var statsMetricColumns = []struct{ Metric, Column string }{
+	{models.MetricServicesAccepted, "total_services_accepted"},
+	{models.MetricServicesNotExecuted, "services_not_executed"},
+}
+
+// IncrementInstallerStats soma 1 às métricas informadas do instalador.
+// Chamado pelo backend de pedidos a cada serviço aceito ou não executado
+// (chave de API com escopo installers:stats:write). Cada métrica é somada uma
+// única vez por service_id, então a chamada pode ser repetida com segurança;
+// a soma é feita em uma única instrução, sem perder atualizações concorrentes.
+func IncrementInstallerStats(c *gin.Context) {
+	var body struct {
+		ServiceID           string `json:"service_id"`
+		ServicesAccepted    int    `json:"services_accepted"`
+		ServicesNotExecuted int    `json:"services_not_executed"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON"})
+		return
+	}
+	body.ServiceID = strings.TrimSpace(body.ServiceID)
+	if body.ServiceID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "service_id é obrigatório"})
+		return
+	}
+	if body.ServicesAccepted < 0 || body.ServicesAccepted > 1 || body.ServicesNotExecuted < 0 || body.ServicesNotExecuted > 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Os incrementos devem ser 0 ou 1 por serviço"})
+		return
+	}
+	if body.ServicesAccepted == 0 && body.ServicesNotExecuted == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Nenhuma métrica informada"})
+		return
+	}
+
+	increments := map[string]int{
+		models.MetricServicesAccepted:    body.ServicesAccepted,
+		models.MetricServicesNotExecuted: body.ServicesNotExecuted,
+	}
+
+	var installer models.User
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Select("id").Where("id = ? AND role = ?", c.Param("id"), models.RoleInstalador).First(&installer).Error; err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{}
+		for _, m := range statsMetricColumns {
+			if increments[m.Metric] == 0 {
+				continue
+			}
+			// Um evento já registrado indica que o serviço já foi contado
+			result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.InstallerStatsEvent{
+				UserID:    installer.ID,
+				ServiceID: body.ServiceID,
+				Metric:    m.Metric,
+			})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected > 0 {
+				updates[m.Column] = gorm.Expr(m.Column + " + 1")
+			}
+		}
+		if len(updates) > 0 {
+			if err := tx.Model(&installer).Updates(updates).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Select("id", "total_services_accepted", "services_not_executed").First(&installer, "id = ?", installer.ID).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Instalador não encontrado"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao atualizar métricas"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"total_services_accepted": installer.TotalServicesAccepted,
+		"services_not_executed":   installer.ServicesNotExecuted,
+	})
+}
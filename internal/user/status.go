@@ -0,0 +1,178 @@
+package user
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"user-service/internal/database"
+	"user-service/internal/revocation"
+	"user-service/internal/user/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var errInvalidTransition = errors.New("transição de status não permitida")
+
+// statusDeniedMessage explica por que uma conta não ativa não pode entrar.
+func statusDeniedMessage(status string) string {
+	switch status {
+	case models.StatusRejected:
+		return "Cadastro rejeitado"
+	case models.StatusSuspended:
+		return "Conta suspensa"
+	default:
+		return "Usuário ainda não autorizado"
+	}
+}
+
+// changeUserStatus aplica a transição de status, mantendo Authorized em
+// sincronia, e registra o histórico com o administrador e o motivo.
+func changeUserStatus(userID, to, reason, changedBy string) (models.User, error) {
+	var user models.User
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, "id = ?", userID).Error; err != nil {
+			return err
+		}
+
+		from := user.Status
+		if !models.CanTransition(from, to) {
+			return errInvalidTransition
+		}
+
+		if to == models.StatusApproved && user.Role == models.RoleInstalador {
+			missing, err := missingRequiredDocuments(tx, user.ID)
+			if err != nil {
+				return err
+			}
+			if len(missing) > 0 {
+				return &missingDocumentsError{missing: missing}
+			}
+		}
+
+		user.Status = to
+		user.Authorized = models.IsActiveStatus(to)
+		if err := tx.Model(&user).Updates(map[string]interface{}{
+			"status":     user.Status,
+			"authorized": user.Authorized,
+		}).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&models.UserStatusHistory{
+			UserID:     user.ID,
+			FromStatus: from,
+			ToStatus:   to,
+			Reason:     reason,
+			ChangedBy:  changedBy,
+		}).Error
+	})
+	if err != nil {
+		return user, err
+	}
+
+	// Conta que deixa de estar ativa perde as sessões imediatamente
+	if !user.Authorized {
+		if err := revocation.RevokeUser(user.ID, "status_"+to); err != nil {
+			return user, err
+		}
+	}
+	return user, nil
+}
+
+// respondStatusChange lê o motivo do corpo (obrigatório se requireReason),
+// aplica a transição e responde com o novo status.
+func respondStatusChange(c *gin.Context, to string, requireReason bool) {
+	var body struct {
+		Reason string `json:"reason"`
+	}
+	_ = c.ShouldBindJSON(&body)
+	reason := strings.TrimSpace(body.Reason)
+	if requireReason && reason == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "reason é obrigatório"})
+		return
+	}
+
+	user, err := changeUserStatus(c.Param("id"), to, reason, c.GetString("actor_id"))
+	var missingErr *missingDocumentsError
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	case errors.Is(err, errInvalidTransition):
+		c.JSON(http.StatusConflict, gin.H{
+			"error": fmt.Sprintf("Não é possível mudar o status de %q para %q", user.Status, to),
+		})
+		return
+	case errors.As(err, &missingErr):
+		c.JSON(http.StatusConflict, gin.H{
+			"error":             "Instalador com documentos obrigatórios pendentes",
+			"missing_documents": missingErr.missing,
+		})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao atualizar status do usuário"})
+		return
+	}
+
+	go notifyStatusChange(user, reason)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Status atualizado com sucesso",
+		"status":  user.Status,
+	})
+}
+
+// AuthorizeUser aprova o cadastro (pendente ou rejeitado anteriormente).
+func AuthorizeUser(c *gin.Context) {
+	respondStatusChange(c, models.StatusApproved, false)
+}
+
+// RejectUser rejeita um cadastro pendente ou suspenso. Exige motivo.
+func RejectUser(c *gin.Context) {
+	respondStatusChange(c, models.StatusRejected, true)
+}
+
+// SuspendUser suspende uma conta ativa e encerra suas sessões. Exige motivo.
+func SuspendUser(c *gin.Context) {
+	respondStatusChange(c, models.StatusSuspended, true)
+}
+
+// ReinstateUser reativa uma conta suspensa.
+func ReinstateUser(c *gin.Context) {
+	respondStatusChange(c, models.StatusReinstated, false)
+}
+
+// ListPendingInstallers lista os instaladores aguardando análise.
+func ListPendingInstallers(c *gin.Context) {
+	var users []models.User
+	if err := database.DB.Where("role = ? AND status = ?", models.RoleInstalador, models.StatusPending).
+		Order("created_at").
+		Find(&users).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao listar instaladores pendentes"})
+		return
+	}
+
+	responses := make([]UserResponse, 0, len(users))
+	for _, user := range users {
+		responses = append(responses, newUserResponse(user))
+	}
+
+	c.JSON(http.StatusOK, responses)
+}
+
+// GetUserStatusHistory lista as mudanças de status do usuário, da mais recente
+// para a mais antiga.
+func GetUserStatusHistory(c *gin.Context) {
+	var history []models.UserStatusHistory
+	if err := database.DB.Where("user_id = ?", c.Param("id")).
+		Order("created_at DESC").
+		Find(&history).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao listar histórico de status"})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
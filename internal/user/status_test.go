@@ -0,0 +1,122 @@
+package user
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"user-service/internal/database"
+	"user-service/internal/user/models"
+)
+
+const statusReasonBody = `{"reason":"documentação inconsistente"}`
+
+func TestStatusRoutesAdminOnly(t *testing.T) {
+	userWithStatus := func(status, action, body string) func(t *testing.T) (string, string) {
+		return func(t *testing.T) (string, string) {
+			return "/user/" + createTestUser(t, models.RoleInstalador, status).ID + action, body
+		}
+	}
+	testAdminOnly(t, map[string]adminRoute{
+		"rejeitar":  {http.MethodPost, "/user/x/reject", userWithStatus(models.StatusPending, "/reject", statusReasonBody), http.StatusOK},
+		"suspender": {http.MethodPost, "/user/x/suspend", userWithStatus(models.StatusApproved, "/suspend", statusReasonBody), http.StatusOK},
+		"reativar":  {http.MethodPost, "/user/x/reinstate", userWithStatus(models.StatusSuspended, "/reinstate", ""), http.StatusOK},
+		"histórico": {http.MethodGet, "/user/x/status-history", userWithStatus(models.StatusPending, "/status-history", ""), http.StatusOK},
+	})
+}
+
+func TestStatusTransitions(t *testing.T) {
+	r := newTestRouter()
+	token := bearerToken(t, createTestUser(t, models.RoleAdmin, models.StatusApproved))
+
+	tests := []struct {
+		from   string
+		action string
+		body   string
+		want   int
+		status string
+	}{
+		{models.StatusPending, "/authorize", "", http.StatusOK, models.StatusApproved},
+		{models.StatusPending, "/reject", statusReasonBody, http.StatusOK, models.StatusRejected},
+		{models.StatusPending, "/suspend", statusReasonBody, http.StatusConflict, models.StatusPending},
+		{models.StatusRejected, "/authorize", "", http.StatusOK, models.StatusApproved},
+		{models.StatusApproved, "/suspend", statusReasonBody, http.StatusOK, models.StatusSuspended},
+		{models.StatusApproved, "/reject", statusReasonBody, http.StatusConflict, models.StatusApproved},
+		{models.StatusSuspended, "/reinstate", "", http.StatusOK, models.StatusReinstated},
+		{models.StatusSuspended, "/reject", statusReasonBody, http.StatusOK, models.StatusRejected},
+		{models.StatusSuspended, "/authorize", "", http.StatusConflict, models.StatusSuspended},
+		{models.StatusReinstated, "/suspend", statusReasonBody, http.StatusOK, models.StatusSuspended},
+	}
+	for _, tt := range tests {
+		t.Run(tt.from+tt.action, func(t *testing.T) {
+			user := createTestUser(t, models.RoleInstalador, tt.from)
+			approveRequiredDocuments(t, user.ID)
+			// A aprovação manteve a rota PATCH anterior ao fluxo de status
+			method := http.MethodPost
+			if tt.action == "/authorize" {
+				method = http.MethodPatch
+			}
+			if w := doRequest(r, method, "/user/"+user.ID+tt.action, token, tt.body); w.Code != tt.want {
+				t.Fatalf("status HTTP = %d, esperado %d: %s", w.Code, tt.want, w.Body)
+			}
+
+			if err := database.DB.First(&user, "id = ?", user.ID).Error; err != nil {
+				t.Fatal(err)
+			}
+			if user.Status != tt.status || user.Authorized != models.IsActiveStatus(tt.status) {
+				t.Errorf("status = %q, authorized = %v; esperado %q", user.Status, user.Authorized, tt.status)
+			}
+		})
+	}
+}
+
+// Rejeitar e suspender exigem motivo.
+func TestStatusChangeRequiresReason(t *testing.T) {
+	r := newTestRouter()
+	token := bearerToken(t, createTestUser(t, models.RoleAdmin, models.StatusApproved))
+
+	for _, action := range []string{"/reject", "/suspend"} {
+		user := createTestUser(t, models.RoleInstalador, models.StatusApproved)
+		if w := doRequest(r, http.MethodPost, "/user/"+user.ID+action, token, `{"reason":"  "}`); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, esperado %d", action, w.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+// A suspensão encerra as sessões, barra novos logins e fica no histórico com o
+// administrador e o motivo.
+func TestSuspendUser(t *testing.T) {
+	r := newTestRouter()
+	installer := createTestUser(t, models.RoleInstalador, models.StatusApproved)
+	tokens := loginTestUser(t, r, installer)
+	admin := createTestUser(t, models.RoleAdmin, models.StatusApproved)
+	adminToken := bearerToken(t, admin)
+
+	if w := doRequest(r, http.MethodPost, "/user/"+installer.ID+"/suspend", adminToken, statusReasonBody); w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body)
+	}
+
+	if w := doRequest(r, http.MethodGet, "/user/me", tokens.Token, ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("token de acesso após suspensão: status = %d, esperado %d", w.Code, http.StatusUnauthorized)
+	}
+	if w := doRequest(r, http.MethodPost, "/user/token/refresh", "", refreshBody(tokens.RefreshToken)); w.Code != http.StatusUnauthorized {
+		t.Errorf("refresh token após suspensão: status = %d, esperado %d", w.Code, http.StatusUnauthorized)
+	}
+
+	w := doRequest(r, http.MethodPost, "/user/login", "", `{"email":"`+installer.Email+`","password":"`+testPassword+`"}`)
+	var denied struct {
+		Status string `json:"status"`
+	}
+	if w.Code != http.StatusForbidden || json.Unmarshal(w.Body.Bytes(), &denied) != nil || denied.Status != models.StatusSuspended {
+		t.Errorf("login suspenso: status = %d: %s", w.Code, w.Body)
+	}
+
+	w = doRequest(r, http.MethodGet, "/user/"+installer.ID+"/status-history", adminToken, "")
+	var history []models.UserStatusHistory
+	if err := json.Unmarshal(w.Body.Bytes(), &history); err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 1 || history[0].FromStatus != models.StatusApproved || history[0].ToStatus != models.StatusSuspended ||
+		history[0].ChangedBy != admin.ID || history[0].Reason != "documentação inconsistente" {
+		t.Errorf("histórico = %+v", history)
+	}
+}
@@ -0,0 +1,248 @@
+package user
+
+import (
+	"errors"
+	"net/http"
+	"time"
+	"user-service/internal/database"
+	"user-service/internal/revocation"
+	"user-service/internal/user/models"
+	"user-service/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	errInvalidRefreshToken = errors.New("refresh token inválido ou expirado")
+	errRefreshTokenReused  = errors.New("refresh token reutilizado")
+	errUserNotAuthorized   = errors.New("usuário não autorizado")
+)
+
+// tokenPair é o conjunto de credenciais entregue ao cliente após login ou rotação.
+type tokenPair struct {
+	AccessToken    string
+	RefreshToken   string
+	ExpiresIn      int
+	refreshTokenID string
+}
+
+// createRefreshToken persiste um novo refresh token (apenas o hash) na família
+// informada e devolve o registro junto com o valor em texto puro.
+func createRefreshToken(tx *gorm.DB, c *gin.Context, userID, familyID, deviceName string) (models.RefreshToken, string, error) {
+	raw, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return models.RefreshToken{}, "", err
+	}
+
+	token := models.RefreshToken{
+		UserID:     userID,
+		FamilyID:   familyID,
+		TokenHash:  utils.HashToken(raw),
+		DeviceName: deviceName,
+		UserAgent:  c.Request.UserAgent(),
+		IP:         c.ClientIP(),
+		ExpiresAt:  time.Now().Add(utils.RefreshTokenTTL()),
+	}
+	if err := tx.Create(&token).Error; err != nil {
+		return models.RefreshToken{}, "", err
+	}
+
+	return token, raw, nil
+}
+
+// issueTokenPair gera um token de acesso e um refresh token. Um familyID vazio
+// inicia uma nova família, registrando uma nova sessão (novo login); caso
+// contrário, a sessão existente é atualizada (rotação).
+func issueTokenPair(tx *gorm.DB, c *gin.Context, user models.User, familyID, deviceName string) (tokenPair, error) {
+	now := time.Now()
+
+	if familyID == "" {
+		session := models.Session{
+			ID:         uuid.New().String(),
+			UserID:     user.ID,
+			DeviceName: deviceName,
+			UserAgent:  c.Request.UserAgent(),
+			IP:         c.ClientIP(),
+			LastSeenAt: now,
+			ExpiresAt:  now.Add(utils.RefreshTokenTTL()),
+		}
+		if err := tx.Create(&session).Error; err != nil {
+			return tokenPair{}, err
+		}
+		familyID = session.ID
+	} else if err := tx.Model(&models.Session{}).Where("id = ?", familyID).Updates(map[string]interface{}{
+		"device_name":  deviceName,
+		"user_agent":   c.Request.UserAgent(),
+		"ip":           c.ClientIP(),
+		"last_seen_at": now,
+		"expires_at":   now.Add(utils.RefreshTokenTTL()),
+	}).Error; err != nil {
+		return tokenPair{}, err
+	}
+
+	record, refresh, err := createRefreshToken(tx, c, user.ID, familyID, deviceName)
+	if err != nil {
+		return tokenPair{}, err
+	}
+
+	access, err := utils.GenerateJWT(user.ID, user.Role, familyID)
+	if err != nil {
+		return tokenPair{}, err
+	}
+
+	return tokenPair{
+		AccessToken:    access,
+		RefreshToken:   refresh,
+		ExpiresIn:      int(utils.AccessTokenTTL().Seconds()),
+		refreshTokenID: record.ID,
+	}, nil
+}
+
+// respondLogin emite um novo par de tokens (nova família) e responde com os
+// dados de login do usuário. Campos em extra são acrescentados à resposta.
+func respondLogin(c *gin.Context, user models.User, deviceName string, extra gin.H) {
+	tokens, err := issueTokenPair(database.DB, c, user, "", deviceName)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	response := gin.H{
+		"ID":            user.ID,
+		"name":          user.Name,
+		"person":        user.Role,
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
+		"photo":         user.Photo,
+	}
+	for key, value := range extra {
+		response[key] = value
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RefreshAccessToken troca um refresh token válido por um novo par de tokens.
+// O token apresentado é revogado (rotação); se um token já rotacionado for
+// reapresentado, toda a família é revogada. Tokens revogados sem substituto
+// são recusados sem afetar o restante da família.
+func RefreshAccessToken(c *gin.Context) {
+	var body struct {
+		RefreshToken string `json:"refresh_token"`
+		DeviceName   string `json:"device_name"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil || body.RefreshToken == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "refresh_token é obrigatório"})
+		return
+	}
+
+	var (
+		pair     tokenPair
+		familyID string
+	)
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var current models.RefreshToken
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ?", utils.HashToken(body.RefreshToken)).
+			First(&current).Error; err != nil {
+			return errInvalidRefreshToken
+		}
+		familyID = current.FamilyID
+
+		if current.RevokedAt != nil {
+			// Só um token já rotacionado indica reutilização; os revogados por
+			// logout ou pelo administrador são apenas inválidos
+			if current.ReplacedByID != "" {
+				return errRefreshTokenReused
+			}
+			return errInvalidRefreshToken
+		}
+		if time.Now().After(current.ExpiresAt) {
+			return errInvalidRefreshToken
+		}
+
+		var user models.User
+		if err := tx.First(&user, "id = ?", current.UserID).Error; err != nil {
+			return errInvalidRefreshToken
+		}
+		if !user.Authorized {
+			return errUserNotAuthorized
+		}
+
+		deviceName := body.DeviceName
+		if deviceName == "" {
+			deviceName = current.DeviceName
+		}
+
+		next, err := issueTokenPair(tx, c, user, current.FamilyID, deviceName)
+		if err != nil {
+			return err
+		}
+
+		pair = next
+		return tx.Model(&current).Updates(map[string]interface{}{
+			"revoked_at":     time.Now(),
+			"replaced_by_id": next.refreshTokenID,
+		}).Error
+	})
+
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, gin.H{
+			"token":         pair.AccessToken,
+			"refresh_token": pair.RefreshToken,
+			"expires_in":    pair.ExpiresIn,
+		})
+	case errors.Is(err, errRefreshTokenReused):
+		// Possível roubo de token: encerra a sessão inteira desse login
+		if err := revocation.RevokeSession(familyID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao revogar refresh tokens"})
+			return
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token reutilizado; sessão encerrada"})
+	case errors.Is(err, errInvalidRefreshToken):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token inválido ou expirado"})
+	case errors.Is(err, errUserNotAuthorized):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Usuário ainda não autorizado"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao renovar token"})
+	}
+}
+
+// LogoutUser revoga o token de acesso atual e encerra sua sessão, incluindo
+// os refresh tokens do mesmo login. Para tokens sem sessão, o refresh token
+// pode ser informado no corpo.
+func LogoutUser(c *gin.Context) {
+	var body struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	// O corpo é opcional
+	_ = c.ShouldBindJSON(&body)
+
+	userID := c.GetString("user_id")
+
+	if err := revocation.RevokeToken(c.GetString("jti"), userID, c.GetTime("token_expires_at"), "logout"); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao revogar token"})
+		return
+	}
+
+	sessionID := c.GetString("session_id")
+	if sessionID == "" && body.RefreshToken != "" {
+		var token models.RefreshToken
+		if err := database.DB.Where("token_hash = ? AND user_id = ?", utils.HashToken(body.RefreshToken), userID).
+			First(&token).Error; err == nil {
+			sessionID = token.FamilyID
+		}
+	}
+
+	if err := revocation.RevokeSession(sessionID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao encerrar sessão"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logout realizado com sucesso"})
+}
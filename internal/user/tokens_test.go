@@ -0,0 +1,105 @@
+package user
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+	"user-service/internal/database"
+	"user-service/internal/user/models"
+	"user-service/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type loginTokens struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+func decodeTokens(t *testing.T, body []byte) loginTokens {
+	t.Helper()
+	var tokens loginTokens
+	if err := json.Unmarshal(body, &tokens); err != nil || tokens.Token == "" || tokens.RefreshToken == "" {
+		t.Fatalf("resposta sem tokens: %s", body)
+	}
+	return tokens
+}
+
+func refreshBody(refreshToken string) string {
+	return `{"refresh_token":"` + refreshToken + `"}`
+}
+
+func loginTestUser(t *testing.T, r *gin.Engine, user models.User) loginTokens {
+	t.Helper()
+	w := doRequest(r, http.MethodPost, "/user/login", "", `{"email":"`+user.Email+`","password":"`+testPassword+`"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("login: status = %d: %s", w.Code, w.Body)
+	}
+	return decodeTokens(t, w.Body.Bytes())
+}
+
+// Reapresentar um refresh token já trocado revoga toda a família: os tokens
+// emitidos depois dele deixam de valer.
+func TestRefreshTokenReuseRevokesFamily(t *testing.T) {
+	r := newTestRouter()
+	first := loginTestUser(t, r, createTestUser(t, models.RoleCliente, models.StatusApproved))
+
+	w := doRequest(r, http.MethodPost, "/user/token/refresh", "", refreshBody(first.RefreshToken))
+	if w.Code != http.StatusOK {
+		t.Fatalf("renovação: status = %d: %s", w.Code, w.Body)
+	}
+	second := decodeTokens(t, w.Body.Bytes())
+	if second.RefreshToken == first.RefreshToken {
+		t.Fatal("refresh token não foi trocado")
+	}
+
+	if w := doRequest(r, http.MethodPost, "/user/token/refresh", "", refreshBody(first.RefreshToken)); w.Code != http.StatusUnauthorized {
+		t.Fatalf("reuso: status = %d, esperado %d: %s", w.Code, http.StatusUnauthorized, w.Body)
+	}
+	if w := doRequest(r, http.MethodPost, "/user/token/refresh", "", refreshBody(second.RefreshToken)); w.Code != http.StatusUnauthorized {
+		t.Errorf("refresh token da família revogada: status = %d, esperado %d: %s", w.Code, http.StatusUnauthorized, w.Body)
+	}
+}
+
+// Um token revogado sem ter sido rotacionado (logout, revogação pelo
+// administrador) é apenas inválido e não derruba o restante da família.
+func TestRevokedRefreshTokenIsNotReuse(t *testing.T) {
+	r := newTestRouter()
+	first := loginTestUser(t, r, createTestUser(t, models.RoleCliente, models.StatusApproved))
+
+	var revoked models.RefreshToken
+	if err := database.DB.First(&revoked, "token_hash = ?", utils.HashToken(first.RefreshToken)).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := database.DB.Model(&revoked).Update("revoked_at", time.Now()).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	sibling, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := database.DB.Create(&models.RefreshToken{
+		UserID:    revoked.UserID,
+		FamilyID:  revoked.FamilyID,
+		TokenHash: utils.HashToken(sibling),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if w := doRequest(r, http.MethodPost, "/user/token/refresh", "", refreshBody(first.RefreshToken)); w.Code != http.StatusUnauthorized {
+		t.Fatalf("token revogado: status = %d, esperado %d: %s", w.Code, http.StatusUnauthorized, w.Body)
+	}
+	if w := doRequest(r, http.MethodPost, "/user/token/refresh", "", refreshBody(sibling)); w.Code != http.StatusOK {
+		t.Errorf("token ativo da mesma família: status = %d, esperado %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+}
+
+func TestRefreshTokenRejectsUnknownToken(t *testing.T) {
+	r := newTestRouter()
+	if w := doRequest(r, http.MethodPost, "/user/token/refresh", "", refreshBody("desconhecido")); w.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, esperado %d: %s", w.Code, http.StatusUnauthorized, w.Body)
+	}
+}
@@ -0,0 +1,102 @@
+package user
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"user-service/internal/database"
+	"user-service/internal/email"
+	"user-service/internal/user/models"
+	"user-service/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// emailVerificationRequired indica se o login deve recusar contas com e-mail
+// não verificado (REQUIRE_EMAIL_VERIFICATION, padrão false).
+func emailVerificationRequired() bool {
+	return utils.GetEnvBool("REQUIRE_EMAIL_VERIFICATION", false)
+}
+
+// sendEmailVerification gera o token de confirmação e envia o e-mail.
+func sendEmailVerification(user models.User) {
+	ttl := utils.GetEnvDuration("EMAIL_VERIFICATION_TTL", 48*time.Hour)
+	token, err := createUserToken(user.ID, models.TokenPurposeEmailVerification, ttl)
+	if err != nil {
+		fmt.Println("⚠️ Erro ao gerar token de verificação de e-mail:", err)
+		return
+	}
+
+	err = email.SendEmailVerification(user.Email, email.EmailVerificationData{
+		Name:       user.Name,
+		Link:       frontendLink("EMAIL_VERIFICATION_URL", token),
+		Token:      token,
+		ValidHours: int(ttl.Hours()),
+	})
+	if err != nil {
+		fmt.Println("⚠️ Erro ao enviar e-mail de verificação:", err)
+	}
+}
+
+// VerifyEmail confirma o e-mail do usuário a partir do token recebido.
+func VerifyEmail(c *gin.Context) {
+	raw := c.Query("token")
+	if raw == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "token é obrigatório"})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		token, err := consumeUserToken(tx, raw, models.TokenPurposeEmailVerification)
+		if err != nil {
+			return err
+		}
+		return tx.Model(&models.User{}).
+			Where("id = ? AND email_verified_at IS NULL", token.UserID).
+			Update("email_verified_at", time.Now()).Error
+	})
+	if errors.Is(err, errInvalidUserToken) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Token inválido ou expirado"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao verificar e-mail"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "E-mail verificado com sucesso"})
+}
+
+// ResendEmailVerification reenvia o e-mail de confirmação. Assim como em
+// ForgotPassword, a resposta não revela se o e-mail existe ou já foi verificado.
+func ResendEmailVerification(c *gin.Context) {
+	var body struct {
+		Email string `json:"email"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil || body.Email == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "email é obrigatório"})
+		return
+	}
+
+	address := strings.TrimSpace(body.Email)
+	if !allowAccountEmail(c, address) {
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "Muitas solicitações; tente novamente mais tarde"})
+		return
+	}
+
+	go func() {
+		var user models.User
+		if err := database.DB.Where("email = ? AND email_verified_at IS NULL", address).
+			First(&user).Error; err != nil {
+			return
+		}
+		sendEmailVerification(user)
+	}()
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Se o e-mail estiver cadastrado e pendente de verificação, um novo link será enviado",
+	})
+}
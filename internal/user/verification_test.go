@@ -0,0 +1,80 @@
+package user
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"user-service/internal/database"
+	"user-service/internal/user/models"
+
+	"github.com/google/uuid"
+)
+
+// registerTestClient cadastra um cliente pela rota pública e retorna o e-mail.
+func registerTestClient(t *testing.T) string {
+	t.Helper()
+	address := uuid.NewString() + "@example.com"
+	body := `{"name":"Nova","email":"` + address + `","password":"` + testPassword + `","role":"cliente"}`
+	if w := doRequest(newTestRouter(), http.MethodPost, "/user/register", "", body); w.Code != http.StatusCreated && w.Code != http.StatusOK {
+		t.Fatalf("cadastro: status = %d: %s", w.Code, w.Body)
+	}
+	return address
+}
+
+func TestRegisterSendsEmailVerification(t *testing.T) {
+	messages := captureEmails(t)
+	r := newTestRouter()
+	address := registerTestClient(t)
+
+	match := emailTokenPattern.FindStringSubmatch(waitEmail(t, messages, address).HTML)
+	if match == nil {
+		t.Fatal("e-mail sem token")
+	}
+
+	path := "/user/verify-email?token=" + url.QueryEscape(match[1])
+	if w := doRequest(r, http.MethodGet, path, "", ""); w.Code != http.StatusOK {
+		t.Fatalf("verificação: status = %d: %s", w.Code, w.Body)
+	}
+	if w := doRequest(r, http.MethodGet, path, "", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("token reutilizado: status = %d, esperado %d", w.Code, http.StatusBadRequest)
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, "email = ?", address).Error; err != nil {
+		t.Fatal(err)
+	}
+	if user.EmailVerifiedAt == nil {
+		t.Error("e-mail continua não verificado")
+	}
+}
+
+func TestLoginRequiresVerifiedEmail(t *testing.T) {
+	t.Setenv("REQUIRE_EMAIL_VERIFICATION", "true")
+	r := newTestRouter()
+	address := registerTestClient(t)
+
+	login := `{"email":"` + address + `","password":"` + testPassword + `"}`
+	if w := doRequest(r, http.MethodPost, "/user/login", "", login); w.Code != http.StatusForbidden {
+		t.Errorf("não verificado: status = %d, esperado %d: %s", w.Code, http.StatusForbidden, w.Body)
+	}
+
+	verified := createTestUser(t, models.RoleCliente, models.StatusApproved)
+	loginTestUser(t, r, verified)
+}
+
+func TestResendEmailVerificationThrottled(t *testing.T) {
+	resetAccountEmailLimits(t, 1, 10)
+	r := newTestRouter()
+	body := `{"email":"reenvio@example.com"}`
+
+	if w := doRequest(r, http.MethodPost, "/user/verify-email/resend", "", body); w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body)
+	}
+	if w := doRequest(r, http.MethodPost, "/user/verify-email/resend", "", body); w.Code != http.StatusTooManyRequests {
+		t.Errorf("status = %d, esperado %d", w.Code, http.StatusTooManyRequests)
+	}
+	// O limite é compartilhado com a redefinição de senha
+	if w := doRequest(r, http.MethodPost, "/user/password/forgot", "", body); w.Code != http.StatusTooManyRequests {
+		t.Errorf("forgot: status = %d, esperado %d", w.Code, http.StatusTooManyRequests)
+	}
+}
@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GetEnvDuration lê uma duração (ex.: "15m", "720h") da variável de ambiente,
+// retornando o valor padrão se ausente ou inválida.
+func GetEnvDuration(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return fallback
+	}
+	return d
+}
+
+// GetEnvInt lê um inteiro da variável de ambiente, retornando o valor padrão
+// se ausente ou inválido.
+func GetEnvInt(key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return fallback
+	}
+	return n
+}
+
+// GetEnvFloat lê um número decimal da variável de ambiente, retornando o
+// valor padrão se ausente ou inválido.
+func GetEnvFloat(key string, fallback float64) float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return fallback
+	}
+	return f
+}
+
+// GetEnvBool lê um booleano ("true", "1", "false"...) da variável de ambiente,
+// retornando o valor padrão se ausente ou inválido.
+func GetEnvBool(key string, fallback bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return fallback
+	}
+	return b
+}
+
+// GetEnvList lê uma lista separada por vírgulas da variável de ambiente,
+// ignorando itens vazios. Retorna o valor padrão se a variável estiver ausente.
+func GetEnvList(key string, fallback []string) []string {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
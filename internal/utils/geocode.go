@@ -8,8 +8,16 @@ import (
 	"net/url"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
+// geocodingClient faz as consultas ao LocationIQ com limite de tempo
+// (GEOCODING_TIMEOUT, padrão 5s), para não prender a requisição do usuário.
+func geocodingClient() *http.Client {
+	return &http.Client{Timeout: GetEnvDuration("GEOCODING_TIMEOUT", 5*time.Second)}
+}
+
 func BuscarCoordenadas(enderecoCompleto string) (float64, float64, error) {
 	apiKey := os.Getenv("LOCATIONIQ_API_KEY")
 	if apiKey == "" {
@@ -24,7 +32,7 @@ func BuscarCoordenadas(enderecoCompleto string) (float64, float64, error) {
 		apiKey,
 	)
 
-	resp, err := http.Get(query)
+	resp, err := geocodingClient().Get(query)
 	if err != nil {
 		return 0, 0, err
 	}
@@ -58,3 +66,51 @@ func BuscarCoordenadas(enderecoCompleto string) (float64, float64, error) {
 	// Caso não consiga interpretar a resposta de nenhuma forma
 	return 0, 0, fmt.Errorf("Resposta inesperada do serviço de geolocalização")
 }
+
+// BuscarCidade faz a geocodificação reversa do ponto, retornando o nome da
+// cidade e a sigla da UF.
+func BuscarCidade(lat, lng float64) (string, string, error) {
+	apiKey := os.Getenv("LOCATIONIQ_API_KEY")
+	if apiKey == "" {
+		return "", "", fmt.Errorf("API key do LocationIQ não encontrada")
+	}
+
+	query := fmt.Sprintf("https://us1.locationiq.com/v1/reverse.php?lat=%s&lon=%s&key=%s&format=json&accept-language=pt",
+		strconv.FormatFloat(lat, 'f', 6, 64),
+		strconv.FormatFloat(lng, 'f', 6, 64),
+		apiKey,
+	)
+
+	resp, err := geocodingClient().Get(query)
+	if err != nil {
+		return "", "", err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Error   string `json:"error"`
+		Address struct {
+			City         string `json:"city"`
+			Town         string `json:"town"`
+			Village      string `json:"village"`
+			Municipality string `json:"municipality"`
+			StateCode    string `json:"ISO3166-2-lvl4"`
+		} `json:"address"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", "", fmt.Errorf("Resposta inesperada do serviço de geolocalização")
+	}
+	if result.Error != "" {
+		return "", "", fmt.Errorf("Erro do LocationIQ: %s", result.Error)
+	}
+
+	city := result.Address.City
+	for _, alt := range []string{result.Address.Town, result.Address.Village, result.Address.Municipality} {
+		if city == "" {
+			city = alt
+		}
+	}
+	// ISO 3166-2: "BR-SP"
+	uf := strings.TrimPrefix(result.Address.StateCode, "BR-")
+	return city, uf, nil
+}
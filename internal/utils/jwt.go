@@ -1,34 +1,158 @@
 package utils
 
 import (
+	"errors"
 	"os"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
-// Chave secreta para assinar o token
-var SecretKey = []byte(os.Getenv("JWT_SECRET"))
+// Finalidades de tokens que não dão acesso às rotas protegidas
+const (
+	TokenPurposeMFA = "mfa"
+	// TokenPurposeOnboarding dá a instaladores ainda não aprovados acesso
+	// apenas ao envio dos documentos exigidos na aprovação
+	TokenPurposeOnboarding = "onboarding"
+)
+
+// ActorClaim identifica quem realmente age em um token de personificação
+// (claim "act", RFC 8693).
+type ActorClaim struct {
+	Subject string `json:"sub"`
+}
 
 // Claims personalizados para o JWT
 type Claims struct {
 	UserID string `json:"user_id"`
 	Role   string `json:"role"`
+	// SessionID (sid) vincula o token de acesso à sessão que o originou
+	SessionID string `json:"sid,omitempty"`
+	// Purpose vazio indica um token de acesso comum
+	Purpose string `json:"purpose,omitempty"`
+	// Actor presente indica que um administrador está personificando UserID
+	Actor *ActorClaim `json:"act,omitempty"`
 	jwt.RegisteredClaims
 }
 
-// Gera um token JWT para o usuário
-func GenerateJWT(userID string, role string) (string, error) {
-	expirationTime := time.Now().Add(time.Hour)
+// AccessTokenTTL retorna a validade do token de acesso (JWT_ACCESS_TTL, padrão 1h).
+func AccessTokenTTL() time.Duration {
+	return GetEnvDuration("JWT_ACCESS_TTL", time.Hour)
+}
+
+// RefreshTokenTTL retorna a validade do refresh token (JWT_REFRESH_TTL, padrão 30 dias).
+func RefreshTokenTTL() time.Duration {
+	return GetEnvDuration("JWT_REFRESH_TTL", 30*24*time.Hour)
+}
+
+// TokenIssuer é o emissor (iss) dos tokens deste serviço (JWT_ISSUER).
+func TokenIssuer() string {
+	if issuer := os.Getenv("JWT_ISSUER"); issuer != "" {
+		return issuer
+	}
+	return "eletrihub-user-service"
+}
 
-	claims := &Claims{
-		UserID: userID,
-		Role:   role,
+// TokenAudience lista os destinatários (aud) incluídos nos tokens
+// (JWT_AUDIENCE, separado por vírgulas). O primeiro item identifica este
+// serviço e é exigido na validação; os demais permitem que outros serviços
+// aceitem o mesmo token.
+func TokenAudience() []string {
+	audience := GetEnvList("JWT_AUDIENCE", nil)
+	if len(audience) == 0 {
+		return []string{"eletrihub"}
+	}
+	return audience
+}
+
+func signClaims(userID, role, sessionID, purpose string, ttl time.Duration) (string, error) {
+	return signToken(newClaims(userID, role, sessionID, purpose, ttl))
+}
+
+func newClaims(userID, role, sessionID, purpose string, ttl time.Duration) *Claims {
+	now := time.Now()
+
+	return &Claims{
+		UserID:    userID,
+		Role:      role,
+		SessionID: sessionID,
+		Purpose:   purpose,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expirationTime),
+			ID:        uuid.New().String(),
+			Issuer:    TokenIssuer(),
+			Subject:   userID,
+			Audience:  jwt.ClaimStrings(TokenAudience()),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
 		},
 	}
+}
+
+// Gera um token JWT para o usuário, vinculado à sessão informada
+func GenerateJWT(userID string, role string, sessionID string) (string, error) {
+	return signClaims(userID, role, sessionID, "", AccessTokenTTL())
+}
+
+// GenerateMFAChallenge gera o token de curta duração (MFA_CHALLENGE_TTL, padrão
+// 5 min) entregue após a senha correta, a ser trocado pelo token de acesso
+// junto com o código TOTP.
+func GenerateMFAChallenge(userID string, role string) (string, error) {
+	return signClaims(userID, role, "", TokenPurposeMFA, GetEnvDuration("MFA_CHALLENGE_TTL", 5*time.Minute))
+}
+
+// OnboardingTokenTTL retorna a validade do token de cadastro entregue a
+// instaladores pendentes ou recusados (ONBOARDING_TOKEN_TTL, padrão 1h).
+func OnboardingTokenTTL() time.Duration {
+	return GetEnvDuration("ONBOARDING_TOKEN_TTL", time.Hour)
+}
+
+// GenerateOnboardingToken gera o token de cadastro, sem sessão nem refresh
+// token, aceito apenas nas rotas de documentos.
+func GenerateOnboardingToken(userID string, role string) (string, error) {
+	return signClaims(userID, role, "", TokenPurposeOnboarding, OnboardingTokenTTL())
+}
+
+// ImpersonationTokenTTL retorna a validade dos tokens de personificação
+// (IMPERSONATION_TTL, padrão 15 min).
+func ImpersonationTokenTTL() time.Duration {
+	return GetEnvDuration("IMPERSONATION_TTL", 15*time.Minute)
+}
+
+// GenerateImpersonationToken gera um token de acesso em nome do usuário alvo,
+// carregando o administrador real no claim "act". Não há sessão nem refresh
+// token associados.
+func GenerateImpersonationToken(userID, role, actorID string) (string, error) {
+	claims := newClaims(userID, role, "", "", ImpersonationTokenTTL())
+	claims.Actor = &ActorClaim{Subject: actorID}
+	return signToken(claims)
+}
+
+// ParseToken valida o token e retorna seus claims. Além da assinatura, exige
+// algoritmo de uma das chaves carregadas, emissor e destinatário deste serviço,
+// exp/iat/nbf coerentes (com tolerância de JWT_CLOCK_SKEW, padrão 30s) e sub
+// igual ao user_id.
+func ParseToken(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+
+	token, err := jwt.ParseWithClaims(tokenString, claims, keyFunc,
+		jwt.WithValidMethods(validMethods()),
+		jwt.WithIssuer(TokenIssuer()),
+		jwt.WithAudience(TokenAudience()[0]),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(GetEnvDuration("JWT_CLOCK_SKEW", 30*time.Second)),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, errors.New("token inválido")
+	}
+	if claims.Subject != claims.UserID || claims.ID == "" || claims.IssuedAt == nil {
+		return nil, errors.New("claims obrigatórios ausentes ou inconsistentes")
+	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(SecretKey)
+	return claims, nil
 }
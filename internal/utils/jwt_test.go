@@ -0,0 +1,162 @@
+package utils
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const testSecret = "segredo-de-teste-com-pelo-menos-32-bytes"
+
+func initHS256Keys(t *testing.T) {
+	t.Helper()
+	t.Setenv("JWT_KEYS_DIR", "")
+	t.Setenv("JWT_SECRET", testSecret)
+	if err := InitKeys(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// testClaims monta claims válidos de um token de acesso, como signClaims.
+func testClaims(userID, role string) *Claims {
+	now := time.Now()
+	return &Claims{
+		UserID: userID,
+		Role:   role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
+			Issuer:    TokenIssuer(),
+			Subject:   userID,
+			Audience:  jwt.ClaimStrings(TokenAudience()),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+		},
+	}
+}
+
+func signTestToken(t *testing.T, method jwt.SigningMethod, key interface{}, kid string, claims jwt.Claims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestParseTokenAcceptsIssuedToken(t *testing.T) {
+	initHS256Keys(t)
+
+	token, err := GenerateJWT("u1", "cliente", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := ParseToken(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.UserID != "u1" || claims.Role != "cliente" || claims.Purpose != "" {
+		t.Errorf("claims inesperados: %+v", claims)
+	}
+	if claims.Issuer != TokenIssuer() || claims.Subject != "u1" || claims.ID == "" {
+		t.Errorf("claims registrados inesperados: %+v", claims.RegisteredClaims)
+	}
+}
+
+func TestParseTokenRejects(t *testing.T) {
+	initHS256Keys(t)
+
+	tests := []struct {
+		name   string
+		token  func() string
+		modify func(c *Claims)
+	}{
+		{name: "emissor diferente", modify: func(c *Claims) { c.Issuer = "outro-servico" }},
+		{name: "sem este serviço na audiência", modify: func(c *Claims) { c.Audience = jwt.ClaimStrings{"outro-servico"} }},
+		{name: "expirado", modify: func(c *Claims) { c.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Hour)) }},
+		{name: "sem exp", modify: func(c *Claims) { c.ExpiresAt = nil }},
+		{name: "emitido no futuro", modify: func(c *Claims) { c.IssuedAt = jwt.NewNumericDate(time.Now().Add(time.Hour)) }},
+		{name: "sub diferente do user_id", modify: func(c *Claims) { c.Subject = "u2" }},
+		{name: "sem jti", modify: func(c *Claims) { c.ID = "" }},
+		{name: "segredo diferente", token: func() string {
+			return signTestToken(t, jwt.SigningMethodHS256, []byte("outro-segredo"), "hs256", testClaims("u1", "cliente"))
+		}},
+		{name: "kid desconhecido", token: func() string {
+			return signTestToken(t, jwt.SigningMethodHS256, []byte(testSecret), "outra", testClaims("u1", "cliente"))
+		}},
+		{name: "algoritmo none", token: func() string {
+			return signTestToken(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, "hs256", testClaims("u1", "cliente"))
+		}},
+		{name: "algoritmo HS512", token: func() string {
+			return signTestToken(t, jwt.SigningMethodHS512, []byte(testSecret), "hs256", testClaims("u1", "cliente"))
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var token string
+			if tt.token != nil {
+				token = tt.token()
+			} else {
+				claims := testClaims("u1", "cliente")
+				tt.modify(claims)
+				token = signTestToken(t, jwt.SigningMethodHS256, []byte(testSecret), "hs256", claims)
+			}
+			if _, err := ParseToken(token); err == nil {
+				t.Error("token aceito, esperado erro")
+			}
+		})
+	}
+}
+
+// Com JWT_AUDIENCE, o primeiro item é exigido e os demais vão no token.
+func TestTokenAudienceFromEnv(t *testing.T) {
+	initHS256Keys(t)
+	t.Setenv("JWT_AUDIENCE", "usuarios, pedidos")
+
+	token, err := GenerateJWT("u1", "cliente", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := ParseToken(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(claims.Audience) != 2 || claims.Audience[0] != "usuarios" || claims.Audience[1] != "pedidos" {
+		t.Errorf("aud = %v", claims.Audience)
+	}
+}
+
+// Com chaves RSA, um token HS256 assinado com a chave pública (conhecida de
+// todos pelo JWKS) não pode ser aceito.
+func TestParseTokenRejectsAlgorithmConfusion(t *testing.T) {
+	dir := t.TempDir()
+	key := writeRSAKey(t, dir, "rsa-1")
+	initKeysDir(t, dir, "")
+
+	publicDER, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+
+	token, err := GenerateJWT("u1", "cliente", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseToken(token); err != nil {
+		t.Fatalf("token RS256 recusado: %v", err)
+	}
+
+	for _, secret := range [][]byte{publicPEM, publicDER} {
+		forged := signTestToken(t, jwt.SigningMethodHS256, secret, "rsa-1", testClaims("u1", "admin"))
+		if _, err := ParseToken(forged); err == nil {
+			t.Error("token HS256 assinado com a chave pública aceito")
+		}
+	}
+}
@@ -0,0 +1,307 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// signingKey é uma chave do chaveiro de JWT. Chaves sem parte privada (arquivos
+// *.pub.pem) servem apenas para validar tokens emitidos antes de uma rotação.
+type signingKey struct {
+	ID      string
+	Method  jwt.SigningMethod
+	Private interface{}
+	Public  interface{}
+}
+
+func (k *signingKey) verifyKey() interface{} {
+	if k.Method == jwt.SigningMethodHS256 {
+		return k.Private
+	}
+	return k.Public
+}
+
+type keyring struct {
+	active *signingKey
+	keys   map[string]*signingKey
+}
+
+var (
+	keysMu  sync.RWMutex
+	current *keyring
+)
+
+// InitKeys carrega as chaves de assinatura de JWT. Com JWT_KEYS_DIR definido,
+// lê as chaves PEM do diretório (RSA → RS256, Ed25519 → EdDSA), usando o nome
+// do arquivo sem extensão como kid; a chave ativa é JWT_ACTIVE_KID ou, na
+// falta dela, a modificada mais recentemente. Sem diretório, usa JWT_SECRET
+// com HS256. Retorna erro se nenhuma chave estiver configurada. Se
+// JWT_KEYS_RELOAD_INTERVAL for definido, o diretório é relido periodicamente.
+func InitKeys() error {
+	ring, err := loadKeyring()
+	if err != nil {
+		return err
+	}
+
+	keysMu.Lock()
+	current = ring
+	keysMu.Unlock()
+	log.Printf("🔑 Chave de assinatura JWT ativa: %s (%s)", ring.active.ID, ring.active.Method.Alg())
+
+	if interval := GetEnvDuration("JWT_KEYS_RELOAD_INTERVAL", 0); interval > 0 && os.Getenv("JWT_KEYS_DIR") != "" {
+		go reloadKeys(interval)
+	}
+	return nil
+}
+
+func reloadKeys(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		ring, err := loadKeyring()
+		if err != nil {
+			log.Println("⚠️ Erro ao recarregar chaves JWT, mantendo as atuais:", err)
+			continue
+		}
+
+		keysMu.Lock()
+		changed := current.active.ID != ring.active.ID
+		current = ring
+		keysMu.Unlock()
+
+		if changed {
+			log.Printf("🔑 Nova chave de assinatura JWT ativa: %s (%s)", ring.active.ID, ring.active.Method.Alg())
+		}
+	}
+}
+
+func loadKeyring() (*keyring, error) {
+	dir := os.Getenv("JWT_KEYS_DIR")
+	if dir == "" {
+		secret := os.Getenv("JWT_SECRET")
+		if secret == "" {
+			return nil, errors.New("nenhuma chave JWT configurada (defina JWT_KEYS_DIR ou JWT_SECRET)")
+		}
+		key := &signingKey{ID: "hs256", Method: jwt.SigningMethodHS256, Private: []byte(secret)}
+		return &keyring{active: key, keys: map[string]*signingKey{key.ID: key}}, nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+
+	ring := &keyring{keys: map[string]*signingKey{}}
+	var signers []string
+	modTimes := map[string]time.Time{}
+
+	for _, file := range files {
+		key, err := loadKeyFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("chave %s: %v", filepath.Base(file), err)
+		}
+		if _, exists := ring.keys[key.ID]; exists {
+			return nil, fmt.Errorf("kid duplicado: %s", key.ID)
+		}
+		ring.keys[key.ID] = key
+
+		if key.Private != nil {
+			signers = append(signers, key.ID)
+			if info, err := os.Stat(file); err == nil {
+				modTimes[key.ID] = info.ModTime()
+			}
+		}
+	}
+
+	if activeID := os.Getenv("JWT_ACTIVE_KID"); activeID != "" {
+		key, ok := ring.keys[activeID]
+		if !ok || key.Private == nil {
+			return nil, fmt.Errorf("JWT_ACTIVE_KID %q não encontrada entre as chaves privadas de %s", activeID, dir)
+		}
+		ring.active = key
+	} else if len(signers) > 0 {
+		sort.Slice(signers, func(i, j int) bool {
+			return modTimes[signers[i]].After(modTimes[signers[j]])
+		})
+		ring.active = ring.keys[signers[0]]
+	}
+
+	if ring.active == nil {
+		return nil, fmt.Errorf("nenhuma chave privada encontrada em %s", dir)
+	}
+	return ring, nil
+}
+
+// loadKeyFile lê uma chave PEM: privada (PKCS#1, PKCS#8) ou pública (PKIX)
+// quando o arquivo termina em .pub.pem.
+func loadKeyFile(path string) (*signingKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("arquivo PEM inválido")
+	}
+
+	name := filepath.Base(path)
+	if strings.HasSuffix(name, ".pub.pem") {
+		pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		key := &signingKey{ID: strings.TrimSuffix(name, ".pub.pem"), Public: pub}
+		return key, key.setMethod(pub)
+	}
+
+	var priv interface{}
+	if block.Type == "RSA PRIVATE KEY" {
+		priv, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	} else {
+		priv, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	signer, ok := priv.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("tipo de chave privada não suportado")
+	}
+
+	key := &signingKey{ID: strings.TrimSuffix(name, ".pem"), Private: priv, Public: signer.Public()}
+	return key, key.setMethod(key.Public)
+}
+
+func (k *signingKey) setMethod(pub interface{}) error {
+	switch p := pub.(type) {
+	case *rsa.PublicKey:
+		if p.N.BitLen() < 2048 {
+			return errors.New("chaves RSA devem ter pelo menos 2048 bits")
+		}
+		k.Method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		k.Method = jwt.SigningMethodEdDSA
+	default:
+		return errors.New("tipo de chave não suportado (use RSA ou Ed25519)")
+	}
+	return nil
+}
+
+func activeKeyring() (*keyring, error) {
+	keysMu.RLock()
+	defer keysMu.RUnlock()
+
+	if current == nil {
+		return nil, errors.New("chaves JWT não inicializadas")
+	}
+	return current, nil
+}
+
+// signToken assina os claims com a chave ativa, informando o kid no cabeçalho.
+func signToken(claims jwt.Claims) (string, error) {
+	ring, err := activeKeyring()
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(ring.active.Method, claims)
+	token.Header["kid"] = ring.active.ID
+	return token.SignedString(ring.active.Private)
+}
+
+// keyFunc escolhe a chave de validação pelo kid do token e exige que o
+// algoritmo do token seja o da chave, impedindo a troca de algoritmo.
+func keyFunc(token *jwt.Token) (interface{}, error) {
+	ring, err := activeKeyring()
+	if err != nil {
+		return nil, err
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := ring.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("kid desconhecido: %q", kid)
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("algoritmo %s não corresponde à chave %s", token.Method.Alg(), kid)
+	}
+	return key.verifyKey(), nil
+}
+
+// validMethods lista os algoritmos das chaves carregadas.
+func validMethods() []string {
+	ring, err := activeKeyring()
+	if err != nil {
+		return nil
+	}
+
+	seen := map[string]bool{}
+	var methods []string
+	for _, key := range ring.keys {
+		if alg := key.Method.Alg(); !seen[alg] {
+			seen[alg] = true
+			methods = append(methods, alg)
+		}
+	}
+	return methods
+}
+
+// JWKS retorna as chaves públicas (RS256/EdDSA) no formato JSON Web Key Set,
+// para que outros serviços validem os tokens. Chaves HS256 nunca são expostas.
+func JWKS() map[string]interface{} {
+	keys := []map[string]interface{}{}
+
+	ring, err := activeKeyring()
+	if err == nil {
+		ids := make([]string, 0, len(ring.keys))
+		for id := range ring.keys {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+
+		for _, id := range ids {
+			key := ring.keys[id]
+			switch pub := key.Public.(type) {
+			case *rsa.PublicKey:
+				keys = append(keys, map[string]interface{}{
+					"kty": "RSA",
+					"use": "sig",
+					"alg": key.Method.Alg(),
+					"kid": key.ID,
+					"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+					"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+				})
+			case ed25519.PublicKey:
+				keys = append(keys, map[string]interface{}{
+					"kty": "OKP",
+					"crv": "Ed25519",
+					"use": "sig",
+					"alg": key.Method.Alg(),
+					"kid": key.ID,
+					"x":   base64.RawURLEncoding.EncodeToString(pub),
+				})
+			}
+		}
+	}
+
+	return map[string]interface{}{"keys": keys}
+}
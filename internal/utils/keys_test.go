@@ -0,0 +1,201 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// writeRSAKey grava uma chave RSA privada em dir/<kid>.pem e retorna a chave.
+func writeRSAKey(t *testing.T, dir, kid string) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePEM(t, filepath.Join(dir, kid+".pem"), "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(key))
+	return key
+}
+
+// writeEd25519Key grava uma chave Ed25519 privada (PKCS#8) em dir/<kid>.pem.
+func writeEd25519Key(t *testing.T, dir, kid string) ed25519.PrivateKey {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePEM(t, filepath.Join(dir, kid+".pem"), "PRIVATE KEY", der)
+	return key
+}
+
+func writePEM(t *testing.T, path, blockType string, der []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func initKeysDir(t *testing.T, dir, activeKID string) {
+	t.Helper()
+	t.Setenv("JWT_KEYS_DIR", dir)
+	t.Setenv("JWT_ACTIVE_KID", activeKID)
+	if err := InitKeys(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// tokenHeader retorna kid e alg do cabeçalho do token, sem validá-lo.
+func tokenHeader(t *testing.T, tokenString string) (string, string) {
+	t.Helper()
+	token, _, err := jwt.NewParser().ParseUnverified(tokenString, &Claims{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	kid, _ := token.Header["kid"].(string)
+	return kid, token.Method.Alg()
+}
+
+func jwksKIDs(t *testing.T) map[string]string {
+	t.Helper()
+	kids := map[string]string{}
+	for _, key := range JWKS()["keys"].([]map[string]interface{}) {
+		kids[key["kid"].(string)] = key["alg"].(string)
+	}
+	return kids
+}
+
+func TestAsymmetricSigning(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func(t *testing.T, dir string)
+		alg   string
+	}{
+		{"RS256", func(t *testing.T, dir string) { writeRSAKey(t, dir, "chave") }, "RS256"},
+		{"EdDSA", func(t *testing.T, dir string) { writeEd25519Key(t, dir, "chave") }, "EdDSA"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			tt.write(t, dir)
+			initKeysDir(t, dir, "")
+
+			token, err := GenerateJWT("u1", "cliente", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if kid, alg := tokenHeader(t, token); kid != "chave" || alg != tt.alg {
+				t.Errorf("cabeçalho kid=%q alg=%q, esperado kid=chave alg=%s", kid, alg, tt.alg)
+			}
+			claims, err := ParseToken(token)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if claims.UserID != "u1" {
+				t.Errorf("user_id = %q", claims.UserID)
+			}
+			if kids := jwksKIDs(t); kids["chave"] != tt.alg {
+				t.Errorf("JWKS = %v", kids)
+			}
+		})
+	}
+}
+
+// Após a rotação, tokens da chave anterior continuam válidos enquanto ela
+// estiver no diretório (inclusive só com a parte pública) e deixam de valer
+// quando ela é removida.
+func TestKeyRotation(t *testing.T) {
+	dir := t.TempDir()
+	oldKey := writeRSAKey(t, dir, "2024-01")
+	initKeysDir(t, dir, "")
+
+	oldToken, err := GenerateJWT("u1", "cliente", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writeEd25519Key(t, dir, "2024-02")
+	initKeysDir(t, dir, "2024-02")
+
+	newToken, err := GenerateJWT("u1", "cliente", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kid, _ := tokenHeader(t, newToken); kid != "2024-02" {
+		t.Errorf("kid do novo token = %q, esperado 2024-02", kid)
+	}
+	if kids := jwksKIDs(t); len(kids) != 2 || kids["2024-01"] != "RS256" || kids["2024-02"] != "EdDSA" {
+		t.Errorf("JWKS = %v", kids)
+	}
+	for name, token := range map[string]string{"anterior": oldToken, "novo": newToken} {
+		if _, err := ParseToken(token); err != nil {
+			t.Errorf("token %s recusado: %v", name, err)
+		}
+	}
+
+	// Mantém só a parte pública da chave anterior
+	publicDER, err := x509.MarshalPKIXPublicKey(&oldKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Remove(filepath.Join(dir, "2024-01.pem"))
+	writePEM(t, filepath.Join(dir, "2024-01.pub.pem"), "PUBLIC KEY", publicDER)
+	initKeysDir(t, dir, "")
+	if _, err := ParseToken(oldToken); err != nil {
+		t.Errorf("token anterior recusado com a chave pública: %v", err)
+	}
+
+	os.Remove(filepath.Join(dir, "2024-01.pub.pem"))
+	initKeysDir(t, dir, "")
+	if _, err := ParseToken(oldToken); err == nil {
+		t.Error("token de chave removida aceito")
+	}
+	if kids := jwksKIDs(t); len(kids) != 1 {
+		t.Errorf("JWKS após remoção = %v", kids)
+	}
+}
+
+func TestInitKeysErrors(t *testing.T) {
+	t.Run("sem configuração", func(t *testing.T) {
+		t.Setenv("JWT_KEYS_DIR", "")
+		t.Setenv("JWT_SECRET", "")
+		if err := InitKeys(); err == nil {
+			t.Error("InitKeys sem chaves não falhou")
+		}
+	})
+	t.Run("kid ativo inexistente", func(t *testing.T) {
+		dir := t.TempDir()
+		writeRSAKey(t, dir, "chave")
+		t.Setenv("JWT_KEYS_DIR", dir)
+		t.Setenv("JWT_ACTIVE_KID", "outra")
+		if err := InitKeys(); err == nil {
+			t.Error("JWT_ACTIVE_KID inexistente aceito")
+		}
+	})
+}
+
+// A chave HS256 é secreta e nunca aparece no JWKS.
+func TestJWKSOmitsHS256(t *testing.T) {
+	initHS256Keys(t)
+	if kids := jwksKIDs(t); len(kids) != 0 {
+		t.Errorf("JWKS = %v, esperado vazio", kids)
+	}
+
+	token, err := GenerateJWT("u1", "cliente", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseToken(token); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,85 @@
+package utils
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Lista embutida de senhas comuns/vazadas, uma por linha, em minúsculas.
+//
+//go:embed common_passwords.txt
+var commonPasswordsFile string
+
+// O bcrypt só aceita senhas de até 72 bytes
+const maxPasswordBytes = 72
+
+var commonPasswords = func() map[string]struct{} {
+	set := map[string]struct{}{}
+	for _, line := range strings.Split(commonPasswordsFile, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			set[strings.ToLower(line)] = struct{}{}
+		}
+	}
+	return set
+}()
+
+// CheckPasswordPolicy valida a senha contra a política configurada por
+// variáveis de ambiente e retorna a lista de problemas encontrados (vazia se
+// a senha for aceita):
+//
+//	PASSWORD_MIN_LENGTH     tamanho mínimo (padrão 8)
+//	PASSWORD_REQUIRE_UPPER  exige letra maiúscula (padrão true)
+//	PASSWORD_REQUIRE_LOWER  exige letra minúscula (padrão true)
+//	PASSWORD_REQUIRE_DIGIT  exige número (padrão true)
+//	PASSWORD_REQUIRE_SYMBOL exige caractere especial (padrão false)
+//	PASSWORD_REJECT_COMMON  rejeita senhas da lista de senhas comuns (padrão true)
+//
+// Senhas acima de 72 bytes (limite do bcrypt) são sempre rejeitadas.
+func CheckPasswordPolicy(password string) []string {
+	var problems []string
+
+	minLength := GetEnvInt("PASSWORD_MIN_LENGTH", 8)
+	if len([]rune(password)) < minLength {
+		problems = append(problems, fmt.Sprintf("a senha deve ter pelo menos %d caracteres", minLength))
+	}
+	if len(password) > maxPasswordBytes {
+		problems = append(problems, fmt.Sprintf("a senha deve ter no máximo %d bytes (caracteres acentuados ocupam mais de um)", maxPasswordBytes))
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+
+	if GetEnvBool("PASSWORD_REQUIRE_UPPER", true) && !hasUpper {
+		problems = append(problems, "a senha deve conter uma letra maiúscula")
+	}
+	if GetEnvBool("PASSWORD_REQUIRE_LOWER", true) && !hasLower {
+		problems = append(problems, "a senha deve conter uma letra minúscula")
+	}
+	if GetEnvBool("PASSWORD_REQUIRE_DIGIT", true) && !hasDigit {
+		problems = append(problems, "a senha deve conter um número")
+	}
+	if GetEnvBool("PASSWORD_REQUIRE_SYMBOL", false) && !hasSymbol {
+		problems = append(problems, "a senha deve conter um caractere especial")
+	}
+
+	if GetEnvBool("PASSWORD_REJECT_COMMON", true) {
+		if _, found := commonPasswords[strings.ToLower(password)]; found {
+			problems = append(problems, "a senha é muito comum ou já apareceu em vazamentos")
+		}
+	}
+
+	return problems
+}
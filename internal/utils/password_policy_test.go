@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckPasswordPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		ok       bool
+	}{
+		{"aceita", "Senha-forte-9", true},
+		{"curta", "Ab1", false},
+		{"sem maiúscula", "senha-forte-9", false},
+		{"sem minúscula", "SENHA-FORTE-9", false},
+		{"sem número", "Senha-forte", false},
+		{"comum", "Password123", false},
+		{"acima de 72 bytes", "Aa1" + strings.Repeat("é", 35), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems := CheckPasswordPolicy(tt.password)
+			if ok := len(problems) == 0; ok != tt.ok {
+				t.Errorf("aceita = %v, esperado %v (%v)", ok, tt.ok, problems)
+			}
+		})
+	}
+}
+
+func TestCheckPasswordPolicyFromEnv(t *testing.T) {
+	t.Setenv("PASSWORD_MIN_LENGTH", "12")
+	t.Setenv("PASSWORD_REQUIRE_SYMBOL", "true")
+
+	if problems := CheckPasswordPolicy("Senhaforte9"); len(problems) != 2 {
+		t.Errorf("problemas = %v, esperado tamanho e caractere especial", problems)
+	}
+	if problems := CheckPasswordPolicy("Senha-forte-9"); len(problems) != 0 {
+		t.Errorf("senha aceita recusada: %v", problems)
+	}
+}
@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"strings"
+	"time"
+	"unicode"
+)
+
+// OnlyDigits remove tudo que não for dígito ("123.456.789-09" → "12345678909").
+func OnlyDigits(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, s)
+}
+
+func allSameDigit(digits string) bool {
+	return strings.Count(digits, digits[:1]) == len(digits)
+}
+
+// checkDigit calcula o dígito verificador módulo 11 usado por CPF e CNPJ.
+func checkDigit(digits string, weights []int) byte {
+	sum := 0
+	for i, w := range weights {
+		sum += int(digits[i]-'0') * w
+	}
+	rest := sum % 11
+	if rest < 2 {
+		return '0'
+	}
+	return byte('0' + 11 - rest)
+}
+
+// ValidCPF valida os dígitos verificadores do CPF, com ou sem máscara.
+func ValidCPF(cpf string) bool {
+	digits := OnlyDigits(cpf)
+	if len(digits) != 11 || allSameDigit(digits) {
+		return false
+	}
+	return checkDigit(digits, []int{10, 9, 8, 7, 6, 5, 4, 3, 2}) == digits[9] &&
+		checkDigit(digits, []int{11, 10, 9, 8, 7, 6, 5, 4, 3, 2}) == digits[10]
+}
+
+// ValidCNPJ valida os dígitos verificadores do CNPJ, com ou sem máscara.
+func ValidCNPJ(cnpj string) bool {
+	digits := OnlyDigits(cnpj)
+	if len(digits) != 14 || allSameDigit(digits) {
+		return false
+	}
+	return checkDigit(digits, []int{5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2}) == digits[12] &&
+		checkDigit(digits, []int{6, 5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2}) == digits[13]
+}
+
+// ValidCEP aceita CEPs com 8 dígitos, com ou sem hífen.
+func ValidCEP(cep string) bool {
+	return len(OnlyDigits(cep)) == 8 && len(strings.TrimSpace(cep)) <= 9
+}
+
+// ValidPhone aceita telefones brasileiros com DDD (10 ou 11 dígitos), com ou
+// sem máscara e código do país. O 55 inicial só é tratado como código do país
+// quando sobram 10 ou 11 dígitos, já que 55 também é DDD (RS).
+func ValidPhone(phone string) bool {
+	digits := OnlyDigits(phone)
+	if (len(digits) == 12 || len(digits) == 13) && strings.HasPrefix(digits, "55") {
+		digits = digits[2:]
+	}
+	return len(digits) == 10 || len(digits) == 11
+}
+
+// ParseBirthDate aceita datas nos formatos AAAA-MM-DD e DD/MM/AAAA.
+func ParseBirthDate(s string) (time.Time, bool) {
+	for _, layout := range []string{"2006-01-02", "02/01/2006"} {
+		if t, err := time.Parse(layout, strings.TrimSpace(s)); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
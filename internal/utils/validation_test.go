@@ -0,0 +1,99 @@
+package utils
+
+import "testing"
+
+func TestValidCPF(t *testing.T) {
+	tests := []struct {
+		cpf string
+		ok  bool
+	}{
+		{"529.982.247-25", true},
+		{"52998224725", true},
+		{"529.982.247-24", false},
+		{"111.111.111-11", false},
+		{"5299822472", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if ok := ValidCPF(tt.cpf); ok != tt.ok {
+			t.Errorf("ValidCPF(%q) = %v, esperado %v", tt.cpf, ok, tt.ok)
+		}
+	}
+}
+
+func TestValidCNPJ(t *testing.T) {
+	tests := []struct {
+		cnpj string
+		ok   bool
+	}{
+		{"11.222.333/0001-81", true},
+		{"11222333000181", true},
+		{"11.222.333/0001-80", false},
+		{"00.000.000/0000-00", false},
+		{"1122233300018", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if ok := ValidCNPJ(tt.cnpj); ok != tt.ok {
+			t.Errorf("ValidCNPJ(%q) = %v, esperado %v", tt.cnpj, ok, tt.ok)
+		}
+	}
+}
+
+func TestValidCEP(t *testing.T) {
+	tests := []struct {
+		cep string
+		ok  bool
+	}{
+		{"01310-100", true},
+		{"01310100", true},
+		{" 01310-100 ", true},
+		{"01310-10", false},
+		{"013101000", false},
+		{"01.310-100", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if ok := ValidCEP(tt.cep); ok != tt.ok {
+			t.Errorf("ValidCEP(%q) = %v, esperado %v", tt.cep, ok, tt.ok)
+		}
+	}
+}
+
+func TestValidPhone(t *testing.T) {
+	tests := []struct {
+		name  string
+		phone string
+		ok    bool
+	}{
+		{"celular", "(11) 98765-4321", true},
+		{"fixo", "(11) 3456-7890", true},
+		{"com código do país", "+55 11 98765-4321", true},
+		{"fixo com código do país", "+55 11 3456-7890", true},
+		{"DDD 55", "(55) 99876-5432", true},
+		{"fixo com DDD 55", "(55) 3222-1234", true},
+		{"DDD 55 com código do país", "+55 55 99876-5432", true},
+		{"sem DDD", "98765-4321", false},
+		{"dígitos demais", "+55 11 98765-43210", false},
+		{"vazio", "", false},
+	}
+	for _, tt := range tests {
+		if ok := ValidPhone(tt.phone); ok != tt.ok {
+			t.Errorf("%s: ValidPhone(%q) = %v, esperado %v", tt.name, tt.phone, ok, tt.ok)
+		}
+	}
+}
+
+func TestParseBirthDate(t *testing.T) {
+	for _, value := range []string{"1990-05-17", "17/05/1990", " 1990-05-17 "} {
+		date, ok := ParseBirthDate(value)
+		if !ok || date.Format("2006-01-02") != "1990-05-17" {
+			t.Errorf("ParseBirthDate(%q) = %v, %v", value, date, ok)
+		}
+	}
+	for _, value := range []string{"1990-02-30", "05/17/1990", "ontem"} {
+		if _, ok := ParseBirthDate(value); ok {
+			t.Errorf("ParseBirthDate(%q) aceita", value)
+		}
+	}
+}